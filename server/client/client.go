@@ -0,0 +1,264 @@
+// Package client is the Go client of the Splitty API.
+//
+// A Client creates and imports groups. Everything else happens on behalf of a
+// group, through the GroupClient returned by Client.Group:
+//
+//	c := client.New("https://splitty.example.com")
+//	group, err := c.CreateGroup(ctx, client.GroupInput{Name: "Trip", Users: []string{"Soumik", "Paulomi"}})
+//	...
+//	g := c.Group(group.ID, group.Token)
+//	transactions, err := g.Transactions(ctx, 0)
+//
+// Errors returned by the API are of type *Error and match the sentinel errors
+// of this package, such as ErrNotFound, with errors.Is.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultMaxRetries = 3
+	DefaultRetryWait  = 250 * time.Millisecond
+	// maxRetryWait caps the backoff, and how long a Retry-After header is
+	// honoured for.
+	maxRetryWait = 10 * time.Second
+)
+
+// Client talks to the API of a Splitty server. Its fields must not be changed
+// once it is in use.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	// MaxRetries is how often idempotent requests, those with the GET, HEAD,
+	// PUT or DELETE method, are retried when the server cannot be reached or
+	// is unavailable. Deleting, undeleting and unarchiving a group are never
+	// retried, as a repeated attempt conflicts with the first one. Zero
+	// disables retries.
+	MaxRetries int
+	// RetryWait is the wait before the first retry. It doubles with every
+	// further one.
+	RetryWait time.Duration
+}
+
+// New returns a client of the server at baseURL, such as
+// http://localhost:4000, that retries idempotent requests DefaultMaxRetries
+// times.
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		MaxRetries: DefaultMaxRetries,
+		RetryWait:  DefaultRetryWait,
+	}
+}
+
+// Group returns the client of the group with the ID, authenticated by its
+// token.
+func (c *Client) Group(id int64, token string) *GroupClient {
+	return &GroupClient{client: c, id: id, token: token}
+}
+
+// request is an API request. Its body is either JSON, marshalled from value,
+// or read from reader with the content type given in contentType, in which
+// case it cannot be retried.
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	token  string
+	header http.Header
+	// stream requests are not cut off by the timeout of the HTTP client, as
+	// their response lasts as long as the caller reads it.
+	stream bool
+	// once requests are never retried, as they fail when repeated after an
+	// attempt that went through, even with an idempotent method.
+	once bool
+
+	value       interface{}
+	reader      io.Reader
+	contentType string
+}
+
+// do sends the request and decodes the JSON response into dst, unless dst is
+// nil.
+func (c *Client) do(ctx context.Context, req *request, dst interface{}) error {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if dst == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(dst); err != nil {
+		return fmt.Errorf("reading the response: %w", err)
+	}
+
+	return nil
+}
+
+// download sends the request and copies the raw response body to w.
+func (c *Client) download(ctx context.Context, req *request, w io.Writer) error {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// send sends the request, retrying it if it is idempotent, and returns the
+// response unless it is an error response, which is returned as *Error.
+func (c *Client) send(ctx context.Context, req *request) (*http.Response, error) {
+	var body []byte
+	if req.value != nil {
+		content, err := json.Marshal(req.value)
+		if err != nil {
+			return nil, err
+		}
+		body = content
+	}
+
+	retries := 0
+	if idempotent(req.method) && req.reader == nil && !req.once {
+		retries = c.MaxRetries
+	}
+
+	wait := c.RetryWait
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.attempt(ctx, req, body)
+
+		if attempt == retries || !retryable(resp, err) || ctx.Err() != nil {
+			if err != nil {
+				return nil, err
+			}
+
+			if resp.StatusCode >= 400 {
+				defer resp.Body.Close()
+				return nil, readError(resp)
+			}
+
+			return resp, nil
+		}
+
+		delay := wait
+		if resp != nil {
+			if after, ok := retryAfter(resp); ok {
+				delay = after
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+
+		wait = min(2*wait, maxRetryWait)
+	}
+}
+
+func (c *Client) attempt(ctx context.Context, req *request, body []byte) (*http.Response, error) {
+	u := c.BaseURL + req.path
+	if len(req.query) > 0 {
+		u += "?" + req.query.Encode()
+	}
+
+	var reader io.Reader
+	switch {
+	case req.reader != nil:
+		reader = req.reader
+	case body != nil:
+		reader = bytes.NewReader(body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range req.header {
+		httpReq.Header[key] = values
+	}
+
+	switch {
+	case req.reader != nil:
+		httpReq.Header.Set("Content-Type", req.contentType)
+	case body != nil:
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+
+	if req.token != "" {
+		httpReq.Header.Set("X-Group-Token", req.token)
+	}
+
+	httpClient := c.HTTPClient
+	if req.stream && httpClient.Timeout != 0 {
+		streamClient := *httpClient
+		streamClient.Timeout = 0
+		httpClient = &streamClient
+	}
+
+	return httpClient.Do(httpReq)
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryable tells whether the outcome of an attempt is worth another one:
+// the server could not be reached, or it is overloaded or being restarted.
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryAfter reads the Retry-After header, given in seconds or as a date. The
+// wait is capped at maxRetryWait.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	header := resp.Header.Get("Retry-After")
+
+	if seconds, err := strconv.ParseInt(header, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(min(seconds, int64(maxRetryWait/time.Second))) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(header); err == nil {
+		return min(max(time.Until(date), 0), maxRetryWait), true
+	}
+
+	return 0, false
+}
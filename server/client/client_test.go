@@ -0,0 +1,368 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/soumikc1729/splitty/server/client"
+)
+
+const token = "abc123XYZ"
+
+// serve starts a server answering every request with handler, and returns
+// a client of the group with ID 1 on it that retries without waiting.
+func serve(t *testing.T, handler http.HandlerFunc) *client.GroupClient {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	c := client.New(srv.URL)
+	c.RetryWait = time.Millisecond
+
+	return c.Group(1, token)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message interface{}) {
+	writeJSON(w, status, map[string]interface{}{"error": message})
+}
+
+func TestRequest(t *testing.T) {
+	var got struct {
+		method, path, query, token, contentType string
+		body                                    map[string]interface{}
+	}
+
+	g := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		got.method, got.path, got.query = r.Method, r.URL.Path, r.URL.RawQuery
+		got.token, got.contentType = r.Header.Get("X-Group-Token"), r.Header.Get("Content-Type")
+		json.NewDecoder(r.Body).Decode(&got.body)
+		writeJSON(w, http.StatusOK, map[string]interface{}{"transaction": map[string]interface{}{"id": 7}})
+	})
+
+	date := client.NewDate(2024, 3, 1)
+	input := client.TransactionInput{Title: "Dinner", Date: &date, Payments: []client.Payment{{Payer: "Soumik", Amount: 10}, {Payer: "Paulomi", Amount: -10}}}
+
+	if _, err := g.UpdateTransaction(context.Background(), 7, input); err != nil {
+		t.Fatal(err)
+	}
+
+	switch {
+	case got.method != http.MethodPatch || got.path != "/v1/groups/1/transactions/7":
+		t.Errorf("sent %s %s, want PATCH /v1/groups/1/transactions/7", got.method, got.path)
+	case got.token != token:
+		t.Errorf("sent token %q, want %q", got.token, token)
+	case got.contentType != "application/json":
+		t.Errorf("sent content type %q", got.contentType)
+	case got.body["title"] != "Dinner" || got.body["date"] != "2024-03-01":
+		t.Errorf("sent body %v", got.body)
+	}
+
+	if _, err := g.Transactions(context.Background(), 42); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.query != "after=42" {
+		t.Errorf("sent query %q, want after=42", got.query)
+	}
+}
+
+func TestResponse(t *testing.T) {
+	g := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"transactions": []map[string]interface{}{
+				{"id": 3, "title": "Taxi", "date": "2024-03-02", "payments": []map[string]interface{}{{"payer": "Soumik", "amount": 5}}, "comment_count": 2},
+			},
+		})
+	})
+
+	transactions, err := g.Transactions(context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(transactions) != 1 {
+		t.Fatalf("got %d transactions, want 1", len(transactions))
+	}
+
+	tr := transactions[0]
+	if tr.ID != 3 || tr.Title != "Taxi" || tr.Date.String() != "2024-03-02" || tr.CommentCount != 2 || len(tr.Payments) != 1 {
+		t.Errorf("got %+v", tr)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	g := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusBadRequest, "the requested resource could not be found")
+	})
+
+	_, err := g.Get(context.Background())
+	if !errors.Is(err, client.ErrNotFound) {
+		t.Fatalf("got %v, want ErrNotFound", err)
+	}
+
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("got %#v, want an *Error with status 400", err)
+	}
+}
+
+func TestValidation(t *testing.T) {
+	g := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusUnprocessableEntity, map[string]string{"title": "must be provided", "payments": "must add up to zero"})
+	})
+
+	_, err := g.CreateTransaction(context.Background(), client.TransactionInput{})
+	if !errors.Is(err, client.ErrValidation) {
+		t.Fatalf("got %v, want ErrValidation", err)
+	}
+
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) || apiErr.Fields["title"] != "must be provided" || len(apiErr.Fields) != 2 {
+		t.Errorf("got %#v, want the invalid fields", err)
+	}
+
+	if want := "the request is invalid:\n  payments: must add up to zero\n  title: must be provided"; err.Error() != want {
+		t.Errorf("got message %q, want %q", err.Error(), want)
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	responses := []struct {
+		name    string
+		message interface{}
+		want    error
+	}{
+		{"edit conflict", "unable to update the record due to an edit conflict, please try again", client.ErrEditConflict},
+		{"archived", "the group is archived and is read-only, unarchive it to modify it", client.ErrGroupArchived},
+		{"pending deletion", "the group is scheduled for deletion and is read-only, cancel the deletion to modify it", client.ErrGroupPendingDeletion},
+		{"unsettled", map[string]interface{}{
+			"message":     "all balances must be settled before archiving the group",
+			"settlements": []map[string]interface{}{{"from": "Paulomi", "to": "Soumik", "amount": 5}},
+		}, client.ErrUnsettled},
+	}
+
+	sentinels := []error{client.ErrEditConflict, client.ErrGroupArchived, client.ErrGroupPendingDeletion, client.ErrUnsettled, client.ErrNotFound}
+
+	for _, resp := range responses {
+		t.Run(resp.name, func(t *testing.T) {
+			g := serve(t, func(w http.ResponseWriter, r *http.Request) {
+				writeError(w, http.StatusConflict, resp.message)
+			})
+
+			_, err := g.Archive(context.Background(), true)
+
+			for _, sentinel := range sentinels {
+				if got := errors.Is(err, sentinel); got != (sentinel == resp.want) {
+					t.Errorf("%v: errors.Is(err, %v) = %t", err, sentinel, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRetries(t *testing.T) {
+	var attempts atomic.Int32
+
+	g := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			writeError(w, http.StatusServiceUnavailable, "the server is restarting")
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"group": map[string]interface{}{"id": 1, "name": "Trip"}})
+	})
+
+	group, err := g.Get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if group.Name != "Trip" || attempts.Load() != 3 {
+		t.Errorf("got %+v after %d attempts, want Trip after 3", group, attempts.Load())
+	}
+}
+
+func TestNoRetries(t *testing.T) {
+	var attempts atomic.Int32
+
+	g := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		writeError(w, http.StatusServiceUnavailable, "the server is restarting")
+	})
+
+	_, err := g.CreateTransaction(context.Background(), client.TransactionInput{Title: "Dinner"})
+
+	var apiErr *client.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("got %v, want the 503 response", err)
+	}
+
+	if attempts.Load() != 1 {
+		t.Errorf("made %d attempts, want 1", attempts.Load())
+	}
+}
+
+func TestGroupStateNotRetried(t *testing.T) {
+	var attempts atomic.Int32
+
+	g := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		writeError(w, http.StatusServiceUnavailable, "the server is restarting")
+	})
+
+	for name, call := range map[string]func(context.Context) (*client.Group, error){
+		"delete":          g.Delete,
+		"cancel deletion": g.CancelDeletion,
+		"unarchive":       g.Unarchive,
+	} {
+		attempts.Store(0)
+
+		if _, err := call(context.Background()); err == nil {
+			t.Errorf("%s: got no error, want the 503 response", name)
+		}
+
+		if attempts.Load() != 1 {
+			t.Errorf("%s: made %d attempts, want 1", name, attempts.Load())
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		retryAfter string
+		wantRetry  bool
+	}{
+		{"date in the past", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), true},
+		// Waits are capped, and one this long must neither overflow into an
+		// immediate retry nor outlast the context.
+		{"too long", "99999999999999", false},
+		{"date far ahead", time.Now().Add(24 * time.Hour).UTC().Format(http.TimeFormat), false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+
+			g := serve(t, func(w http.ResponseWriter, r *http.Request) {
+				if attempts.Add(1) == 1 {
+					w.Header().Set("Retry-After", tt.retryAfter)
+					writeError(w, http.StatusServiceUnavailable, "the server is restarting")
+					return
+				}
+				writeJSON(w, http.StatusOK, map[string]interface{}{"group": map[string]interface{}{"id": 1}})
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+
+			_, err := g.Get(ctx)
+
+			switch {
+			case tt.wantRetry && err != nil:
+				t.Errorf("got %v, want the retry to succeed", err)
+			case !tt.wantRetry && !errors.Is(err, context.DeadlineExceeded):
+				t.Errorf("got %v after %d attempts, want to wait until the deadline", err, attempts.Load())
+			}
+		})
+	}
+}
+
+func TestRetryContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusServiceUnavailable, "the server is restarting")
+	}))
+	defer srv.Close()
+
+	c := client.New(srv.URL)
+	c.MaxRetries = 100
+	c.RetryWait = time.Second
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.Group(1, token).Get(ctx)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want context.DeadlineExceeded", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("gave up after %s", elapsed)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	var lastEventID string
+
+	g := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		lastEventID = r.Header.Get("Last-Event-ID")
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "retry: 3000\n\n")
+		fmt.Fprint(w, `id: 5`+"\nevent: transaction.created\ndata: "+`{"id":5,"type":"transaction.created","group_id":1,"data":{"id":9}}`+"\n\n")
+		fmt.Fprint(w, ": heartbeat\n\n")
+		fmt.Fprint(w, `id: 6`+"\nevent: group.updated\ndata: "+`{"id":6,"type":"group.updated","group_id":1,"data":{}}`+"\n\n")
+	})
+
+	var ids []int64
+	err := g.Events(context.Background(), 4, func(event *client.Event) error {
+		ids = append(ids, event.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if lastEventID != "4" {
+		t.Errorf("sent Last-Event-ID %q, want 4", lastEventID)
+	}
+
+	if len(ids) != 2 || ids[0] != 5 || ids[1] != 6 {
+		t.Errorf("got events %v, want [5 6]", ids)
+	}
+
+	stop := errors.New("stop")
+	if err := g.Events(context.Background(), 0, func(*client.Event) error { return stop }); err != stop {
+		t.Errorf("got %v, want the error of the callback", err)
+	}
+}
+
+func TestUpload(t *testing.T) {
+	var filename, content string
+
+	g := serve(t, func(w http.ResponseWriter, r *http.Request) {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		defer file.Close()
+
+		b, _ := io.ReadAll(file)
+		filename, content = header.Filename, string(b)
+		writeJSON(w, http.StatusCreated, map[string]interface{}{"attachment": map[string]interface{}{"id": 2, "filename": header.Filename}})
+	})
+
+	attachment, err := g.UploadAttachment(context.Background(), 7, "receipt.pdf", strings.NewReader("%PDF-1.4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if filename != "receipt.pdf" || content != "%PDF-1.4" || attachment.ID != 2 {
+		t.Errorf("uploaded %q with %q, got %+v", filename, content, attachment)
+	}
+}
@@ -0,0 +1,133 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// The messages the API sends for the errors the sentinels stand for.
+const (
+	notFoundMessage        = "the requested resource could not be found"
+	editConflictMessage    = "unable to update the record due to an edit conflict, please try again"
+	groupArchivedMessage   = "the group is archived and is read-only, unarchive it to modify it"
+	pendingDeletionMessage = "the group is scheduled for deletion and is read-only, cancel the deletion to modify it"
+)
+
+var (
+	// ErrNotFound matches errors about a group or one of its records that
+	// does not exist, or a token that does not belong to the group.
+	ErrNotFound = errors.New("not found")
+	// ErrValidation matches failed validations, whose *Error tells the
+	// message of every invalid field.
+	ErrValidation = errors.New("failed validation")
+	// ErrEditConflict matches updates that raced with another one. They
+	// succeed once retried on the latest version.
+	ErrEditConflict = errors.New("edit conflict")
+	// ErrGroupArchived matches changes to an archived group.
+	ErrGroupArchived = errors.New("group is archived")
+	// ErrGroupPendingDeletion matches changes to a group scheduled for
+	// deletion.
+	ErrGroupPendingDeletion = errors.New("group is scheduled for deletion")
+	// ErrUnsettled matches archiving a group with require_settled while its
+	// balances are not, whose *Error tells the settlements still due.
+	ErrUnsettled = errors.New("group is not settled")
+)
+
+// Error is an error response of the API. The error of the envelope is either
+// a message, the messages of the invalid fields of a failed validation, or
+// a message along with details such as the settlements due.
+type Error struct {
+	StatusCode  int
+	Message     string
+	Fields      map[string]string
+	Settlements []Settlement
+}
+
+func (e *Error) Error() string {
+	if len(e.Fields) == 0 {
+		return e.Message
+	}
+
+	fields := make([]string, 0, len(e.Fields))
+	for field := range e.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var b strings.Builder
+	b.WriteString("the request is invalid:")
+	for _, field := range fields {
+		fmt.Fprintf(&b, "\n  %s: %s", field, e.Fields[field])
+	}
+
+	return b.String()
+}
+
+// Is matches the error with the sentinel errors of the package. Unknown
+// groups and records are answered with 400 Bad Request, so they are told
+// apart by their message.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound || e.Message == notFoundMessage
+	case ErrValidation:
+		return e.StatusCode == http.StatusUnprocessableEntity
+	case ErrEditConflict:
+		return e.StatusCode == http.StatusConflict && e.Message == editConflictMessage
+	case ErrGroupArchived:
+		return e.StatusCode == http.StatusConflict && e.Message == groupArchivedMessage
+	case ErrGroupPendingDeletion:
+		return e.StatusCode == http.StatusConflict && e.Message == pendingDeletionMessage
+	case ErrUnsettled:
+		return e.StatusCode == http.StatusConflict && e.Settlements != nil
+	default:
+		return false
+	}
+}
+
+// readError reads the error envelope of the response.
+func readError(resp *http.Response) error {
+	apiErr := &Error{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+
+	var envelope struct {
+		Error json.RawMessage `json:"error"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil || len(envelope.Error) == 0 {
+		return apiErr
+	}
+
+	var message string
+	if err := json.Unmarshal(envelope.Error, &message); err == nil {
+		apiErr.Message = message
+		return apiErr
+	}
+
+	if resp.StatusCode == http.StatusUnprocessableEntity {
+		if err := json.Unmarshal(envelope.Error, &apiErr.Fields); err != nil {
+			return errors.Join(apiErr, err)
+		}
+		apiErr.Message = "the request is invalid"
+		return apiErr
+	}
+
+	var details struct {
+		Message     string       `json:"message"`
+		Settlements []Settlement `json:"settlements"`
+	}
+
+	if err := json.Unmarshal(envelope.Error, &details); err != nil {
+		return errors.Join(apiErr, err)
+	}
+
+	if details.Message != "" {
+		apiErr.Message = details.Message
+	}
+	apiErr.Settlements = details.Settlements
+
+	return apiErr
+}
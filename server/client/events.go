@@ -0,0 +1,157 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	SyncCreate = "create"
+	SyncUpdate = "update"
+	SyncDelete = "delete"
+
+	OpCreateTransaction = "create_transaction"
+	OpUpdateTransaction = "update_transaction"
+	OpDeleteTransaction = "delete_transaction"
+)
+
+// Events streams the events of the group to fn until ctx is done, fn fails or
+// the server ends the stream, in which case it returns nil and the caller
+// should call it again with the ID of the last event it received. Events
+// stored after lastEventID are received first; with a lastEventID of zero
+// that is every stored event. Events stored shortly before lastEventID are
+// received again, as an event can be stored after events with higher IDs, and
+// fn should skip those it already handled by their ID.
+func (g *GroupClient) Events(ctx context.Context, lastEventID int64, fn func(*Event) error) error {
+	req := &request{
+		method: http.MethodGet,
+		path:   g.path("events"),
+		header: http.Header{"Last-Event-Id": {strconv.FormatInt(lastEventID, 10)}},
+		token:  g.token,
+		stream: true,
+	}
+
+	resp, err := g.client.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(nil, 1<<20)
+
+	var payload strings.Builder
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case line == "":
+			if payload.Len() == 0 {
+				continue
+			}
+
+			var event Event
+			if err := json.Unmarshal([]byte(payload.String()), &event); err != nil {
+				return fmt.Errorf("reading an event: %w", err)
+			}
+			payload.Reset()
+
+			if err := fn(&event); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "data:"):
+			payload.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return scanner.Err()
+}
+
+// SocketRequest is an operation on the transactions of the group sent over a
+// socket. ID is chosen by the client and echoed in the reply. A non-zero
+// Version makes updates and deletions fail unless the transaction is still at
+// that version.
+type SocketRequest struct {
+	ID            string            `json:"id"`
+	Op            string            `json:"op"`
+	TransactionID int64             `json:"transaction_id,omitempty"`
+	Version       int               `json:"version,omitempty"`
+	Transaction   *TransactionInput `json:"transaction,omitempty"`
+}
+
+// SocketMessage is received over a socket. Type is event for the events of
+// the group, ack for the reply to a request, or error for a request that
+// failed, whose Status and Error are those of the equivalent HTTP response.
+type SocketMessage struct {
+	Type          string       `json:"type"`
+	ID            string       `json:"id,omitempty"`
+	Event         *Event       `json:"event,omitempty"`
+	TransactionID int64        `json:"transaction_id,omitempty"`
+	Transaction   *Transaction `json:"transaction,omitempty"`
+	Version       int          `json:"version,omitempty"`
+	Status        int          `json:"status,omitempty"`
+	Error         interface{}  `json:"error,omitempty"`
+}
+
+// Socket is a WebSocket on which the events of the group are received and
+// its transactions can be changed. Send and Receive may each be called by
+// one goroutine at a time.
+type Socket struct {
+	conn *websocket.Conn
+}
+
+// Socket opens a WebSocket to the group.
+func (g *GroupClient) Socket(ctx context.Context) (*Socket, error) {
+	u := "ws" + strings.TrimPrefix(g.client.BaseURL, "http") + g.path("socket")
+
+	header := http.Header{"X-Group-Token": {g.token}}
+
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, u, header)
+	if err != nil {
+		if resp != nil && resp.StatusCode >= 400 {
+			return nil, readError(resp)
+		}
+		return nil, err
+	}
+
+	return &Socket{conn: conn}, nil
+}
+
+func (s *Socket) Send(req *SocketRequest) error {
+	return s.conn.WriteJSON(req)
+}
+
+// Receive waits for the next message.
+func (s *Socket) Receive() (*SocketMessage, error) {
+	var msg SocketMessage
+	if err := s.conn.ReadJSON(&msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+func (s *Socket) Close() error {
+	return s.conn.Close()
+}
+
+// Sync applies the operations queued while offline and returns what became of
+// them, along with the events of the group since input.LastEventID.
+func (g *GroupClient) Sync(ctx context.Context, input SyncInput) (*SyncResult, error) {
+	var result SyncResult
+	if err := g.do(ctx, &request{method: http.MethodPost, path: g.path("sync"), value: input}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// GroupClient makes the requests of a group. Create it with Client.Group.
+type GroupClient struct {
+	client *Client
+	id     int64
+	token  string
+}
+
+// ID returns the ID of the group.
+func (g *GroupClient) ID() int64 {
+	return g.id
+}
+
+// path returns the path of the group followed by the elements, which are
+// formatted with %v.
+func (g *GroupClient) path(elems ...interface{}) string {
+	path := fmt.Sprintf("/v1/groups/%d", g.id)
+	for _, elem := range elems {
+		path += fmt.Sprintf("/%v", elem)
+	}
+	return path
+}
+
+func (g *GroupClient) do(ctx context.Context, req *request, dst interface{}) error {
+	req.token = g.token
+	return g.client.do(ctx, req, dst)
+}
+
+func (g *GroupClient) download(ctx context.Context, req *request, w io.Writer) error {
+	req.token = g.token
+	return g.client.download(ctx, req, w)
+}
+
+type groupEnvelope struct {
+	Group *Group `json:"group"`
+}
+
+func (c *Client) CreateGroup(ctx context.Context, input GroupInput) (*Group, error) {
+	var envelope groupEnvelope
+	if err := c.do(ctx, &request{method: http.MethodPost, path: "/v1/groups", value: input}, &envelope); err != nil {
+		return nil, err
+	}
+	return envelope.Group, nil
+}
+
+// ImportGroup creates a group named name from the CSV export of another app,
+// or previews it unless input.Commit is set.
+func (c *Client) ImportGroup(ctx context.Context, name string, input ImportInput) (*ImportResult, error) {
+	body := struct {
+		ImportInput
+		Name string `json:"name"`
+	}{input, name}
+
+	var result ImportResult
+	if err := c.do(ctx, &request{method: http.MethodPost, path: "/v1/import", value: body}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (g *GroupClient) Get(ctx context.Context) (*Group, error) {
+	return g.groupRequest(ctx, &request{method: http.MethodGet, path: g.path()})
+}
+
+// Update renames the group and sets its users. Users can be added but not
+// removed.
+func (g *GroupClient) Update(ctx context.Context, name string, users []string) (*Group, error) {
+	input := map[string]interface{}{"name": name, "users": users}
+	return g.groupRequest(ctx, &request{method: http.MethodPatch, path: g.path(), value: input})
+}
+
+func (g *GroupClient) UpdateSettings(ctx context.Context, input SettingsInput) (*Settings, error) {
+	var envelope struct {
+		Settings *Settings `json:"settings"`
+	}
+	if err := g.do(ctx, &request{method: http.MethodPatch, path: g.path("settings"), value: input}, &envelope); err != nil {
+		return nil, err
+	}
+	return envelope.Settings, nil
+}
+
+// Delete schedules the group for deletion once the grace period of the server
+// has passed. Until then the group is read-only and the deletion can be
+// cancelled.
+func (g *GroupClient) Delete(ctx context.Context) (*Group, error) {
+	return g.groupRequest(ctx, &request{method: http.MethodDelete, path: g.path(), once: true})
+}
+
+func (g *GroupClient) CancelDeletion(ctx context.Context) (*Group, error) {
+	return g.groupRequest(ctx, &request{method: http.MethodDelete, path: g.path("deletion"), once: true})
+}
+
+// Archive makes the group read-only. With requireSettled it fails with
+// ErrUnsettled unless every balance is zero.
+func (g *GroupClient) Archive(ctx context.Context, requireSettled bool) (*Group, error) {
+	query := url.Values{"require_settled": {strconv.FormatBool(requireSettled)}}
+	return g.groupRequest(ctx, &request{method: http.MethodPost, path: g.path("archive"), query: query})
+}
+
+func (g *GroupClient) Unarchive(ctx context.Context) (*Group, error) {
+	return g.groupRequest(ctx, &request{method: http.MethodDelete, path: g.path("archive"), once: true})
+}
+
+// Merge moves the transactions of the source group into this one and returns
+// the group along with how many transactions were moved.
+func (g *GroupClient) Merge(ctx context.Context, input MergeInput) (*Group, int, error) {
+	var envelope struct {
+		Group             *Group `json:"group"`
+		TransactionsMoved int    `json:"transactions_moved"`
+	}
+	if err := g.do(ctx, &request{method: http.MethodPost, path: g.path("merge"), value: input}, &envelope); err != nil {
+		return nil, 0, err
+	}
+	return envelope.Group, envelope.TransactionsMoved, nil
+}
+
+// Export writes the transactions of the group to w in the format of the
+// options.
+func (g *GroupClient) Export(ctx context.Context, opts ExportOptions, w io.Writer) error {
+	query := url.Values{}
+	setQuery(query, "format", string(opts.Format))
+	setQuery(query, "commodity", opts.Commodity)
+	return g.download(ctx, &request{method: http.MethodGet, path: g.path("export"), query: query}, w)
+}
+
+// Report writes the HTML statement of the group for the dates to w. The zero
+// range is the current month.
+func (g *GroupClient) Report(ctx context.Context, dates DateRange, w io.Writer) error {
+	query := url.Values{}
+	dates.setQuery(query)
+	return g.download(ctx, &request{method: http.MethodGet, path: g.path("report"), query: query}, w)
+}
+
+func (g *GroupClient) groupRequest(ctx context.Context, req *request) (*Group, error) {
+	var envelope groupEnvelope
+	if err := g.do(ctx, req, &envelope); err != nil {
+		return nil, err
+	}
+	return envelope.Group, nil
+}
+
+// setQuery sets the query parameter unless the value is empty, so that the
+// server falls back to its default.
+func setQuery(query url.Values, key, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
+
+func (dates DateRange) setQuery(query url.Values) {
+	if !dates.From.IsZero() {
+		query.Set("from", dates.From.String())
+	}
+	if !dates.To.IsZero() {
+		query.Set("to", dates.To.String())
+	}
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// ImportTransactions adds the transactions of the CSV export of another app
+// to the group, or previews them unless input.Commit is set. Every member of
+// the export must match a user of the group, by name or through
+// input.Members.
+func (g *GroupClient) ImportTransactions(ctx context.Context, input ImportInput) (*ImportResult, error) {
+	var result ImportResult
+	if err := g.do(ctx, &request{method: http.MethodPost, path: g.path("import"), value: input}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ImportStatement turns the expenses of a bank or card statement into
+// transactions of the group, applying its import rules.
+func (g *GroupClient) ImportStatement(ctx context.Context, input StatementInput) (*StatementResult, error) {
+	var result StatementResult
+	if err := g.do(ctx, &request{method: http.MethodPost, path: g.path("import", "statement"), value: input}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+type importRuleEnvelope struct {
+	Rule *ImportRule `json:"rule"`
+}
+
+func (g *GroupClient) CreateImportRule(ctx context.Context, input ImportRuleInput) (*ImportRule, error) {
+	var envelope importRuleEnvelope
+	if err := g.do(ctx, &request{method: http.MethodPost, path: g.path("import", "rules"), value: input}, &envelope); err != nil {
+		return nil, err
+	}
+	return envelope.Rule, nil
+}
+
+func (g *GroupClient) ImportRules(ctx context.Context) ([]ImportRule, error) {
+	var envelope struct {
+		Rules []ImportRule `json:"rules"`
+	}
+	if err := g.do(ctx, &request{method: http.MethodGet, path: g.path("import", "rules")}, &envelope); err != nil {
+		return nil, err
+	}
+	return envelope.Rules, nil
+}
+
+// UpdateImportRule replaces the rule with the input.
+func (g *GroupClient) UpdateImportRule(ctx context.Context, id int64, input ImportRuleInput) (*ImportRule, error) {
+	var envelope importRuleEnvelope
+	if err := g.do(ctx, &request{method: http.MethodPatch, path: g.path("import", "rules", id), value: input}, &envelope); err != nil {
+		return nil, err
+	}
+	return envelope.Rule, nil
+}
+
+func (g *GroupClient) DeleteImportRule(ctx context.Context, id int64) error {
+	return g.do(ctx, &request{method: http.MethodDelete, path: g.path("import", "rules", id)}, nil)
+}
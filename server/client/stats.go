@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+func (g *GroupClient) Stats(ctx context.Context, opts StatsOptions) (*Stats, error) {
+	query := url.Values{}
+	opts.DateRange.setQuery(query)
+	setQuery(query, "bucket", string(opts.Bucket))
+	if opts.Top != nil {
+		query.Set("top", strconv.Itoa(*opts.Top))
+	}
+
+	var envelope struct {
+		Stats *Stats `json:"stats"`
+	}
+	if err := g.do(ctx, &request{method: http.MethodGet, path: g.path("stats"), query: query}, &envelope); err != nil {
+		return nil, err
+	}
+	return envelope.Stats, nil
+}
+
+// SpendingChart writes the SVG bar chart of the spending per month to w.
+func (g *GroupClient) SpendingChart(ctx context.Context, opts ChartOptions, w io.Writer) error {
+	return g.chart(ctx, "spending", opts, w)
+}
+
+// BalanceChart writes the SVG bar chart of the balance of every user to w.
+func (g *GroupClient) BalanceChart(ctx context.Context, opts ChartOptions, w io.Writer) error {
+	opts.DateRange = DateRange{}
+	return g.chart(ctx, "balances", opts, w)
+}
+
+// CategoryChart writes the SVG pie chart of the spending by category to w.
+func (g *GroupClient) CategoryChart(ctx context.Context, opts ChartOptions, w io.Writer) error {
+	return g.chart(ctx, "categories", opts, w)
+}
+
+func (g *GroupClient) chart(ctx context.Context, name string, opts ChartOptions, w io.Writer) error {
+	query := url.Values{}
+	opts.DateRange.setQuery(query)
+	setQuery(query, "theme", opts.Theme)
+	if opts.Width != 0 {
+		query.Set("width", strconv.Itoa(opts.Width))
+	}
+	if opts.Height != 0 {
+		query.Set("height", strconv.Itoa(opts.Height))
+	}
+
+	return g.download(ctx, &request{method: http.MethodGet, path: g.path("charts", name), query: query}, w)
+}
@@ -0,0 +1,148 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type transactionEnvelope struct {
+	Transaction *Transaction `json:"transaction"`
+}
+
+func (g *GroupClient) CreateTransaction(ctx context.Context, input TransactionInput) (*Transaction, error) {
+	var envelope transactionEnvelope
+	if err := g.do(ctx, &request{method: http.MethodPost, path: g.path("transactions"), value: input}, &envelope); err != nil {
+		return nil, err
+	}
+	return envelope.Transaction, nil
+}
+
+// Transactions lists the transactions of the group whose ID is greater than
+// afterID, in the order of their IDs.
+func (g *GroupClient) Transactions(ctx context.Context, afterID int64) ([]ListedTransaction, error) {
+	var envelope struct {
+		Transactions []ListedTransaction `json:"transactions"`
+	}
+	query := url.Values{"after": {strconv.FormatInt(afterID, 10)}}
+	if err := g.do(ctx, &request{method: http.MethodGet, path: g.path("transactions"), query: query}, &envelope); err != nil {
+		return nil, err
+	}
+	return envelope.Transactions, nil
+}
+
+// UpdateTransaction replaces the transaction with the input.
+func (g *GroupClient) UpdateTransaction(ctx context.Context, id int64, input TransactionInput) (*Transaction, error) {
+	var envelope transactionEnvelope
+	if err := g.do(ctx, &request{method: http.MethodPatch, path: g.path("transactions", id), value: input}, &envelope); err != nil {
+		return nil, err
+	}
+	return envelope.Transaction, nil
+}
+
+func (g *GroupClient) DeleteTransaction(ctx context.Context, id int64) error {
+	return g.do(ctx, &request{method: http.MethodDelete, path: g.path("transactions", id)}, nil)
+}
+
+type commentEnvelope struct {
+	Comment *Comment `json:"comment"`
+}
+
+func (g *GroupClient) CreateComment(ctx context.Context, transactionID int64, author, body string) (*Comment, error) {
+	input := map[string]string{"author": author, "body": body}
+
+	var envelope commentEnvelope
+	if err := g.do(ctx, &request{method: http.MethodPost, path: g.path("transactions", transactionID, "comments"), value: input}, &envelope); err != nil {
+		return nil, err
+	}
+	return envelope.Comment, nil
+}
+
+func (g *GroupClient) Comments(ctx context.Context, transactionID int64) ([]Comment, error) {
+	var envelope struct {
+		Comments []Comment `json:"comments"`
+	}
+	if err := g.do(ctx, &request{method: http.MethodGet, path: g.path("transactions", transactionID, "comments")}, &envelope); err != nil {
+		return nil, err
+	}
+	return envelope.Comments, nil
+}
+
+func (g *GroupClient) UpdateComment(ctx context.Context, transactionID, id int64, body string) (*Comment, error) {
+	input := map[string]string{"body": body}
+
+	var envelope commentEnvelope
+	if err := g.do(ctx, &request{method: http.MethodPatch, path: g.path("transactions", transactionID, "comments", id), value: input}, &envelope); err != nil {
+		return nil, err
+	}
+	return envelope.Comment, nil
+}
+
+func (g *GroupClient) DeleteComment(ctx context.Context, transactionID, id int64) error {
+	return g.do(ctx, &request{method: http.MethodDelete, path: g.path("transactions", transactionID, "comments", id)}, nil)
+}
+
+// UploadAttachment attaches the file read from r to the transaction. The
+// server detects its content type from its content.
+func (g *GroupClient) UploadAttachment(ctx context.Context, transactionID int64, filename string, r io.Reader) (*Attachment, error) {
+	var body bytes.Buffer
+
+	mw := multipart.NewWriter(&body)
+
+	part, err := mw.CreateFormFile("file", filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := io.Copy(part, r); err != nil {
+		return nil, err
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	req := &request{
+		method:      http.MethodPost,
+		path:        g.path("transactions", transactionID, "attachments"),
+		reader:      &body,
+		contentType: mw.FormDataContentType(),
+	}
+
+	var envelope struct {
+		Attachment *Attachment `json:"attachment"`
+	}
+	if err := g.do(ctx, req, &envelope); err != nil {
+		return nil, err
+	}
+	return envelope.Attachment, nil
+}
+
+func (g *GroupClient) Attachments(ctx context.Context, transactionID int64) ([]Attachment, error) {
+	var envelope struct {
+		Attachments []Attachment `json:"attachments"`
+	}
+	if err := g.do(ctx, &request{method: http.MethodGet, path: g.path("transactions", transactionID, "attachments")}, &envelope); err != nil {
+		return nil, err
+	}
+	return envelope.Attachments, nil
+}
+
+// Attachment writes the content of the attachment to w.
+func (g *GroupClient) Attachment(ctx context.Context, transactionID, id int64, w io.Writer) error {
+	return g.download(ctx, &request{method: http.MethodGet, path: g.path("transactions", transactionID, "attachments", id)}, w)
+}
+
+// AttachmentThumbnail writes the JPEG thumbnail of the attachment to w. It
+// fails with ErrNotFound for attachments without a thumbnail.
+func (g *GroupClient) AttachmentThumbnail(ctx context.Context, transactionID, id int64, w io.Writer) error {
+	return g.download(ctx, &request{method: http.MethodGet, path: g.path("transactions", transactionID, "attachments", id, "thumbnail")}, w)
+}
+
+func (g *GroupClient) DeleteAttachment(ctx context.Context, transactionID, id int64) error {
+	return g.do(ctx, &request{method: http.MethodDelete, path: g.path("transactions", transactionID, "attachments", id)}, nil)
+}
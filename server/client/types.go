@@ -0,0 +1,229 @@
+package client
+
+import (
+	"time"
+
+	"github.com/soumikc1729/splitty/server/model"
+)
+
+// The records of the API are those the server stores, so that both always
+// agree on their JSON. Package model defines them without any of the
+// dependencies of the server.
+type (
+	Group             = model.Group
+	Settings          = model.Settings
+	SplitMode         = model.SplitMode
+	Transaction       = model.Transaction
+	Payment           = model.Payment
+	Date              = model.Date
+	Comment           = model.Comment
+	Attachment        = model.Attachment
+	ImportRule        = model.ImportRule
+	Webhook           = model.Webhook
+	Delivery          = model.Delivery
+	DeliveryStatus    = model.DeliveryStatus
+	MergeAction       = model.MergeAction
+	Bucket            = model.Bucket
+	SpendingBucket    = model.SpendingBucket
+	MemberSpending    = model.MemberSpending
+	RankedTransaction = model.RankedTransaction
+	Settlement        = model.Settlement
+	Event             = model.Event
+	EventType         = model.EventType
+	ExportFormat      = model.ExportFormat
+	Warning           = model.Warning
+	StatementMapping  = model.StatementMapping
+	Proposal          = model.Proposal
+)
+
+// NewDate returns the date of the year, month and day.
+func NewDate(year, month, day int) Date {
+	return model.NewDate(year, time.Month(month), day)
+}
+
+// SettingsInput holds the settings of a group to set. Settings that are left
+// nil keep their current value, or the default one for a new group.
+type SettingsInput struct {
+	Currency      *string    `json:"currency,omitempty"`
+	DefaultSplit  *SplitMode `json:"default_split,omitempty"`
+	Locale        *string    `json:"locale,omitempty"`
+	Timezone      *string    `json:"timezone,omitempty"`
+	SimplifyDebts *bool      `json:"simplify_debts,omitempty"`
+	Precision     *int       `json:"precision,omitempty"`
+}
+
+type GroupInput struct {
+	Name     string        `json:"name"`
+	Users    []string      `json:"users"`
+	Settings SettingsInput `json:"settings"`
+}
+
+// TransactionInput holds a transaction to create, or to replace an existing
+// one with. A nil Date is today in the time zone of the group.
+type TransactionInput struct {
+	Title    string    `json:"title"`
+	Category string    `json:"category,omitempty"`
+	Date     *Date     `json:"date,omitempty"`
+	Payments []Payment `json:"payments"`
+}
+
+// ListedTransaction is a transaction along with the number of its comments.
+type ListedTransaction struct {
+	Transaction
+	CommentCount int `json:"comment_count"`
+}
+
+type MergeInput struct {
+	SourceID    int64  `json:"source_id"`
+	SourceToken string `json:"source_token"`
+	// Mapping renames members of the source group to members of the target
+	// group. Members are matched by name otherwise.
+	Mapping      map[string]string `json:"mapping,omitempty"`
+	SourceAction MergeAction       `json:"source_action,omitempty"`
+}
+
+// ImportInput holds the CSV export of another app. Transactions are only
+// stored if Commit is set, they are previewed otherwise.
+type ImportInput struct {
+	Source  string            `json:"source"`
+	CSV     string            `json:"csv"`
+	Members map[string]string `json:"members,omitempty"`
+	Commit  bool              `json:"commit"`
+}
+
+// ImportResult is the outcome of an import. Group is only set when a group
+// was imported and committed.
+type ImportResult struct {
+	Group        *Group        `json:"group,omitempty"`
+	Members      []string      `json:"members,omitempty"`
+	Transactions []Transaction `json:"transactions"`
+	Warnings     []Warning     `json:"warnings"`
+}
+
+// StatementInput holds a bank or card statement to import. Rows whose line is
+// listed in Skip are left out, as are duplicates unless their line is listed
+// in Include. Transactions are only stored if Commit is set, they are
+// previewed as Rows otherwise.
+type StatementInput struct {
+	CSV          string           `json:"csv"`
+	Mapping      StatementMapping `json:"mapping"`
+	DefaultPayer string           `json:"default_payer,omitempty"`
+	Skip         []int            `json:"skip,omitempty"`
+	Include      []int            `json:"include,omitempty"`
+	Commit       bool             `json:"commit"`
+}
+
+type StatementResult struct {
+	Rows         []Proposal    `json:"rows,omitempty"`
+	Transactions []Transaction `json:"transactions,omitempty"`
+	Warnings     []Warning     `json:"warnings"`
+}
+
+type ImportRuleInput struct {
+	Pattern  string   `json:"pattern"`
+	Category string   `json:"category,omitempty"`
+	Payer    string   `json:"payer,omitempty"`
+	Split    []string `json:"split,omitempty"`
+}
+
+// WebhookInput holds the fields of a webhook. Fields that are left nil keep
+// their current value when updating; a new webhook without a secret gets a
+// generated one.
+type WebhookInput struct {
+	URL    *string  `json:"url,omitempty"`
+	Secret *string  `json:"secret,omitempty"`
+	Events []string `json:"events,omitempty"`
+}
+
+// DeliveryFilter selects the deliveries of a webhook to list. The zero value
+// lists the last 50 deliveries of any status.
+type DeliveryFilter struct {
+	Status DeliveryStatus
+	Limit  int
+}
+
+// DateRange is an inclusive range of dates. Zero dates fall back to the
+// default range of the request.
+type DateRange struct {
+	From Date
+	To   Date
+}
+
+// StatsOptions selects the stats of a group. The zero value buckets the last
+// year by month and ranks the top 5 transactions.
+type StatsOptions struct {
+	DateRange
+	Bucket Bucket
+	Top    *int
+}
+
+type Stats struct {
+	From            Date                    `json:"from"`
+	To              Date                    `json:"to"`
+	Bucket          Bucket                  `json:"bucket"`
+	Total           float64                 `json:"total"`
+	Series          []SpendingBucket        `json:"series"`
+	Members         map[string]MemberTotals `json:"members"`
+	TopTransactions []RankedTransaction     `json:"top_transactions"`
+}
+
+type MemberTotals struct {
+	Paid     float64 `json:"paid"`
+	Consumed float64 `json:"consumed"`
+	Net      float64 `json:"net"`
+}
+
+// ChartOptions selects how an SVG chart is drawn. Zero values fall back to
+// the defaults of the server. The date range is ignored by the balance
+// chart.
+type ChartOptions struct {
+	DateRange
+	Width  int
+	Height int
+	Theme  string
+}
+
+// ExportOptions selects the format of an export. The zero value exports CSV
+// in the currency of the group.
+type ExportOptions struct {
+	Format    ExportFormat
+	Commodity string
+}
+
+// SyncOperation is a change queued while offline. See the sync endpoint of
+// the API for how operations are applied and merged.
+type SyncOperation struct {
+	Op          string            `json:"op"`
+	ID          int64             `json:"id,omitempty"`
+	ClientID    string            `json:"client_id,omitempty"`
+	BaseVersion int               `json:"base_version,omitempty"`
+	Base        *TransactionInput `json:"base,omitempty"`
+	Transaction *TransactionInput `json:"transaction,omitempty"`
+	ModifiedAt  time.Time         `json:"modified_at"`
+}
+
+type SyncInput struct {
+	Operations  []SyncOperation `json:"operations"`
+	LastEventID int64           `json:"last_event_id"`
+}
+
+// SyncResult holds what became of every operation, and the events of the
+// group since the last event ID of the input. HasMore tells that there are
+// further events to catch up with.
+type SyncResult struct {
+	Results     []SyncOperationResult `json:"results"`
+	Events      []Event               `json:"events"`
+	LastEventID int64                 `json:"last_event_id"`
+	HasMore     bool                  `json:"has_more"`
+}
+
+// SyncOperationResult tells what became of an operation. Status is one of
+// created, updated, merged, unchanged, deleted or failed, in which case Error
+// holds what the API would have answered to the equivalent request.
+type SyncOperationResult struct {
+	Index       int          `json:"index"`
+	Status      string       `json:"status"`
+	Transaction *Transaction `json:"transaction,omitempty"`
+	Conflicts   []string     `json:"conflicts,omitempty"`
+	Error       interface{}  `json:"error,omitempty"`
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type webhookEnvelope struct {
+	Webhook *Webhook `json:"webhook"`
+}
+
+// CreateWebhook subscribes the URL to the events of the group. The secret
+// the deliveries are signed with is only ever returned here.
+func (g *GroupClient) CreateWebhook(ctx context.Context, input WebhookInput) (*Webhook, error) {
+	var envelope webhookEnvelope
+	if err := g.do(ctx, &request{method: http.MethodPost, path: g.path("webhooks"), value: input}, &envelope); err != nil {
+		return nil, err
+	}
+	return envelope.Webhook, nil
+}
+
+func (g *GroupClient) Webhooks(ctx context.Context) ([]Webhook, error) {
+	var envelope struct {
+		Webhooks []Webhook `json:"webhooks"`
+	}
+	if err := g.do(ctx, &request{method: http.MethodGet, path: g.path("webhooks")}, &envelope); err != nil {
+		return nil, err
+	}
+	return envelope.Webhooks, nil
+}
+
+func (g *GroupClient) UpdateWebhook(ctx context.Context, id int64, input WebhookInput) (*Webhook, error) {
+	var envelope webhookEnvelope
+	if err := g.do(ctx, &request{method: http.MethodPatch, path: g.path("webhooks", id), value: input}, &envelope); err != nil {
+		return nil, err
+	}
+	return envelope.Webhook, nil
+}
+
+func (g *GroupClient) DeleteWebhook(ctx context.Context, id int64) error {
+	return g.do(ctx, &request{method: http.MethodDelete, path: g.path("webhooks", id)}, nil)
+}
+
+// WebhookDeliveries lists the latest deliveries of the webhook, newest first.
+func (g *GroupClient) WebhookDeliveries(ctx context.Context, id int64, filter DeliveryFilter) ([]Delivery, error) {
+	query := url.Values{}
+	setQuery(query, "status", string(filter.Status))
+	if filter.Limit != 0 {
+		query.Set("limit", strconv.Itoa(filter.Limit))
+	}
+
+	var envelope struct {
+		Deliveries []Delivery `json:"deliveries"`
+	}
+	if err := g.do(ctx, &request{method: http.MethodGet, path: g.path("webhooks", id, "deliveries"), query: query}, &envelope); err != nil {
+		return nil, err
+	}
+	return envelope.Deliveries, nil
+}
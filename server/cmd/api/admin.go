@@ -0,0 +1,380 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/internal/validator"
+)
+
+const adminUsage = `usage: api admin COMMAND [args]
+
+  group ID                          print the group and its activity
+  rotate-token ID                   give the group a new token
+  export ID [FILE]                  write the group and its transactions as JSON
+  import [FILE]                     create a group from an export
+  purge [-inactive-for D] [-dry-run]  delete groups without activity for a while
+  stats                             print what the instance holds
+  check                             check the transactions of every group
+
+FILE defaults to standard output or input.`
+
+var (
+	errAdminUsage = errors.New(adminUsage)
+	// errInconsistent is returned by the check command once it reported the
+	// problems it found.
+	errInconsistent = errors.New("the data is inconsistent")
+)
+
+// groupExport is a group with its transactions as written by the export
+// command. Comments and attachments are not part of it.
+type groupExport struct {
+	Group        *data.Group         `json:"group"`
+	Transactions []*data.Transaction `json:"transactions"`
+}
+
+// runAdmin runs the admin subcommand, which gives operators access to every
+// group through the data store the server is configured with.
+func runAdmin(cfg *Config, args []string) error {
+	if len(args) == 0 {
+		return errAdminUsage
+	}
+
+	app, err := NewApp(cfg)
+	if err != nil {
+		return err
+	}
+
+	if app.Data.Admin == nil {
+		return errors.New("the memory driver keeps nothing between runs, configure a database")
+	}
+
+	cmd, args := args[0], args[1:]
+
+	switch {
+	case cmd == "group" && len(args) == 1:
+		return app.adminGroup(args[0])
+	case cmd == "rotate-token" && len(args) == 1:
+		return app.adminRotateToken(args[0])
+	case cmd == "export" && len(args) >= 1 && len(args) <= 2:
+		return app.adminExport(args[0], args[1:])
+	case cmd == "import" && len(args) <= 1:
+		return app.adminImport(args)
+	case cmd == "purge":
+		return app.adminPurge(args)
+	case cmd == "stats" && len(args) == 0:
+		return app.adminStats()
+	case cmd == "check" && len(args) == 0:
+		return app.adminCheck()
+	default:
+		return errAdminUsage
+	}
+}
+
+func (app *App) adminGroupInfo(arg string) (*data.GroupInfo, error) {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || id < 1 {
+		return nil, fmt.Errorf("invalid group id %q", arg)
+	}
+
+	info, err := app.Data.Admin.GetGroup(id, app.Config.Data.QueryTimeout)
+	if err != nil {
+		if errors.Is(err, data.ErrRecordNotFound) {
+			return nil, fmt.Errorf("there is no group with id %d", id)
+		}
+		return nil, err
+	}
+
+	return info, nil
+}
+
+func (app *App) adminGroup(arg string) error {
+	info, err := app.adminGroupInfo(arg)
+	if err != nil {
+		return err
+	}
+
+	return printAdminJSON(os.Stdout, info)
+}
+
+func (app *App) adminRotateToken(arg string) error {
+	info, err := app.adminGroupInfo(arg)
+	if err != nil {
+		return err
+	}
+
+	if err := app.Data.Admin.RotateToken(info.Group, app.Config.Data.QueryTimeout); err != nil {
+		return err
+	}
+
+	fmt.Printf("the new token of group %d is %s\n", info.Group.ID, info.Group.Token)
+	return nil
+}
+
+func (app *App) adminExport(arg string, files []string) error {
+	info, err := app.adminGroupInfo(arg)
+	if err != nil {
+		return err
+	}
+
+	export := groupExport{Group: info.Group, Transactions: []*data.Transaction{}}
+
+	err = app.Data.Transactions.StreamAllAfterID(0, info.Group.ID, app.Config.Data.StreamTimeout, func(t *data.Transaction) error {
+		export.Transactions = append(export.Transactions, t)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		return printAdminJSON(os.Stdout, export)
+	}
+
+	f, err := os.Create(files[0])
+	if err != nil {
+		return err
+	}
+
+	if err := printAdminJSON(f, export); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// adminImport creates a group from an export, with a new ID and token. The
+// client IDs of the transactions are dropped, as they are unique across the
+// instance. The group stays archived if it was, but a scheduled deletion is
+// not carried over.
+func (app *App) adminImport(files []string) error {
+	var r io.Reader = os.Stdin
+	if len(files) == 1 {
+		f, err := os.Open(files[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var export groupExport
+
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(&export); err != nil {
+		return fmt.Errorf("reading the export: %w", err)
+	}
+
+	if export.Group == nil {
+		return errors.New("the export holds no group")
+	}
+
+	group := &data.Group{Name: export.Group.Name, Users: export.Group.Users, Settings: export.Group.Settings}
+
+	v := validator.New()
+
+	if data.ValidateGroup(v, group); !v.Valid() {
+		return fmt.Errorf("the group is invalid: %v", v.Errors)
+	}
+
+	for _, t := range export.Transactions {
+		t.GroupID = group.ID
+		t.ClientID = ""
+
+		if data.ValidateTransaction(v, t, group); !v.Valid() {
+			return fmt.Errorf("transaction %d is invalid: %v", t.ID, v.Errors)
+		}
+	}
+
+	if err := app.Data.Groups.Insert(group, app.Config.Data.QueryTimeout); err != nil {
+		return err
+	}
+
+	for _, t := range export.Transactions {
+		t.GroupID = group.ID
+	}
+
+	err := app.Data.Transactions.InsertAll(export.Transactions, app.Config.Data.QueryTimeout)
+	if err == nil && export.Group.ArchivedAt != nil {
+		err = app.Data.Groups.SetArchived(group, export.Group.ArchivedAt, app.Config.Data.QueryTimeout)
+	}
+	if err != nil {
+		if discardErr := app.discardGroup(group); discardErr != nil {
+			return errors.Join(err, discardErr)
+		}
+		return err
+	}
+
+	fmt.Printf("imported group %d with %d transactions, its token is %s\n", group.ID, len(export.Transactions), group.Token)
+	return nil
+}
+
+// adminPurge deletes the groups that were neither created nor had a
+// transaction changed for a while, along with their attachments.
+func (app *App) adminPurge(args []string) error {
+	var inactiveFor time.Duration
+	var dryRun bool
+
+	flags := flag.NewFlagSet("api admin purge", flag.ContinueOnError)
+	flags.DurationVar(&inactiveFor, "inactive-for", 365*24*time.Hour, "how long a group must have been inactive")
+	flags.BoolVar(&dryRun, "dry-run", false, "only list the groups that would be deleted")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() > 0 || inactiveFor <= 0 {
+		return errAdminUsage
+	}
+
+	ids, err := app.Data.Admin.GetAllInactiveSince(time.Now().Add(-inactiveFor), app.Config.Data.QueryTimeout)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tTRANSACTIONS\tLAST ACTIVITY")
+
+	purged := 0
+	var failedBlobs []string
+
+	for _, id := range ids {
+		info, err := app.Data.Admin.GetGroup(id, app.Config.Data.QueryTimeout)
+		if err != nil {
+			if errors.Is(err, data.ErrRecordNotFound) {
+				continue
+			}
+			return err
+		}
+
+		if !dryRun {
+			blobKeys, err := app.groupBlobKeys(id)
+			if err != nil {
+				return err
+			}
+
+			if err := app.discardGroup(info.Group); err != nil {
+				return fmt.Errorf("deleting group %d: %w", id, err)
+			}
+
+			// The blobs are deleted here rather than by app.deleteBlobs, which
+			// would leave them to a background goroutine the command does not
+			// wait for.
+			for _, key := range blobKeys {
+				if err := app.deleteBlob(key); err != nil {
+					failedBlobs = append(failedBlobs, key)
+					fmt.Fprintf(os.Stderr, "deleting blob %s of group %d: %v\n", key, id, err)
+				}
+			}
+		}
+
+		purged++
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%s\n", id, info.Group.Name, info.Transactions, info.LastActivityAt.Format(time.DateOnly))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if dryRun {
+		fmt.Printf("\n%d groups would be deleted\n", purged)
+	} else {
+		fmt.Printf("\n%d groups deleted\n", purged)
+	}
+
+	if len(failedBlobs) > 0 {
+		return fmt.Errorf("%d blobs could not be deleted and are left in storage", len(failedBlobs))
+	}
+
+	return nil
+}
+
+func (app *App) deleteBlob(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), app.Config.Data.StreamTimeout)
+	defer cancel()
+
+	return app.Blobs.Delete(ctx, key)
+}
+
+func (app *App) adminStats() error {
+	stats, err := app.Data.Admin.Stats(app.Config.Data.QueryTimeout)
+	if err != nil {
+		return err
+	}
+
+	return printAdminJSON(os.Stdout, stats)
+}
+
+// adminCheck checks that the payments of every transaction add up to zero in
+// the precision of its group, and that every payer is still a user of the
+// group.
+func (app *App) adminCheck() error {
+	ids, err := app.Data.Admin.GetAllGroupIDs(app.Config.Data.QueryTimeout)
+	if err != nil {
+		return err
+	}
+
+	problems, transactions := 0, 0
+
+	for _, id := range ids {
+		info, err := app.Data.Admin.GetGroup(id, app.Config.Data.QueryTimeout)
+		if err != nil {
+			if errors.Is(err, data.ErrRecordNotFound) {
+				continue
+			}
+			return err
+		}
+
+		group := info.Group
+
+		err = app.Data.Transactions.StreamAllAfterID(0, id, app.Config.Data.StreamTimeout, func(t *data.Transaction) error {
+			transactions++
+
+			var units int64
+			for _, p := range t.Payments {
+				units += group.Settings.Units(p.Amount)
+
+				if !slices.Contains(group.Users, p.Payer) {
+					problems++
+					fmt.Printf("group %d transaction %d: payer %q is not a user of the group\n", id, t.ID, p.Payer)
+				}
+			}
+
+			if units != 0 {
+				problems++
+				fmt.Printf("group %d transaction %d: payments add up to %.*f instead of zero\n", id, t.ID, group.Settings.Precision, group.Settings.Amount(units))
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("checked %d transactions of %d groups, found %d problems\n", transactions, len(ids), problems)
+
+	if problems > 0 {
+		return errInconsistent
+	}
+
+	return nil
+}
+
+func printAdminJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
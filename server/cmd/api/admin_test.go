@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+)
+
+// newSQLiteTestApp is newTestApp with a SQLite database, for the commands
+// that need the admin store.
+func newSQLiteTestApp(t *testing.T) *App {
+	t.Helper()
+
+	app := newTestApp(t)
+
+	cfg := &app.Config.Data
+	cfg.Driver = ""
+	cfg.DSN = "sqlite://" + filepath.Join(t.TempDir(), "splitty.db")
+	cfg.MaxOpenConns = 4
+	cfg.MaxIdleConns = 4
+	cfg.IdleTimeout = time.Minute
+	cfg.PingTimeout = 5 * time.Second
+	cfg.AutoMigrate = true
+
+	if err := data.CheckSchema(cfg, nil); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	d, err := data.New(cfg)
+	if err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	t.Cleanup(func() { d.DB.Close() })
+
+	app.Data = d
+	return app
+}
+
+func TestAdminPurge(t *testing.T) {
+	app := newSQLiteTestApp(t)
+
+	old := time.Now().AddDate(-2, 0, 0).UTC().Format("2006-01-02 15:04:05-07:00")
+
+	backdate := func(t *testing.T, query string, id int64) {
+		t.Helper()
+
+		if _, err := app.Data.DB.Exec(query, old, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Groups count as active from their creation and from the last change to
+	// any of their transactions.
+	empty := app.createTestGroup(t)
+	backdate(t, `UPDATE groups SET created_at = ? WHERE id = ?`, empty.ID)
+
+	stale := app.createTestGroup(t)
+	app.createTestTransaction(t, stale, dinner())
+	backdate(t, `UPDATE groups SET created_at = ? WHERE id = ?`, stale.ID)
+	backdate(t, `UPDATE transactions SET updated_at = ? WHERE group_id = ?`, stale.ID)
+
+	active := app.createTestGroup(t)
+	app.createTestTransaction(t, active, dinner())
+	backdate(t, `UPDATE groups SET created_at = ? WHERE id = ?`, active.ID)
+
+	recent := app.createTestGroup(t)
+
+	exists := func(t *testing.T, group *data.Group) bool {
+		t.Helper()
+
+		_, err := app.Data.Admin.GetGroup(group.ID, app.Config.Data.QueryTimeout)
+		if err != nil && !errors.Is(err, data.ErrRecordNotFound) {
+			t.Fatal(err)
+		}
+		return err == nil
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		if err := app.adminPurge([]string{"-inactive-for", "8760h", "-dry-run"}); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, group := range []*data.Group{empty, stale, active, recent} {
+			if !exists(t, group) {
+				t.Errorf("group %d was deleted by a dry run", group.ID)
+			}
+		}
+	})
+
+	t.Run("purged", func(t *testing.T) {
+		if err := app.adminPurge([]string{"-inactive-for", "8760h"}); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, tt := range []struct {
+			name  string
+			group *data.Group
+			want  bool
+		}{
+			{"created long ago", empty, false},
+			{"changed long ago", stale, false},
+			{"changed recently", active, true},
+			{"created recently", recent, true},
+		} {
+			if got := exists(t, tt.group); got != tt.want {
+				t.Errorf("%s: group exists is %t, want %t", tt.name, got, tt.want)
+			}
+		}
+
+		res := app.do(t, http.MethodGet, groupPath(active, "/transactions"), active.Token, nil)
+		wantStatus(t, res, http.StatusOK)
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		for _, args := range [][]string{{"-inactive-for", "0"}, {"extra"}} {
+			if err := app.adminPurge(args); !errors.Is(err, errAdminUsage) {
+				t.Errorf("adminPurge(%q) = %v, want the usage", args, err)
+			}
+		}
+	})
+}
@@ -1,22 +1,42 @@
 package main
 
 import (
+	"net/http"
+	"strings"
+
 	"github.com/rs/zerolog"
+	"github.com/soumikc1729/splitty/server/internal/blob"
 	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/internal/events"
 	"github.com/soumikc1729/splitty/server/internal/logger"
 	"github.com/soumikc1729/splitty/server/internal/server"
+	"github.com/soumikc1729/splitty/server/internal/socket"
+	"github.com/soumikc1729/splitty/server/internal/webhook"
 )
 
 type Config struct {
-	Server server.Config `mapstructure:"server"`
-	Logger logger.Config `mapstructure:"logger"`
-	Data   data.Config   `mapstructure:"data"`
+	Server  server.Config  `mapstructure:"server"`
+	Logger  logger.Config  `mapstructure:"logger"`
+	Data    data.Config    `mapstructure:"data"`
+	Blob    blob.Config    `mapstructure:"blob"`
+	Webhook webhook.Config `mapstructure:"webhook"`
+	Events  events.Config  `mapstructure:"events"`
+	Socket  socket.Config  `mapstructure:"socket"`
 }
 
 type App struct {
-	Config *Config
-	Logger *zerolog.Logger
-	Data   *data.Data
+	Config  *Config
+	Logger  *zerolog.Logger
+	Data    *data.Data
+	Blobs   blob.Store
+	Server  *server.Server
+	Events  *events.Broker
+	Sockets *socket.Hub
+
+	webhookClient *http.Client
+	// webhookWakeup is signalled when deliveries were queued, so that they are
+	// sent without waiting for the next poll.
+	webhookWakeup chan struct{}
 }
 
 func NewApp(cfg *Config) (*App, error) {
@@ -25,15 +45,44 @@ func NewApp(cfg *Config) (*App, error) {
 		return nil, err
 	}
 
+	err = data.CheckSchema(&cfg.Data, func(format string, v ...interface{}) {
+		logger.Info().Msgf(strings.TrimSpace(format), v...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	data, err := data.New(&cfg.Data)
 	if err != nil {
 		return nil, err
 	}
 
-	return &App{Config: cfg, Logger: logger, Data: data}, nil
+	blobs, err := blob.New(&cfg.Blob)
+	if err != nil {
+		return nil, err
+	}
+
+	server := server.New(&cfg.Server, logger)
+
+	return &App{
+		Config:        cfg,
+		Logger:        logger,
+		Data:          data,
+		Blobs:         blobs,
+		Server:        server,
+		Events:        events.NewBroker(cfg.Events.BufferSize),
+		Sockets:       socket.NewHub(&cfg.Socket),
+		webhookClient: webhook.NewClient(cfg.Webhook.Timeout),
+		webhookWakeup: make(chan struct{}, 1),
+	}, nil
 }
 
 func (app *App) Serve() error {
-	server := server.New(&app.Config.Server, app.Logger)
-	return server.Start(app.Routes())
+	app.Server.Background(app.PurgeGroups)
+	if app.Data.Postgres() {
+		app.Server.Background(app.DeliverWebhooks)
+		app.Server.Background(app.ForwardEvents)
+	}
+	app.Server.Background(app.Sockets.Run)
+	return app.Server.Start(app.Routes())
 }
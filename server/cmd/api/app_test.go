@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/internal/events"
+	"github.com/soumikc1729/splitty/server/internal/server"
+	"github.com/soumikc1729/splitty/server/internal/socket"
+)
+
+// newTestApp returns an app that keeps its data in memory and logs nothing.
+func newTestApp(t *testing.T) *App {
+	t.Helper()
+
+	cfg := &Config{}
+	cfg.Data.Driver = data.Memory
+	cfg.Data.QueryTimeout = 3 * time.Second
+	cfg.Data.StreamTimeout = time.Minute
+	cfg.Data.DeletionGracePeriod = 168 * time.Hour
+	cfg.Events.BufferSize = 64
+
+	d, err := data.New(&cfg.Data)
+	if err != nil {
+		t.Fatalf("failed to create the memory store: %v", err)
+	}
+
+	logger := zerolog.Nop()
+
+	return &App{
+		Config:        cfg,
+		Logger:        &logger,
+		Data:          d,
+		Server:        server.New(&cfg.Server, &logger),
+		Events:        events.NewBroker(cfg.Events.BufferSize),
+		Sockets:       socket.NewHub(&cfg.Socket),
+		webhookWakeup: make(chan struct{}, 1),
+	}
+}
+
+// testResponse is a response of the API, with the body kept for decoding.
+type testResponse struct {
+	status int
+	body   []byte
+}
+
+// decode reads the field key of the envelope into dst.
+func (res testResponse) decode(t *testing.T, key string, dst interface{}) {
+	t.Helper()
+
+	var env map[string]json.RawMessage
+	if err := json.Unmarshal(res.body, &env); err != nil {
+		t.Fatalf("the response is not a JSON object: %v: %s", err, res.body)
+	}
+
+	raw, ok := env[key]
+	if !ok {
+		t.Fatalf("the response has no %s: %s", key, res.body)
+	}
+
+	if err := json.Unmarshal(raw, dst); err != nil {
+		t.Fatalf("failed to decode %s: %v: %s", key, err, raw)
+	}
+}
+
+// errorMessage returns the error of the response if it is a message.
+func (res testResponse) errorMessage(t *testing.T) string {
+	t.Helper()
+
+	var message string
+	res.decode(t, "error", &message)
+	return message
+}
+
+// do sends a request through the routes of the app. A non-empty token is sent
+// in the X-Group-Token header and a non-nil body as JSON.
+func (app *App) do(t *testing.T, method, path, token string, body interface{}) testResponse {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatalf("failed to encode the request body: %v", err)
+		}
+	}
+
+	r := httptest.NewRequest(method, path, &buf)
+	if token != "" {
+		r.Header.Set("X-Group-Token", token)
+	}
+
+	w := httptest.NewRecorder()
+	app.Routes().ServeHTTP(w, r)
+
+	return testResponse{status: w.Code, body: w.Body.Bytes()}
+}
+
+// createTestGroup creates a group of alice, bob and carol.
+func (app *App) createTestGroup(t *testing.T) *data.Group {
+	t.Helper()
+
+	res := app.do(t, http.MethodPost, "/v1/groups", "", map[string]interface{}{
+		"name":  "Trip",
+		"users": []string{"alice", "bob", "carol"},
+	})
+	if res.status != http.StatusCreated {
+		t.Fatalf("creating a group: got status %d, want %d: %s", res.status, http.StatusCreated, res.body)
+	}
+
+	var group data.Group
+	res.decode(t, "group", &group)
+	return &group
+}
+
+func groupPath(group *data.Group, suffix string) string {
+	return fmt.Sprintf("/v1/groups/%d%s", group.ID, suffix)
+}
+
+func wantStatus(t *testing.T, res testResponse, status int) {
+	t.Helper()
+
+	if res.status != status {
+		t.Fatalf("got status %d, want %d: %s", res.status, status, res.body)
+	}
+}
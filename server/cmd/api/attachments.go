@@ -0,0 +1,344 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/soumikc1729/splitty/server/internal/blob"
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/internal/thumbnail"
+	"github.com/soumikc1729/splitty/server/internal/util"
+	"github.com/soumikc1729/splitty/server/internal/validator"
+)
+
+const (
+	thumbnailSize = 256
+	// multipartOverhead is allowed on top of the maximum attachment size for
+	// the multipart boundaries and headers of an upload.
+	multipartOverhead = 64 << 10
+)
+
+var (
+	AttachmentContentTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp", "application/pdf"}
+)
+
+func (app *App) UploadAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+
+	transactionID, err := util.ReadParam("transactionID", r)
+	if err != nil {
+		app.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if _, err := app.Data.Transactions.Get(transactionID, group.ID, app.Config.Data.QueryTimeout); err != nil {
+		app.DataErrorResponse(w, r, err)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, app.Config.Blob.MaxSize+multipartOverhead)
+
+	file, fileHeader, err := r.FormFile("file")
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesError):
+			app.AttachmentTooLargeResponse(w, r)
+		default:
+			app.BadRequestResponse(w, r, err)
+		}
+		return
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, app.Config.Blob.MaxSize+1))
+	if err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if int64(len(content)) > app.Config.Blob.MaxSize {
+		app.AttachmentTooLargeResponse(w, r)
+		return
+	}
+
+	attachment := &data.Attachment{
+		TransactionID: transactionID,
+		Filename:      attachmentFilename(fileHeader.Filename),
+		ContentType:   http.DetectContentType(content),
+		Size:          int64(len(content)),
+		BlobKey:       blob.NewKey("attachments"),
+	}
+
+	v := validator.New()
+
+	v.Check(len(content) > 0, "file", "must not be empty")
+
+	if !v.Valid() {
+		app.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if !validator.In(attachment.ContentType, AttachmentContentTypes...) {
+		app.UnsupportedMediaTypeResponse(w, r, attachment.ContentType)
+		return
+	}
+
+	err = app.Blobs.Put(r.Context(), attachment.BlobKey, bytes.NewReader(content), attachment.Size, attachment.ContentType)
+	if err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if thumbnail.Supported(attachment.ContentType) {
+		app.storeThumbnail(r, attachment, content)
+	}
+
+	if err := app.Data.Attachments.Insert(attachment, group.ID, app.Config.Data.QueryTimeout); err != nil {
+		app.deleteBlobs(attachment.BlobKeys())
+		app.DataErrorResponse(w, r, err)
+		return
+	}
+
+	header := make(http.Header)
+	header.Set("Location", fmt.Sprintf("/v1/groups/%d/transactions/%d/attachments/%d", group.ID, transactionID, attachment.ID))
+
+	if err := util.WriteJSON(w, http.StatusCreated, util.Envelope{"attachment": attachment}, header); err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	app.Logger.Info().Int64("group-id", group.ID).Int64("transaction-id", transactionID).Int64("attachment-id", attachment.ID).Int64("size", attachment.Size).Msg("uploaded attachment")
+}
+
+func (app *App) ListAttachmentsHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+
+	transactionID, err := util.ReadParam("transactionID", r)
+	if err != nil {
+		app.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if _, err := app.Data.Transactions.Get(transactionID, group.ID, app.Config.Data.QueryTimeout); err != nil {
+		app.DataErrorResponse(w, r, err)
+		return
+	}
+
+	attachments, err := app.Data.Attachments.GetAllForTransaction(transactionID, group.ID, app.Config.Data.QueryTimeout)
+	if err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if err := util.WriteJSON(w, http.StatusOK, util.Envelope{"attachments": attachments}, nil); err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	app.Logger.Info().Int64("group-id", group.ID).Int64("transaction-id", transactionID).Msg("retrieved attachments")
+}
+
+func (app *App) GetAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	attachment := app.readAttachment(w, r)
+	if attachment == nil {
+		return
+	}
+
+	app.writeBlob(w, r, attachment.BlobKey, attachment.ContentType, attachment.Filename)
+}
+
+func (app *App) GetAttachmentThumbnailHandler(w http.ResponseWriter, r *http.Request) {
+	attachment := app.readAttachment(w, r)
+	if attachment == nil {
+		return
+	}
+
+	if !attachment.HasThumbnail {
+		app.NotFoundResponse(w, r)
+		return
+	}
+
+	app.writeBlob(w, r, attachment.ThumbnailKey, thumbnail.ContentType, "thumbnail-"+strings.TrimSuffix(attachment.Filename, filepath.Ext(attachment.Filename))+".jpg")
+}
+
+func (app *App) DeleteAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+
+	transactionID, err := util.ReadParam("transactionID", r)
+	if err != nil {
+		app.BadRequestResponse(w, r, err)
+		return
+	}
+
+	id, err := util.ReadParam("attachmentID", r)
+	if err != nil {
+		app.BadRequestResponse(w, r, err)
+		return
+	}
+
+	attachment, err := app.Data.Attachments.Delete(id, transactionID, group.ID, app.Config.Data.QueryTimeout)
+	if err != nil {
+		app.DataErrorResponse(w, r, err)
+		return
+	}
+
+	app.deleteBlobs(attachment.BlobKeys())
+
+	if err := util.WriteJSON(w, http.StatusOK, util.Envelope{"message": "attachment successfully deleted"}, nil); err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	app.Logger.Info().Int64("group-id", group.ID).Int64("transaction-id", transactionID).Int64("attachment-id", id).Msg("deleted attachment")
+}
+
+func (app *App) readAttachment(w http.ResponseWriter, r *http.Request) *data.Attachment {
+	group := app.ContextGetGroup(r)
+
+	transactionID, err := util.ReadParam("transactionID", r)
+	if err != nil {
+		app.BadRequestResponse(w, r, err)
+		return nil
+	}
+
+	id, err := util.ReadParam("attachmentID", r)
+	if err != nil {
+		app.BadRequestResponse(w, r, err)
+		return nil
+	}
+
+	attachment, err := app.Data.Attachments.Get(id, transactionID, group.ID, app.Config.Data.QueryTimeout)
+	if err != nil {
+		app.DataErrorResponse(w, r, err)
+		return nil
+	}
+
+	return attachment
+}
+
+// writeBlob streams a blob to the client. Blobs never change once stored, so
+// clients may cache them for a long time.
+func (app *App) writeBlob(w http.ResponseWriter, r *http.Request, key, contentType, filename string) {
+	rc, err := app.Blobs.Get(r.Context(), key)
+	if err != nil {
+		switch {
+		case errors.Is(err, blob.ErrNotFound):
+			app.NotFoundResponse(w, r)
+		default:
+			app.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+	defer rc.Close()
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": filename}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=86400, immutable")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := io.Copy(w, rc); err != nil {
+		app.LogError(r, err)
+	}
+}
+
+// storeThumbnail stores a thumbnail of the image attachment. Images that
+// cannot be decoded are still kept, only without a thumbnail.
+func (app *App) storeThumbnail(r *http.Request, attachment *data.Attachment, content []byte) {
+	thumb, err := thumbnail.Generate(bytes.NewReader(content), thumbnailSize)
+	if err != nil {
+		app.Logger.Warn().Err(err).Str("content-type", attachment.ContentType).Msg("failed to generate thumbnail")
+		return
+	}
+
+	key := attachment.BlobKey + "-thumbnail"
+
+	if err := app.Blobs.Put(r.Context(), key, bytes.NewReader(thumb), int64(len(thumb)), thumbnail.ContentType); err != nil {
+		app.LogError(r, err)
+		return
+	}
+
+	attachment.ThumbnailKey = key
+}
+
+// deleteBlobs deletes the blobs in the background, as the records referring to
+// them are already gone and nobody waits for the result.
+func (app *App) deleteBlobs(keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+
+	app.Server.Background(func(_ context.Context) {
+		ctx, cancel := context.WithTimeout(context.Background(), app.Config.Data.StreamTimeout)
+		defer cancel()
+
+		for _, key := range keys {
+			if err := app.Blobs.Delete(ctx, key); err != nil {
+				app.Logger.Err(err).Str("key", key).Msg("failed to delete blob")
+			}
+		}
+	})
+}
+
+// transactionBlobKeys lists the blobs of the attachments of the transaction.
+// Attachments are only kept in Postgres, so there are none otherwise.
+func (app *App) transactionBlobKeys(transactionID, groupID int64) ([]string, error) {
+	if !app.Data.Postgres() {
+		return nil, nil
+	}
+
+	attachments, err := app.Data.Attachments.GetAllForTransaction(transactionID, groupID, app.Config.Data.QueryTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return attachmentsKeys(attachments), nil
+}
+
+func (app *App) groupBlobKeys(groupID int64) ([]string, error) {
+	if !app.Data.Postgres() {
+		return nil, nil
+	}
+
+	attachments, err := app.Data.Attachments.GetAllForGroup(groupID, app.Config.Data.QueryTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return attachmentsKeys(attachments), nil
+}
+
+func attachmentFilename(name string) string {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, `\`, "/")))
+
+	if name == "" || name == "." || name == "/" || !utf8.ValidString(name) {
+		return "attachment"
+	}
+
+	if len(name) > 255 {
+		ext := filepath.Ext(name)
+		if len(ext) > 16 {
+			ext = ""
+		}
+		name = strings.ToValidUTF8(name[:255-len(ext)], "") + ext
+	}
+
+	return name
+}
+
+func attachmentsKeys(attachments []*data.Attachment) []string {
+	var keys []string
+	for _, attachment := range attachments {
+		keys = append(keys, attachment.BlobKeys()...)
+	}
+	return keys
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/soumikc1729/splitty/server/internal/balance"
+	"github.com/soumikc1729/splitty/server/internal/chart"
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/internal/validator"
+	"github.com/soumikc1729/splitty/server/model"
+)
+
+func (app *App) SpendingChartHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+
+	qs := r.URL.Query()
+	today := model.Today(group.Settings.Location())
+
+	v := validator.New()
+
+	opts := app.readChartOptions(qs, v)
+	from, to := app.readDateRange(qs, model.NewDate(today.Year()-1, today.Month()+1, 1), today, v)
+
+	v.Check(to.Sub(from.Time) <= 10*366*24*time.Hour, "to", "must be at most ten years after from")
+
+	if !v.Valid() {
+		app.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	series, err := app.Data.Stats.SpendingSeries(group.ID, data.Month, from, to, app.Config.Data.QueryTimeout)
+	if err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	totals := make(map[string]float64, len(series))
+	for _, b := range series {
+		totals[b.Start.Format("2006-01")] = b.Total
+	}
+
+	// Months without any transaction are missing from the series but should
+	// still show up as empty bars.
+	var values []chart.Value
+	for month := model.NewDate(from.Year(), from.Month(), 1); month.Before(to.Time); month = (data.Date{Time: month.AddDate(0, 1, 0)}) {
+		label := month.Format("2006-01")
+		values = append(values, chart.Value{Label: label, Value: totals[label]})
+	}
+
+	opts.Title = fmt.Sprintf("%s: spending per month", group.Name)
+
+	app.renderChart(w, r, chart.Bar, values, opts)
+
+	app.Logger.Info().Int64("group-id", group.ID).Msg("rendered spending chart")
+}
+
+func (app *App) BalanceChartHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+
+	v := validator.New()
+
+	opts := app.readChartOptions(r.URL.Query(), v)
+
+	if !v.Valid() {
+		app.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	summary := balance.New(group.Users)
+
+	err := app.Data.Transactions.StreamAllAfterID(0, group.ID, app.Config.Data.StreamTimeout, func(t *data.Transaction) error {
+		summary.Add(t)
+		return nil
+	})
+	if err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	values := make([]chart.Value, 0, len(group.Users))
+	for _, user := range group.Users {
+		values = append(values, chart.Value{Label: user, Value: summary.Net(user)})
+	}
+
+	opts.Title = fmt.Sprintf("%s: balances", group.Name)
+
+	app.renderChart(w, r, chart.Bar, values, opts)
+
+	app.Logger.Info().Int64("group-id", group.ID).Msg("rendered balance chart")
+}
+
+func (app *App) CategoryChartHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+
+	qs := r.URL.Query()
+	today := model.Today(group.Settings.Location())
+
+	v := validator.New()
+
+	opts := app.readChartOptions(qs, v)
+	from, to := app.readDateRange(qs, model.NewDate(today.Year()-1, today.Month()+1, 1), today, v)
+
+	if !v.Valid() {
+		app.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	totals, err := app.Data.Stats.CategoryTotals(group.ID, from, to, app.Config.Data.QueryTimeout)
+	if err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	values := make([]chart.Value, 0, len(totals))
+	for _, c := range totals {
+		label := c.Category
+		if label == "" {
+			label = "Uncategorized"
+		}
+		values = append(values, chart.Value{Label: label, Value: c.Total})
+	}
+
+	opts.Title = fmt.Sprintf("%s: spending by category", group.Name)
+
+	app.renderChart(w, r, chart.Pie, values, opts)
+
+	app.Logger.Info().Int64("group-id", group.ID).Msg("rendered category chart")
+}
+
+func (app *App) readChartOptions(qs url.Values, v *validator.Validator) chart.Options {
+	opts := chart.Options{
+		Width:  app.readInt(qs, "width", 640, v),
+		Height: app.readInt(qs, "height", 360, v),
+	}
+
+	v.Check(validator.Between(opts.Width, 200, 2000), "width", "must be between 200 and 2000")
+	v.Check(validator.Between(opts.Height, 150, 2000), "height", "must be between 150 and 2000")
+
+	theme, err := chart.ThemeByName(app.readString(qs, "theme", chart.Light.Name))
+	if err != nil {
+		v.AddError("theme", "must be one of light, dark or mono")
+	}
+	opts.Theme = theme
+
+	return opts
+}
+
+// renderChart draws the chart into a buffer so that its content hash can be
+// used as the ETag, answering conditional requests with 304 Not Modified.
+func (app *App) renderChart(w http.ResponseWriter, r *http.Request, draw func(io.Writer, []chart.Value, chart.Options) error, values []chart.Value, opts chart.Options) {
+	var buf bytes.Buffer
+	if err := draw(&buf, values, opts); err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.Header().Set("ETag", etag)
+
+	for _, match := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		if match = strings.TrimSpace(match); match == etag || match == "*" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.WriteHeader(http.StatusOK)
+	buf.WriteTo(w)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/internal/util"
+	"github.com/soumikc1729/splitty/server/internal/validator"
+)
+
+func (app *App) CreateCommentHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+
+	transactionID, err := util.ReadParam("transactionID", r)
+	if err != nil {
+		app.BadRequestResponse(w, r, err)
+		return
+	}
+
+	var input struct {
+		Author string `json:"author"`
+		Body   string `json:"body"`
+	}
+
+	if err := util.ReadJSON(r, &input); err != nil {
+		app.BadRequestResponse(w, r, err)
+		return
+	}
+
+	comment := &data.Comment{TransactionID: transactionID, Author: input.Author, Body: input.Body}
+
+	v := validator.New()
+
+	if data.ValidateComment(v, comment, group); !v.Valid() {
+		app.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if err := app.Data.Comments.Insert(comment, group.ID, app.Config.Data.QueryTimeout); err != nil {
+		app.DataErrorResponse(w, r, err)
+		return
+	}
+
+	header := make(http.Header)
+	header.Set("Location", fmt.Sprintf("/v1/groups/%d/transactions/%d/comments/%d", group.ID, transactionID, comment.ID))
+
+	if err := util.WriteJSON(w, http.StatusCreated, util.Envelope{"comment": comment}, header); err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	app.Logger.Info().Int64("group-id", group.ID).Int64("transaction-id", transactionID).Int64("comment-id", comment.ID).Msg("created comment")
+}
+
+func (app *App) ListCommentsHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+
+	transactionID, err := util.ReadParam("transactionID", r)
+	if err != nil {
+		app.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if _, err := app.Data.Transactions.Get(transactionID, group.ID, app.Config.Data.QueryTimeout); err != nil {
+		app.DataErrorResponse(w, r, err)
+		return
+	}
+
+	comments, err := app.Data.Comments.GetAll(transactionID, group.ID, app.Config.Data.QueryTimeout)
+	if err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if err := util.WriteJSON(w, http.StatusOK, util.Envelope{"comments": comments}, nil); err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	app.Logger.Info().Int64("group-id", group.ID).Int64("transaction-id", transactionID).Msg("retrieved comments")
+}
+
+// UpdateCommentHandler only lets the body of a comment change, its author
+// stays the same.
+func (app *App) UpdateCommentHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+
+	transactionID, err := util.ReadParam("transactionID", r)
+	if err != nil {
+		app.BadRequestResponse(w, r, err)
+		return
+	}
+
+	id, err := util.ReadParam("commentID", r)
+	if err != nil {
+		app.BadRequestResponse(w, r, err)
+		return
+	}
+
+	var input struct {
+		Body string `json:"body"`
+	}
+
+	if err := util.ReadJSON(r, &input); err != nil {
+		app.BadRequestResponse(w, r, err)
+		return
+	}
+
+	comment, err := app.Data.Comments.Get(id, transactionID, group.ID, app.Config.Data.QueryTimeout)
+	if err != nil {
+		app.DataErrorResponse(w, r, err)
+		return
+	}
+
+	comment.Body = input.Body
+
+	v := validator.New()
+
+	if data.ValidateComment(v, comment, group); !v.Valid() {
+		app.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if err := app.Data.Comments.Update(comment, app.Config.Data.QueryTimeout); err != nil {
+		app.DataErrorResponse(w, r, err)
+		return
+	}
+
+	if err := util.WriteJSON(w, http.StatusOK, util.Envelope{"comment": comment}, nil); err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	app.Logger.Info().Int64("group-id", group.ID).Int64("transaction-id", transactionID).Int64("comment-id", id).Msg("updated comment")
+}
+
+func (app *App) DeleteCommentHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+
+	transactionID, err := util.ReadParam("transactionID", r)
+	if err != nil {
+		app.BadRequestResponse(w, r, err)
+		return
+	}
+
+	id, err := util.ReadParam("commentID", r)
+	if err != nil {
+		app.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if err = app.Data.Comments.Delete(id, transactionID, group.ID, app.Config.Data.QueryTimeout); err != nil {
+		app.DataErrorResponse(w, r, err)
+		return
+	}
+
+	if err := util.WriteJSON(w, http.StatusOK, util.Envelope{"message": "comment successfully deleted"}, nil); err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	app.Logger.Info().Int64("group-id", group.ID).Int64("transaction-id", transactionID).Int64("comment-id", id).Msg("deleted comment")
+}
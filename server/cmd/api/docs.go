@@ -0,0 +1,24 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/soumikc1729/splitty/server/docs"
+)
+
+// OpenAPIHandler serves the OpenAPI document of the API, which
+// TestRoutesMatchSpec checks against the routes.
+func (app *App) OpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.WriteHeader(http.StatusOK)
+	w.Write(docs.OpenAPI)
+}
+
+// DocsHandler serves a page rendering the OpenAPI document.
+func (app *App) DocsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.WriteHeader(http.StatusOK)
+	w.Write(docs.Page)
+}
@@ -4,13 +4,29 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 
 	"github.com/soumikc1729/splitty/server/internal/data"
 	"github.com/soumikc1729/splitty/server/internal/util"
 )
 
 func (app *App) LogError(r *http.Request, err interface{}) {
-	app.Logger.Error().Interface("error", err).Str("request_method", r.Method).Str("request_url", r.URL.String()).Msg("an error occurred")
+	app.Logger.Error().Interface("error", err).Str("request_method", r.Method).Str("request_url", redactURL(r.URL)).Msg("an error occurred")
+}
+
+// redactURL returns the URL with the group token of shared links hidden, so
+// that it can be logged.
+func redactURL(u *url.URL) string {
+	query := u.Query()
+	if !query.Has("token") {
+		return u.String()
+	}
+
+	query.Set("token", "REDACTED")
+
+	redacted := *u
+	redacted.RawQuery = query.Encode()
+	return redacted.String()
 }
 
 func (app *App) ErrorResponse(w http.ResponseWriter, r *http.Request, status int, message interface{}) {
@@ -44,14 +60,46 @@ func (app *App) ServerErrorResponse(w http.ResponseWriter, r *http.Request, err
 	app.ErrorResponse(w, r, http.StatusInternalServerError, err.Error())
 }
 
+func (app *App) GroupPendingDeletionResponse(w http.ResponseWriter, r *http.Request) {
+	app.ErrorResponse(w, r, http.StatusConflict, "the group is scheduled for deletion and is read-only, cancel the deletion to modify it")
+}
+
+func (app *App) GroupArchivedResponse(w http.ResponseWriter, r *http.Request) {
+	app.ErrorResponse(w, r, http.StatusConflict, "the group is archived and is read-only, unarchive it to modify it")
+}
+
+func (app *App) AttachmentTooLargeResponse(w http.ResponseWriter, r *http.Request) {
+	message := fmt.Sprintf("the attachment must not be larger than %d bytes", app.Config.Blob.MaxSize)
+	app.ErrorResponse(w, r, http.StatusRequestEntityTooLarge, message)
+}
+
+func (app *App) UnsupportedMediaTypeResponse(w http.ResponseWriter, r *http.Request, contentType string) {
+	message := fmt.Sprintf("attachments of type %s are not supported, upload a JPEG, PNG, GIF, WebP or PDF file", contentType)
+	app.ErrorResponse(w, r, http.StatusUnsupportedMediaType, message)
+}
+
+func (app *App) PostgresRequiredResponse(w http.ResponseWriter, r *http.Request) {
+	app.ErrorResponse(w, r, http.StatusNotImplemented, "this feature is only available when the server stores its data in Postgres")
+}
+
 func (app *App) EditConflictResponse(w http.ResponseWriter, r *http.Request) {
 	app.ErrorResponse(w, r, http.StatusConflict, "unable to update the record due to an edit conflict, please try again")
 }
 
+func (app *App) DuplicateClientIDResponse(w http.ResponseWriter, r *http.Request) {
+	app.ErrorResponse(w, r, http.StatusConflict, "the client_id is already used by another transaction")
+}
+
 func (app *App) DataErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
 	switch {
 	case errors.Is(err, data.ErrRecordNotFound):
 		app.NotFoundResponse(w, r)
+	case errors.Is(err, data.ErrEditConflict):
+		app.EditConflictResponse(w, r)
+	case errors.Is(err, data.ErrGroupArchived):
+		app.GroupArchivedResponse(w, r)
+	case errors.Is(err, data.ErrDuplicateClientID):
+		app.DuplicateClientIDResponse(w, r)
 	default:
 		app.ServerErrorResponse(w, r, err)
 	}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestRedactURL(t *testing.T) {
+	for _, tt := range []struct {
+		url  string
+		want string
+	}{
+		{"/v1/groups/1/report", "/v1/groups/1/report"},
+		{"/v1/groups/1/report?from=2024-05-01", "/v1/groups/1/report?from=2024-05-01"},
+		{"/v1/groups/1/report?token=abc123XYZ", "/v1/groups/1/report?token=REDACTED"},
+		{"/v1/groups/1/events?last_event_id=4&token=abc123XYZ", "/v1/groups/1/events?last_event_id=4&token=REDACTED"},
+	} {
+		u, err := url.Parse(tt.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := redactURL(u); got != tt.want {
+			t.Errorf("redactURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
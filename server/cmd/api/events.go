@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/internal/events"
+	"github.com/soumikc1729/splitty/server/internal/validator"
+)
+
+const (
+	// replayBatchSize is how many stored events are read at a time when a
+	// stream resumes.
+	replayBatchSize = 500
+	// streamRetry tells clients how long to wait before reconnecting.
+	streamRetry = 3 * time.Second
+)
+
+// GroupEventsHandler streams the events of the group as Server-Sent Events.
+// Clients that reconnect with the Last-Event-ID header, or the last_event_id
+// query parameter, first receive every stored event they missed. As an event
+// can be stored after events with higher IDs, the stored events within
+// events.GapWindow before the given ID are sent again too, and clients skip
+// those they already have by their ID.
+func (app *App) GroupEventsHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+
+	v := validator.New()
+
+	lastEventID, resume := readLastEventID(r, v)
+
+	if !v.Valid() {
+		app.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// The stream outlives the read and write timeouts of the server, which
+	// would otherwise cut it off.
+	rc := http.NewResponseController(w)
+
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if err := rc.SetReadDeadline(time.Time{}); err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	// Subscribing before replaying makes sure no event falls in between.
+	// Events that arrive both ways are only sent once.
+	sub := app.Events.Subscribe(group.ID)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", streamRetry.Milliseconds()); err != nil {
+		return
+	}
+
+	cursor := events.NewCursor(max(0, lastEventID-events.GapWindow))
+
+	if resume {
+		afterID := cursor.Resume()
+
+		for {
+			missed, err := app.Data.Events.GetAllAfterID(afterID, group.ID, replayBatchSize, app.Config.Data.QueryTimeout)
+			if err != nil {
+				app.LogError(r, err)
+				return
+			}
+
+			for _, event := range missed {
+				afterID = event.ID
+				cursor.Add(event.ID)
+
+				if err := writeEvent(w, event); err != nil {
+					return
+				}
+			}
+
+			if len(missed) < replayBatchSize {
+				break
+			}
+		}
+	}
+
+	if err := rc.Flush(); err != nil {
+		app.LogError(r, err)
+		return
+	}
+
+	app.Logger.Info().Int64("group-id", group.ID).Int64("last-event-id", lastEventID).Msg("opened event stream")
+
+	heartbeat := time.NewTicker(app.Config.Events.HeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-app.Server.ShuttingDown():
+			return
+		case event, ok := <-sub.C:
+			if !ok {
+				// The stream fell behind. The client reconnects and catches
+				// up from the stored events.
+				return
+			}
+
+			if !cursor.Add(event.ID) {
+				continue
+			}
+
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event *events.Event) error {
+	js, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, js)
+	return err
+}
+
+func readLastEventID(r *http.Request, v *validator.Validator) (int64, bool) {
+	s := r.Header.Get("Last-Event-ID")
+	if s == "" {
+		s = r.URL.Query().Get("last_event_id")
+	}
+
+	if s == "" {
+		return 0, false
+	}
+
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || id < 0 {
+		v.AddError("last_event_id", "must be a non-negative integer")
+		return 0, false
+	}
+
+	return id, true
+}
+
+// publish stores the event and passes it on to the streams of the group. With
+// Postgres, every instance, this one included, passes it on once notified by
+// the database, and it is queued for delivery to the webhooks of the group.
+// Otherwise this is the only instance, which passes it on itself. The change
+// it describes has already been made, so failures are only logged.
+func (app *App) publish(eventType events.Type, groupID int64, payload interface{}) {
+	event := events.New(eventType, groupID, payload)
+
+	if err := app.Data.Events.Insert(event, app.Config.Data.QueryTimeout); err != nil {
+		app.Logger.Err(err).Int64("group-id", groupID).Str("event", string(eventType)).Msg("failed to store event")
+		return
+	}
+
+	if !app.Data.Postgres() {
+		app.Events.Publish(event)
+		return
+	}
+
+	queued, err := app.Data.Deliveries.Enqueue(event, app.Config.Data.QueryTimeout)
+	if err != nil {
+		app.Logger.Err(err).Int64("group-id", groupID).Str("event", string(eventType)).Msg("failed to queue webhook deliveries")
+		return
+	}
+
+	if queued > 0 {
+		select {
+		case app.webhookWakeup <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// publishGroup publishes a group event. The token of the group is left out,
+// as it grants access to the group.
+func (app *App) publishGroup(eventType events.Type, group *data.Group) {
+	payload := struct {
+		*data.Group
+		Token string `json:"token,omitempty"`
+	}{Group: group}
+
+	app.publish(eventType, group.ID, payload)
+}
+
+func (app *App) publishTransactions(transactions []*data.Transaction) {
+	for _, transaction := range transactions {
+		app.publish(events.TransactionCreated, transaction.GroupID, transaction)
+	}
+}
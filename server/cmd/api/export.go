@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/soumikc1729/splitty/server/internal/export"
+	"github.com/soumikc1729/splitty/server/internal/validator"
+)
+
+func (app *App) ExportGroupHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+
+	format := export.Format(r.URL.Query().Get("format"))
+	if format == "" {
+		format = export.CSV
+	}
+
+	opts := export.Options{Commodity: r.URL.Query().Get("commodity")}
+	if opts.Commodity == "" {
+		opts.Commodity = group.Settings.Currency
+	}
+
+	v := validator.New()
+
+	v.Check(validator.In(string(format), export.Formats...), "format", "must be one of csv, xlsx, ledger, hledger or beancount")
+	v.Check(validator.Matches(opts.Commodity, export.CommodityRX), "commodity", "must be 2-24 characters long, start with an uppercase letter and contain only uppercase letters, numbers, apostrophes, periods, underscores, and hyphens")
+
+	if !v.Valid() {
+		app.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	w.Header().Set("Content-Type", export.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="group-%d.%s"`, group.ID, export.Extension(format)))
+
+	cw := &countingWriter{w: w}
+
+	exporter, err := export.New(format, cw, group, opts)
+	if err != nil {
+		app.exportFailed(w, r, cw, err)
+		return
+	}
+
+	err = app.Data.Transactions.StreamAllAfterID(0, group.ID, app.Config.Data.StreamTimeout, exporter.Write)
+	if err != nil {
+		app.exportFailed(w, r, cw, err)
+		return
+	}
+
+	if err := exporter.Close(); err != nil {
+		app.exportFailed(w, r, cw, err)
+		return
+	}
+
+	app.Logger.Info().Int64("group-id", group.ID).Str("format", string(format)).Msg("exported transactions")
+}
+
+// exportFailed answers with an error if nothing of the export has been sent
+// yet. Otherwise an error response would be appended to a partly written
+// file, so the response is aborted instead, which the client sees as a
+// broken download.
+func (app *App) exportFailed(w http.ResponseWriter, r *http.Request, cw *countingWriter, err error) {
+	if cw.n == 0 {
+		w.Header().Del("Content-Disposition")
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	app.LogError(r, err)
+	panic(http.ErrAbortHandler)
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
@@ -4,16 +4,21 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/soumikc1729/splitty/server/internal/balance"
 	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/internal/events"
 	"github.com/soumikc1729/splitty/server/internal/util"
 	"github.com/soumikc1729/splitty/server/internal/validator"
+	"github.com/soumikc1729/splitty/server/model"
 )
 
 func (app *App) CreateGroupHandler(w http.ResponseWriter, r *http.Request) {
 	var input struct {
-		Name  string   `json:"name"`
-		Users []string `json:"users"`
+		Name     string        `json:"name"`
+		Users    []string      `json:"users"`
+		Settings settingsInput `json:"settings"`
 	}
 
 	err := util.ReadJSON(r, &input)
@@ -22,7 +27,8 @@ func (app *App) CreateGroupHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	group := &data.Group{Name: input.Name, Users: input.Users}
+	group := &data.Group{Name: input.Name, Users: input.Users, Settings: model.DefaultSettings()}
+	input.Settings.apply(&group.Settings)
 
 	v := validator.New()
 
@@ -37,6 +43,8 @@ func (app *App) CreateGroupHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	app.publishGroup(events.GroupCreated, group)
+
 	header := make(http.Header)
 	header.Set("Location", fmt.Sprintf("/v1/groups/%d", group.ID))
 
@@ -98,11 +106,13 @@ func (app *App) UpdateGroupHandler(w http.ResponseWriter, r *http.Request) {
 		case errors.Is(err, data.ErrEditConflict):
 			app.EditConflictResponse(w, r)
 		default:
-			app.ServerErrorResponse(w, r, err)
+			app.DataErrorResponse(w, r, err)
 		}
 		return
 	}
 
+	app.publishGroup(events.GroupUpdated, group)
+
 	if err := util.WriteJSON(w, http.StatusOK, util.Envelope{"group": group}, nil); err != nil {
 		app.ServerErrorResponse(w, r, err)
 	}
@@ -110,19 +120,208 @@ func (app *App) UpdateGroupHandler(w http.ResponseWriter, r *http.Request) {
 	app.Logger.Info().Int64("id", group.ID).Msg("updated group")
 }
 
+// settingsInput holds the settings sent by a client. Settings that are left
+// out keep their current value.
+type settingsInput struct {
+	Currency      *string         `json:"currency"`
+	DefaultSplit  *data.SplitMode `json:"default_split"`
+	Locale        *string         `json:"locale"`
+	Timezone      *string         `json:"timezone"`
+	SimplifyDebts *bool           `json:"simplify_debts"`
+	Precision     *int            `json:"precision"`
+}
+
+func (input *settingsInput) apply(settings *data.Settings) {
+	if input.Currency != nil {
+		settings.Currency = *input.Currency
+	}
+	if input.DefaultSplit != nil {
+		settings.DefaultSplit = *input.DefaultSplit
+	}
+	if input.Locale != nil {
+		settings.Locale = *input.Locale
+	}
+	if input.Timezone != nil {
+		settings.Timezone = *input.Timezone
+	}
+	if input.SimplifyDebts != nil {
+		settings.SimplifyDebts = *input.SimplifyDebts
+	}
+	if input.Precision != nil {
+		settings.Precision = *input.Precision
+	}
+}
+
+func (app *App) UpdateGroupSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+
+	var input settingsInput
+
+	if err := util.ReadJSON(r, &input); err != nil {
+		app.BadRequestResponse(w, r, err)
+		return
+	}
+
+	input.apply(&group.Settings)
+
+	v := validator.New()
+
+	if data.ValidateSettings(v, group.Settings); !v.Valid() {
+		app.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if err := app.Data.Groups.Update(group, app.Config.Data.QueryTimeout); err != nil {
+		app.DataErrorResponse(w, r, err)
+		return
+	}
+
+	app.publishGroup(events.GroupUpdated, group)
+
+	if err := util.WriteJSON(w, http.StatusOK, util.Envelope{"settings": group.Settings}, nil); err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	app.Logger.Info().Int64("id", group.ID).Msg("updated group settings")
+}
+
+// DeleteGroupHandler schedules the group for deletion once the grace period
+// has passed. Until then the group is read-only and the deletion can be
+// cancelled.
 func (app *App) DeleteGroupHandler(w http.ResponseWriter, r *http.Request) {
 	group := app.ContextGetGroup(r)
 
-	err := app.Data.Groups.Delete(group.ID, group.Token, app.Config.Data.QueryTimeout)
+	if !group.PendingDeletion() {
+		deleteAfter := time.Now().Add(app.Config.Data.DeletionGracePeriod)
+
+		err := app.Data.Groups.ScheduleDeletion(group, &deleteAfter, app.Config.Data.QueryTimeout)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrEditConflict):
+				app.EditConflictResponse(w, r)
+			default:
+				app.ServerErrorResponse(w, r, err)
+			}
+			return
+		}
+
+		// Webhooks are purged along with the group, so subscribers learn
+		// about the deletion when it is scheduled.
+		app.publishGroup(events.GroupDeleted, group)
+	}
+
+	err := util.WriteJSON(w, http.StatusAccepted, util.Envelope{"group": group}, nil)
+	if err != nil {
+		app.ServerErrorResponse(w, r, err)
+	}
+
+	app.Logger.Info().Int64("id", group.ID).Time("delete-after", *group.DeleteAfter).Msg("scheduled group deletion")
+}
+
+func (app *App) CancelGroupDeletionHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+
+	if !group.PendingDeletion() {
+		app.ErrorResponse(w, r, http.StatusConflict, "the group is not scheduled for deletion")
+		return
+	}
+
+	err := app.Data.Groups.ScheduleDeletion(group, nil, app.Config.Data.QueryTimeout)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.EditConflictResponse(w, r)
+		default:
+			app.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.publishGroup(events.GroupUpdated, group)
+
+	err = util.WriteJSON(w, http.StatusOK, util.Envelope{"group": group}, nil)
+	if err != nil {
+		app.ServerErrorResponse(w, r, err)
+	}
+
+	app.Logger.Info().Int64("id", group.ID).Msg("cancelled group deletion")
+}
+
+// ArchiveGroupHandler closes the group, after which it can still be read and
+// exported but no longer modified. With require_settled set, archiving fails
+// unless every balance is zero.
+func (app *App) ArchiveGroupHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+
+	v := validator.New()
+
+	requireSettled := app.readBool(r.URL.Query(), "require_settled", false, v)
+
+	if !v.Valid() {
+		app.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if requireSettled {
+		summary := balance.New(group.Users)
+
+		err := app.Data.Transactions.StreamAllAfterID(0, group.ID, app.Config.Data.StreamTimeout, func(t *data.Transaction) error {
+			summary.Add(t)
+			return nil
+		})
+		if err != nil {
+			app.ServerErrorResponse(w, r, err)
+			return
+		}
+
+		if settlements := summary.Settlements(); len(settlements) > 0 {
+			app.ErrorResponse(w, r, http.StatusConflict, util.Envelope{
+				"message":     "all balances must be settled before archiving the group",
+				"settlements": settlements,
+			})
+			return
+		}
+	}
+
+	now := time.Now()
+
+	err := app.Data.Groups.SetArchived(group, &now, app.Config.Data.QueryTimeout)
 	if err != nil {
 		app.DataErrorResponse(w, r, err)
 		return
 	}
 
-	err = util.WriteJSON(w, http.StatusOK, util.Envelope{"message": "group successfully deleted"}, nil)
+	app.publishGroup(events.GroupUpdated, group)
+
+	err = util.WriteJSON(w, http.StatusOK, util.Envelope{"group": group}, nil)
+	if err != nil {
+		app.ServerErrorResponse(w, r, err)
+	}
+
+	app.Logger.Info().Int64("id", group.ID).Bool("require-settled", requireSettled).Msg("archived group")
+}
+
+func (app *App) UnarchiveGroupHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+
+	if !group.Archived() {
+		app.ErrorResponse(w, r, http.StatusConflict, "the group is not archived")
+		return
+	}
+
+	err := app.Data.Groups.SetArchived(group, nil, app.Config.Data.QueryTimeout)
+	if err != nil {
+		app.DataErrorResponse(w, r, err)
+		return
+	}
+
+	app.publishGroup(events.GroupUpdated, group)
+
+	err = util.WriteJSON(w, http.StatusOK, util.Envelope{"group": group}, nil)
 	if err != nil {
 		app.ServerErrorResponse(w, r, err)
 	}
 
-	app.Logger.Info().Int64("id", group.ID).Msg("deleted group")
+	app.Logger.Info().Int64("id", group.ID).Msg("unarchived group")
 }
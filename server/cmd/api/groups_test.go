@@ -0,0 +1,266 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/model"
+)
+
+// racingGroups updates a group between it being read and written by a
+// handler, as a concurrent request would.
+type racingGroups struct {
+	data.GroupStore
+}
+
+func (g racingGroups) GetByIDAndToken(id int64, token string, timeout time.Duration) (*data.Group, error) {
+	group, err := g.GroupStore.GetByIDAndToken(id, token, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	other, err := g.GroupStore.GetByIDAndToken(id, token, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	other.Name = "Renamed elsewhere"
+	if err := g.GroupStore.Update(other, timeout); err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+func TestCreateGroup(t *testing.T) {
+	app := newTestApp(t)
+
+	t.Run("created", func(t *testing.T) {
+		group := app.createTestGroup(t)
+
+		if group.ID == 0 || group.Token == "" {
+			t.Fatalf("got id %d and token %q, want both set", group.ID, group.Token)
+		}
+		if group.Settings != model.DefaultSettings() {
+			t.Errorf("got settings %+v, want the defaults", group.Settings)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		res := app.do(t, http.MethodPost, "/v1/groups", "", map[string]interface{}{
+			"name":  "Trip",
+			"users": []string{"alice"},
+		})
+		wantStatus(t, res, http.StatusUnprocessableEntity)
+
+		var errs map[string]string
+		res.decode(t, "error", &errs)
+		if errs["users"] == "" {
+			t.Errorf("got errors %v, want one for users", errs)
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		res := app.do(t, http.MethodPost, "/v1/groups", "", map[string]interface{}{"name": 1})
+		wantStatus(t, res, http.StatusBadRequest)
+	})
+}
+
+func TestGetGroup(t *testing.T) {
+	app := newTestApp(t)
+	group := app.createTestGroup(t)
+
+	t.Run("found", func(t *testing.T) {
+		res := app.do(t, http.MethodGet, groupPath(group, ""), group.Token, nil)
+		wantStatus(t, res, http.StatusOK)
+
+		var got data.Group
+		res.decode(t, "group", &got)
+		if got.ID != group.ID || got.Name != group.Name || !slices.Equal(got.Users, group.Users) {
+			t.Errorf("got %+v, want %+v", got, *group)
+		}
+	})
+
+	// Groups that do not exist and tokens that do not match are not told
+	// apart, and both are answered like unknown routes.
+	for _, tt := range []struct {
+		name  string
+		path  string
+		token string
+	}{
+		{"unknown group", "/v1/groups/9999", group.Token},
+		{"wrong token", groupPath(group, ""), data.GenerateRandomToken()},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			res := app.do(t, http.MethodGet, tt.path, tt.token, nil)
+			wantStatus(t, res, http.StatusBadRequest)
+
+			if got, want := res.errorMessage(t), "the requested resource could not be found"; got != want {
+				t.Errorf("got error %q, want %q", got, want)
+			}
+		})
+	}
+
+	t.Run("no token", func(t *testing.T) {
+		res := app.do(t, http.MethodGet, groupPath(group, ""), "", nil)
+		wantStatus(t, res, http.StatusBadRequest)
+	})
+}
+
+func TestUpdateGroup(t *testing.T) {
+	app := newTestApp(t)
+	group := app.createTestGroup(t)
+
+	t.Run("updated", func(t *testing.T) {
+		res := app.do(t, http.MethodPatch, groupPath(group, ""), group.Token, map[string]interface{}{
+			"name":  "Road trip",
+			"users": []string{"alice", "bob", "carol", "dave"},
+		})
+		wantStatus(t, res, http.StatusOK)
+
+		res = app.do(t, http.MethodGet, groupPath(group, ""), group.Token, nil)
+		wantStatus(t, res, http.StatusOK)
+
+		var got data.Group
+		res.decode(t, "group", &got)
+		if got.Name != "Road trip" || !slices.Equal(got.Users, []string{"alice", "bob", "carol", "dave"}) {
+			t.Errorf("got name %q and users %v after the update", got.Name, got.Users)
+		}
+	})
+
+	t.Run("users removed", func(t *testing.T) {
+		res := app.do(t, http.MethodPatch, groupPath(group, ""), group.Token, map[string]interface{}{
+			"name":  "Road trip",
+			"users": []string{"alice", "bob"},
+		})
+		wantStatus(t, res, http.StatusUnprocessableEntity)
+	})
+
+	t.Run("version conflict", func(t *testing.T) {
+		app := newTestApp(t)
+		group := app.createTestGroup(t)
+
+		app.Data.Groups = racingGroups{app.Data.Groups}
+
+		res := app.do(t, http.MethodPatch, groupPath(group, ""), group.Token, map[string]interface{}{
+			"name":  "Road trip",
+			"users": group.Users,
+		})
+		wantStatus(t, res, http.StatusConflict)
+
+		if got, want := res.errorMessage(t), "unable to update the record due to an edit conflict, please try again"; got != want {
+			t.Errorf("got error %q, want %q", got, want)
+		}
+	})
+}
+
+func TestArchivedGroup(t *testing.T) {
+	app := newTestApp(t)
+	group := app.createTestGroup(t)
+
+	res := app.do(t, http.MethodPost, groupPath(group, "/archive"), group.Token, nil)
+	wantStatus(t, res, http.StatusOK)
+
+	t.Run("readable", func(t *testing.T) {
+		res := app.do(t, http.MethodGet, groupPath(group, ""), group.Token, nil)
+		wantStatus(t, res, http.StatusOK)
+
+		var got data.Group
+		res.decode(t, "group", &got)
+		if got.ArchivedAt == nil {
+			t.Error("got no archived_at for an archived group")
+		}
+	})
+
+	for _, tt := range []struct {
+		name   string
+		method string
+		suffix string
+		body   interface{}
+	}{
+		{"update", http.MethodPatch, "", map[string]interface{}{"name": "Road trip", "users": group.Users}},
+		{"update settings", http.MethodPatch, "/settings", map[string]interface{}{"currency": "USD"}},
+		{"archive again", http.MethodPost, "/archive", nil},
+		{"create transaction", http.MethodPost, "/transactions", dinner()},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			res := app.do(t, tt.method, groupPath(group, tt.suffix), group.Token, tt.body)
+			wantStatus(t, res, http.StatusConflict)
+
+			if got, want := res.errorMessage(t), "the group is archived and is read-only, unarchive it to modify it"; got != want {
+				t.Errorf("got error %q, want %q", got, want)
+			}
+		})
+	}
+
+	t.Run("unarchived", func(t *testing.T) {
+		res := app.do(t, http.MethodDelete, groupPath(group, "/archive"), group.Token, nil)
+		wantStatus(t, res, http.StatusOK)
+
+		res = app.do(t, http.MethodDelete, groupPath(group, "/archive"), group.Token, nil)
+		wantStatus(t, res, http.StatusConflict)
+
+		res = app.do(t, http.MethodPost, groupPath(group, "/transactions"), group.Token, dinner())
+		wantStatus(t, res, http.StatusCreated)
+	})
+}
+
+func TestGroupPendingDeletion(t *testing.T) {
+	app := newTestApp(t)
+	group := app.createTestGroup(t)
+
+	res := app.do(t, http.MethodDelete, groupPath(group, ""), group.Token, nil)
+	wantStatus(t, res, http.StatusAccepted)
+
+	var scheduled data.Group
+	res.decode(t, "group", &scheduled)
+	if scheduled.DeleteAfter == nil || time.Until(*scheduled.DeleteAfter) < app.Config.Data.DeletionGracePeriod-time.Minute {
+		t.Fatalf("got delete_after %v, want the end of the grace period", scheduled.DeleteAfter)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		method string
+		suffix string
+		body   interface{}
+	}{
+		{"update", http.MethodPatch, "", map[string]interface{}{"name": "Road trip", "users": group.Users}},
+		{"archive", http.MethodPost, "/archive", nil},
+		{"create transaction", http.MethodPost, "/transactions", dinner()},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			res := app.do(t, tt.method, groupPath(group, tt.suffix), group.Token, tt.body)
+			wantStatus(t, res, http.StatusConflict)
+
+			if got, want := res.errorMessage(t), "the group is scheduled for deletion and is read-only, cancel the deletion to modify it"; got != want {
+				t.Errorf("got error %q, want %q", got, want)
+			}
+		})
+	}
+
+	t.Run("cancelled", func(t *testing.T) {
+		res := app.do(t, http.MethodDelete, groupPath(group, "/deletion"), group.Token, nil)
+		wantStatus(t, res, http.StatusOK)
+
+		res = app.do(t, http.MethodDelete, groupPath(group, "/deletion"), group.Token, nil)
+		wantStatus(t, res, http.StatusConflict)
+
+		res = app.do(t, http.MethodPost, groupPath(group, "/transactions"), group.Token, dinner())
+		wantStatus(t, res, http.StatusCreated)
+	})
+}
+
+func TestPostgresOnlyRoutes(t *testing.T) {
+	app := newTestApp(t)
+	group := app.createTestGroup(t)
+
+	for _, suffix := range []string{"/stats", "/webhooks", "/import/rules"} {
+		res := app.do(t, http.MethodGet, groupPath(group, suffix), group.Token, nil)
+		if res.status != http.StatusNotImplemented {
+			t.Errorf("GET %s: got status %d, want %d", suffix, res.status, http.StatusNotImplemented)
+		}
+	}
+}
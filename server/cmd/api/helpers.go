@@ -0,0 +1,75 @@
+package main
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/internal/validator"
+	"github.com/soumikc1729/splitty/server/model"
+)
+
+func (app *App) readString(qs url.Values, key string, defaultValue string) string {
+	s := qs.Get(key)
+	if s == "" {
+		return defaultValue
+	}
+
+	return s
+}
+
+func (app *App) readInt(qs url.Values, key string, defaultValue int, v *validator.Validator) int {
+	s := qs.Get(key)
+	if s == "" {
+		return defaultValue
+	}
+
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		v.AddError(key, "must be an integer value")
+		return defaultValue
+	}
+
+	return i
+}
+
+func (app *App) readBool(qs url.Values, key string, defaultValue bool, v *validator.Validator) bool {
+	s := qs.Get(key)
+	if s == "" {
+		return defaultValue
+	}
+
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		v.AddError(key, "must be a boolean value")
+		return defaultValue
+	}
+
+	return b
+}
+
+func (app *App) readDate(qs url.Values, key string, defaultValue data.Date, v *validator.Validator) data.Date {
+	s := qs.Get(key)
+	if s == "" {
+		return defaultValue
+	}
+
+	date, err := model.ParseDate(s)
+	if err != nil {
+		v.AddError(key, "must be a date in the format YYYY-MM-DD")
+		return defaultValue
+	}
+
+	return date
+}
+
+// readDateRange reads the inclusive from and to query parameters and returns
+// them as the half-open range [from, to).
+func (app *App) readDateRange(qs url.Values, defaultFrom, defaultTo data.Date, v *validator.Validator) (data.Date, data.Date) {
+	from := app.readDate(qs, "from", defaultFrom, v)
+	to := app.readDate(qs, "to", defaultTo, v)
+
+	v.Check(!to.Before(from.Time), "to", "must not be before from")
+
+	return from, to.AddDays(1)
+}
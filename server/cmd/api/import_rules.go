@@ -0,0 +1,138 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/internal/util"
+	"github.com/soumikc1729/splitty/server/internal/validator"
+)
+
+func (app *App) CreateImportRuleHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+	if rule := app.validateImportRuleInput(w, r, group); rule != nil {
+		if err := app.Data.ImportRules.Insert(rule, app.Config.Data.QueryTimeout); err != nil {
+			app.DataErrorResponse(w, r, err)
+			return
+		}
+
+		if err := util.WriteJSON(w, http.StatusCreated, util.Envelope{"rule": rule}, nil); err != nil {
+			app.ServerErrorResponse(w, r, err)
+			return
+		}
+
+		app.Logger.Info().Int64("group-id", group.ID).Int64("rule-id", rule.ID).Msg("created import rule")
+	}
+}
+
+func (app *App) ListImportRulesHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+
+	rules, err := app.Data.ImportRules.GetAll(group.ID, app.Config.Data.QueryTimeout)
+	if err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if err := util.WriteJSON(w, http.StatusOK, util.Envelope{"rules": rules}, nil); err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	app.Logger.Info().Int64("group-id", group.ID).Msg("retrieved import rules")
+}
+
+func (app *App) UpdateImportRuleHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+	if updatedRule := app.validateImportRuleInput(w, r, group); updatedRule != nil {
+		id, err := util.ReadParam("ruleID", r)
+		if err != nil {
+			app.BadRequestResponse(w, r, err)
+			return
+		}
+
+		rule, err := app.Data.ImportRules.Get(id, group.ID, app.Config.Data.QueryTimeout)
+		if err != nil {
+			app.DataErrorResponse(w, r, err)
+			return
+		}
+
+		updatedRule.ID = id
+		updatedRule.Version = rule.Version
+
+		if err := app.Data.ImportRules.Update(updatedRule, app.Config.Data.QueryTimeout); err != nil {
+			switch {
+			case errors.Is(err, data.ErrEditConflict):
+				app.EditConflictResponse(w, r)
+			default:
+				app.DataErrorResponse(w, r, err)
+			}
+			return
+		}
+
+		if err := util.WriteJSON(w, http.StatusOK, util.Envelope{"rule": updatedRule}, nil); err != nil {
+			app.ServerErrorResponse(w, r, err)
+			return
+		}
+
+		app.Logger.Info().Int64("group-id", group.ID).Int64("rule-id", id).Msg("updated import rule")
+	}
+}
+
+func (app *App) DeleteImportRuleHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+
+	id, err := util.ReadParam("ruleID", r)
+	if err != nil {
+		app.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if err = app.Data.ImportRules.Delete(id, group.ID, app.Config.Data.QueryTimeout); err != nil {
+		app.DataErrorResponse(w, r, err)
+		return
+	}
+
+	if err := util.WriteJSON(w, http.StatusOK, util.Envelope{"message": "import rule successfully deleted"}, nil); err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	app.Logger.Info().Int64("group-id", group.ID).Int64("rule-id", id).Msg("deleted import rule")
+}
+
+func (app *App) validateImportRuleInput(w http.ResponseWriter, r *http.Request, group *data.Group) *data.ImportRule {
+	var input struct {
+		Pattern  string   `json:"pattern"`
+		Category string   `json:"category"`
+		Payer    string   `json:"payer"`
+		Split    []string `json:"split"`
+	}
+
+	if err := util.ReadJSON(r, &input); err != nil {
+		app.BadRequestResponse(w, r, err)
+		return nil
+	}
+
+	rule := &data.ImportRule{
+		Pattern:  input.Pattern,
+		Category: input.Category,
+		Payer:    input.Payer,
+		Split:    input.Split,
+		GroupID:  group.ID,
+	}
+
+	if rule.Split == nil {
+		rule.Split = []string{}
+	}
+
+	v := validator.New()
+
+	if data.ValidateImportRule(v, rule, group); !v.Valid() {
+		app.FailedValidationResponse(w, r, v.Errors)
+		return nil
+	}
+
+	return rule
+}
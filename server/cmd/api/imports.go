@@ -0,0 +1,317 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/internal/events"
+	"github.com/soumikc1729/splitty/server/internal/importer"
+	"github.com/soumikc1729/splitty/server/internal/util"
+	"github.com/soumikc1729/splitty/server/internal/validator"
+	"github.com/soumikc1729/splitty/server/model"
+)
+
+type importInput struct {
+	Source  string            `json:"source"`
+	CSV     string            `json:"csv"`
+	Members map[string]string `json:"members"`
+	Commit  bool              `json:"commit"`
+}
+
+func (app *App) ImportGroupHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		importInput
+		Name string `json:"name"`
+	}
+
+	if err := util.ReadJSON(r, &input); err != nil {
+		app.BadRequestResponse(w, r, err)
+		return
+	}
+
+	result := app.parseImport(w, r, &input.importInput)
+	if result == nil {
+		return
+	}
+
+	group := &data.Group{Name: input.Name, Users: result.Members, Settings: model.DefaultSettings()}
+
+	v := validator.New()
+
+	if data.ValidateGroup(v, group); !v.Valid() {
+		app.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	transactions := app.importTransactions(result, group)
+
+	if !input.Commit {
+		app.writeImportPreview(w, r, result, transactions)
+		return
+	}
+
+	if err := app.Data.Groups.Insert(group, app.Config.Data.QueryTimeout); err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	for _, transaction := range transactions {
+		transaction.GroupID = group.ID
+	}
+
+	if err := app.Data.Transactions.InsertAll(transactions, app.Config.Data.QueryTimeout); err != nil {
+		if err := app.discardGroup(group); err != nil {
+			app.LogError(r, err)
+		}
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	app.publishGroup(events.GroupCreated, group)
+	app.publishTransactions(transactions)
+
+	header := make(http.Header)
+	header.Set("Location", fmt.Sprintf("/v1/groups/%d", group.ID))
+
+	env := util.Envelope{"group": group, "transactions": transactions, "warnings": result.Warnings}
+	if err := util.WriteJSON(w, http.StatusCreated, env, header); err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	app.Logger.Info().Int64("id", group.ID).Str("source", string(result.Source)).Int("transactions", len(transactions)).Msg("imported group")
+}
+
+func (app *App) ImportTransactionsHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+
+	var input importInput
+
+	if err := util.ReadJSON(r, &input); err != nil {
+		app.BadRequestResponse(w, r, err)
+		return
+	}
+
+	result := app.parseImport(w, r, &input)
+	if result == nil {
+		return
+	}
+
+	v := validator.New()
+
+	for _, member := range result.Members {
+		v.Check(validator.In(member, group.Users...), "members", fmt.Sprintf("'%s' does not match any group user, map it to one", member))
+	}
+
+	if !v.Valid() {
+		app.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	transactions := app.importTransactions(result, group)
+
+	if !input.Commit {
+		app.writeImportPreview(w, r, result, transactions)
+		return
+	}
+
+	if err := app.Data.Transactions.InsertAll(transactions, app.Config.Data.QueryTimeout); err != nil {
+		app.DataErrorResponse(w, r, err)
+		return
+	}
+
+	app.publishTransactions(transactions)
+
+	env := util.Envelope{"transactions": transactions, "warnings": result.Warnings}
+	if err := util.WriteJSON(w, http.StatusCreated, env, nil); err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	app.Logger.Info().Int64("group-id", group.ID).Str("source", string(result.Source)).Int("transactions", len(transactions)).Msg("imported transactions")
+}
+
+func (app *App) ImportStatementHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+
+	var input struct {
+		CSV          string                    `json:"csv"`
+		Mapping      importer.StatementMapping `json:"mapping"`
+		DefaultPayer string                    `json:"default_payer"`
+		Skip         []int                     `json:"skip"`
+		Include      []int                     `json:"include"`
+		Commit       bool                      `json:"commit"`
+	}
+
+	input.Mapping.DateFormat = importer.DefaultStatementDateFormat
+	input.Mapping.Delimiter = ","
+
+	if err := util.ReadJSON(r, &input); err != nil {
+		app.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	v.Check(strings.TrimSpace(input.CSV) != "", "csv", "must be provided")
+	v.Check(input.DefaultPayer == "" || validator.In(input.DefaultPayer, group.Users...), "default_payer", fmt.Sprintf("%s not one of the group users", input.DefaultPayer))
+
+	if importer.ValidateStatementMapping(v, &input.Mapping); !v.Valid() {
+		app.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	rows, warnings, err := importer.ParseStatement(strings.NewReader(input.CSV), &input.Mapping)
+	if err != nil {
+		app.BadRequestResponse(w, r, err)
+		return
+	}
+
+	rules, err := app.Data.ImportRules.GetAll(group.ID, app.Config.Data.QueryTimeout)
+	if err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	proposals, ruleWarnings := importer.ApplyRules(rows, rules, group, input.DefaultPayer)
+	warnings = append(warnings, ruleWarnings...)
+
+	existing := make(map[string]bool)
+	err = app.Data.Transactions.StreamAllAfterID(0, group.ID, app.Config.Data.StreamTimeout, func(transaction *data.Transaction) error {
+		existing[importer.Fingerprint(transaction)] = true
+		return nil
+	})
+	if err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	transactions := []*data.Transaction{}
+
+	for i := range proposals {
+		proposal := &proposals[i]
+		fingerprint := importer.Fingerprint(proposal.Transaction)
+
+		// Rows repeating a row imported earlier in the same statement are
+		// duplicates as well. Duplicates listed in include are imported anyway,
+		// for expenses that really were made twice.
+		proposal.Duplicate = existing[fingerprint]
+
+		if slices.Contains(input.Skip, proposal.Line) || (proposal.Duplicate && !slices.Contains(input.Include, proposal.Line)) {
+			continue
+		}
+
+		v := validator.New()
+
+		if data.ValidateTransaction(v, proposal.Transaction, group); !v.Valid() {
+			for key, message := range v.Errors {
+				warnings = append(warnings, importer.Warning{Line: proposal.Line, Message: fmt.Sprintf("%s %s, skipping row", key, message)})
+			}
+			continue
+		}
+
+		existing[fingerprint] = true
+		transactions = append(transactions, proposal.Transaction)
+	}
+
+	if !input.Commit {
+		env := util.Envelope{"rows": proposals, "warnings": warnings}
+		if err := util.WriteJSON(w, http.StatusOK, env, nil); err != nil {
+			app.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	if err := app.Data.Transactions.InsertAll(transactions, app.Config.Data.QueryTimeout); err != nil {
+		app.DataErrorResponse(w, r, err)
+		return
+	}
+
+	app.publishTransactions(transactions)
+
+	env := util.Envelope{"transactions": transactions, "warnings": warnings}
+	if err := util.WriteJSON(w, http.StatusCreated, env, nil); err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	app.Logger.Info().Int64("group-id", group.ID).Int("transactions", len(transactions)).Msg("imported statement")
+}
+
+func (app *App) parseImport(w http.ResponseWriter, r *http.Request, input *importInput) *importer.Result {
+	v := validator.New()
+
+	v.Check(validator.In(input.Source, importer.Sources...), "source", "must be one of splitwise or tricount")
+	v.Check(strings.TrimSpace(input.CSV) != "", "csv", "must be provided")
+
+	if !v.Valid() {
+		app.FailedValidationResponse(w, r, v.Errors)
+		return nil
+	}
+
+	result, err := importer.Parse(importer.Source(input.Source), strings.NewReader(input.CSV))
+	if err != nil {
+		app.BadRequestResponse(w, r, err)
+		return nil
+	}
+
+	result.MapMembers(input.Members)
+
+	return result
+}
+
+// importTransactions converts the parsed rows into transactions of group,
+// turning rows that fail validation into warnings instead of rejecting the
+// whole import.
+func (app *App) importTransactions(result *importer.Result, group *data.Group) []*data.Transaction {
+	transactions := []*data.Transaction{}
+
+	for _, row := range result.Rows {
+		transaction := &data.Transaction{
+			Title:    row.Title,
+			Payments: importer.Balance(group.Settings, row.Payments),
+			Date:     row.Date,
+			GroupID:  group.ID,
+		}
+
+		if transaction.Date.IsZero() {
+			transaction.Date = model.Today(group.Settings.Location())
+		}
+
+		v := validator.New()
+
+		if data.ValidateTransaction(v, transaction, group); !v.Valid() {
+			for key, message := range v.Errors {
+				result.Warnings = append(result.Warnings, importer.Warning{Line: row.Line, Message: fmt.Sprintf("%s %s, skipping row", key, message)})
+			}
+			continue
+		}
+
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions
+}
+
+func (app *App) writeImportPreview(w http.ResponseWriter, r *http.Request, result *importer.Result, transactions []*data.Transaction) {
+	env := util.Envelope{"members": result.Members, "transactions": transactions, "warnings": result.Warnings}
+	if err := util.WriteJSON(w, http.StatusOK, env, nil); err != nil {
+		app.ServerErrorResponse(w, r, err)
+	}
+}
+
+// discardGroup immediately deletes a group whose import failed half way. The
+// time is truncated as delete_after is stored with a precision of seconds.
+func (app *App) discardGroup(group *data.Group) error {
+	now := time.Now().Truncate(time.Second)
+
+	if err := app.Data.Groups.ScheduleDeletion(group, &now, app.Config.Data.QueryTimeout); err != nil {
+		return err
+	}
+
+	return app.Data.Groups.Delete(group.ID, now, app.Config.Data.QueryTimeout)
+}
@@ -11,12 +11,29 @@ func main() {
 	var configName, configPath string
 	flag.StringVar(&configName, "config-name", "config", "config name")
 	flag.StringVar(&configPath, "config-path", ".", "config path")
+	flag.Parse()
 
 	cfg, err := config.Load[Config](configName, configPath)
 	if err != nil {
 		log.Fatalf("failed to load config: %v", err)
 	}
 
+	switch flag.Arg(0) {
+	case "":
+	case "migrate":
+		if err := runMigrate(cfg, flag.Args()[1:]); err != nil {
+			log.Fatalf("failed to migrate: %v", err)
+		}
+		return
+	case "admin":
+		if err := runAdmin(cfg, flag.Args()[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	default:
+		log.Fatalf("unknown command %q", flag.Arg(0))
+	}
+
 	app, err := NewApp(cfg)
 	if err != nil {
 		log.Fatalf("failed to create app: %v", err)
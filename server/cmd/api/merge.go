@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/internal/events"
+	"github.com/soumikc1729/splitty/server/internal/util"
+	"github.com/soumikc1729/splitty/server/internal/validator"
+)
+
+// MergeGroupHandler merges a source group, authenticated by its token in the
+// request body, into the group of the URL. Source members are matched to
+// target members by name unless mapped explicitly; members without a match
+// are added to the target group. The amounts of the source group must fit the
+// precision of the target group. The moved transactions are published as
+// created in the target group.
+func (app *App) MergeGroupHandler(w http.ResponseWriter, r *http.Request) {
+	target := app.ContextGetGroup(r)
+
+	var input struct {
+		SourceID     int64             `json:"source_id"`
+		SourceToken  string            `json:"source_token"`
+		Mapping      map[string]string `json:"mapping"`
+		SourceAction string            `json:"source_action"`
+	}
+
+	if err := util.ReadJSON(r, &input); err != nil {
+		app.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if input.SourceAction == "" {
+		input.SourceAction = string(data.MergeDelete)
+	}
+
+	v := validator.New()
+
+	v.Check(input.SourceID != target.ID, "source_id", "must not be the id of the target group")
+	v.Check(validator.Matches(input.SourceToken, data.TokenFormatRX), "source_token", "must be 9 characters long and contain only letters and numbers")
+	v.Check(validator.In(input.SourceAction, data.MergeActions...), "source_action", "must be one of delete or archive")
+
+	if !v.Valid() {
+		app.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	source, err := app.Data.Groups.GetByIDAndToken(input.SourceID, input.SourceToken, app.Config.Data.QueryTimeout)
+	if err != nil {
+		app.DataErrorResponse(w, r, err)
+		return
+	}
+
+	switch {
+	case source.PendingDeletion():
+		app.GroupPendingDeletionResponse(w, r)
+		return
+	case source.Archived():
+		app.GroupArchivedResponse(w, r)
+		return
+	}
+
+	for member := range input.Mapping {
+		v.Check(validator.In(member, source.Users...), "mapping", fmt.Sprintf("'%s' is not a member of the source group", member))
+	}
+
+	transactions, err := app.Data.Transactions.GetAllAfterID(0, source.ID, app.Config.Data.StreamTimeout)
+	if err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	for _, transaction := range *transactions {
+		for _, p := range transaction.Payments {
+			v.Check(target.Settings.HasPrecision(p.Amount), "source_id", fmt.Sprintf("the source group has amounts with more than %d decimals, the precision of the target group", target.Settings.Precision))
+		}
+	}
+
+	for _, member := range source.Users {
+		if mapped, ok := input.Mapping[member]; ok {
+			member = mapped
+		}
+
+		if !slices.Contains(target.Users, member) {
+			target.Users = append(target.Users, member)
+		}
+	}
+
+	if data.ValidateGroup(v, target); !v.Valid() {
+		app.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	moved, err := app.Data.Groups.Merge(target, source, input.Mapping, data.MergeAction(input.SourceAction), app.Config.Data.StreamTimeout)
+	if err != nil {
+		app.DataErrorResponse(w, r, err)
+		return
+	}
+
+	app.publishGroup(events.GroupUpdated, target)
+	app.publishTransactions(moved)
+
+	switch data.MergeAction(input.SourceAction) {
+	case data.MergeArchive:
+		app.publishGroup(events.GroupUpdated, source)
+	default:
+		app.publishGroup(events.GroupDeleted, source)
+	}
+
+	if err := util.WriteJSON(w, http.StatusOK, util.Envelope{"group": target, "transactions_moved": len(moved)}, nil); err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	app.Logger.Info().Int64("id", target.ID).Int64("source-id", source.ID).Int("transactions", len(moved)).Str("source-action", input.SourceAction).Msg("merged groups")
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/internal/events"
+)
+
+func mergeBody(source *data.Group, mapping map[string]string) map[string]interface{} {
+	return map[string]interface{}{
+		"source_id":    source.ID,
+		"source_token": source.Token,
+		"mapping":      mapping,
+	}
+}
+
+func TestMergeGroup(t *testing.T) {
+	app := newTestApp(t)
+	target := app.createTestGroup(t)
+	source := app.createTestGroup(t)
+
+	res := app.do(t, http.MethodPatch, groupPath(source, ""), source.Token, map[string]interface{}{
+		"name":  source.Name,
+		"users": []string{"alice", "bob", "carol", "dan"},
+	})
+	wantStatus(t, res, http.StatusOK)
+
+	moved := app.createTestTransaction(t, source, map[string]interface{}{
+		"title": "Taxi",
+		"date":  "2024-05-02",
+		"payments": []map[string]interface{}{
+			{"payer": "dan", "amount": 20},
+			{"payer": "alice", "amount": -20},
+		},
+	})
+
+	sub := app.Events.Subscribe(target.ID)
+	defer sub.Close()
+
+	res = app.do(t, http.MethodPost, groupPath(target, "/merge"), target.Token, mergeBody(source, map[string]string{"dan": "daniel"}))
+	wantStatus(t, res, http.StatusOK)
+
+	var merged data.Group
+	res.decode(t, "group", &merged)
+	if !slices.Equal(merged.Users, []string{"alice", "bob", "carol", "daniel"}) {
+		t.Errorf("got users %v, want the mapped member added", merged.Users)
+	}
+
+	var count int
+	res.decode(t, "transactions_moved", &count)
+	if count != 1 {
+		t.Errorf("got %d transactions moved, want 1", count)
+	}
+
+	transactions := app.listTestTransactions(t, target)
+	if len(transactions) != 1 || transactions[0].ID != moved.ID || transactions[0].Payments[0].Payer != "daniel" {
+		t.Errorf("got %+v in the target, want the moved transaction paid by daniel", transactions)
+	}
+
+	var types []events.Type
+	for range 2 {
+		event := <-sub.C
+		types = append(types, event.Type)
+	}
+	if !slices.Equal(types, []events.Type{events.GroupUpdated, events.TransactionCreated}) {
+		t.Errorf("got events %v, want the target updated and the transaction created", types)
+	}
+
+	res = app.do(t, http.MethodGet, groupPath(source, ""), source.Token, nil)
+	wantStatus(t, res, http.StatusBadRequest)
+}
+
+func TestMergeGroupRejected(t *testing.T) {
+	app := newTestApp(t)
+	target := app.createTestGroup(t)
+
+	t.Run("precision", func(t *testing.T) {
+		source := app.createTestGroup(t)
+
+		body := dinner()
+		body["payments"] = []map[string]interface{}{
+			{"payer": "alice", "amount": 15.5},
+			{"payer": "bob", "amount": -15.5},
+		}
+		app.createTestTransaction(t, source, body)
+
+		res := app.do(t, http.MethodPatch, groupPath(target, "/settings"), target.Token, map[string]interface{}{"precision": 0})
+		wantStatus(t, res, http.StatusOK)
+
+		res = app.do(t, http.MethodPost, groupPath(target, "/merge"), target.Token, mergeBody(source, nil))
+		wantStatus(t, res, http.StatusUnprocessableEntity)
+
+		var errs map[string]string
+		res.decode(t, "error", &errs)
+		if errs["source_id"] == "" {
+			t.Errorf("got errors %v, want one for source_id", errs)
+		}
+
+		if got := app.listTestTransactions(t, source); len(got) != 1 {
+			t.Errorf("got %d transactions left in the source, want 1", len(got))
+		}
+	})
+
+	t.Run("itself", func(t *testing.T) {
+		res := app.do(t, http.MethodPost, groupPath(target, "/merge"), target.Token, mergeBody(target, nil))
+		wantStatus(t, res, http.StatusUnprocessableEntity)
+	})
+
+	t.Run("wrong token", func(t *testing.T) {
+		source := app.createTestGroup(t)
+		source.Token = data.GenerateRandomToken()
+
+		res := app.do(t, http.MethodPost, groupPath(target, "/merge"), target.Token, mergeBody(source, nil))
+		wantStatus(t, res, http.StatusBadRequest)
+	})
+
+	t.Run("unknown member", func(t *testing.T) {
+		source := app.createTestGroup(t)
+
+		res := app.do(t, http.MethodPost, groupPath(target, "/merge"), target.Token, mergeBody(source, map[string]string{"erin": "alice"}))
+		wantStatus(t, res, http.StatusUnprocessableEntity)
+	})
+
+	t.Run("archived source", func(t *testing.T) {
+		source := app.createTestGroup(t)
+
+		res := app.do(t, http.MethodPost, groupPath(source, "/archive"), source.Token, nil)
+		wantStatus(t, res, http.StatusOK)
+
+		res = app.do(t, http.MethodPost, groupPath(target, "/merge"), target.Token, mergeBody(source, nil))
+		wantStatus(t, res, http.StatusConflict)
+	})
+}
@@ -10,6 +10,19 @@ import (
 )
 
 func (app *App) AuthenticateGroup(next http.HandlerFunc) http.HandlerFunc {
+	return app.authenticateGroup(next, false)
+}
+
+// AuthenticateGroupLink is AuthenticateGroup for read-only routes that are
+// opened as links, such as a report or the events stream in a browser, which
+// cannot set custom headers. It also accepts the token in the token query
+// parameter. Routes that modify the group must not use it, since query
+// parameters end up in logs, browser history and Referer headers.
+func (app *App) AuthenticateGroupLink(next http.HandlerFunc) http.HandlerFunc {
+	return app.authenticateGroup(next, true)
+}
+
+func (app *App) authenticateGroup(next http.HandlerFunc, allowQuery bool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id, err := util.ReadParam("groupID", r)
 		if err != nil {
@@ -17,7 +30,7 @@ func (app *App) AuthenticateGroup(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
-		token, err := readToken(r)
+		token, err := readToken(r, allowQuery)
 		if err != nil {
 			app.BadRequestResponse(w, r, err)
 			return
@@ -41,11 +54,49 @@ func (app *App) AuthenticateGroup(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
-func readToken(r *http.Request) (string, error) {
+// RequireMutableGroup rejects requests that would modify a group which is
+// scheduled for deletion or archived. It must be wrapped by AuthenticateGroup.
+func (app *App) RequireMutableGroup(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		group := app.ContextGetGroup(r)
+
+		switch {
+		case group.PendingDeletion():
+			app.GroupPendingDeletionResponse(w, r)
+			return
+		case group.Archived():
+			app.GroupArchivedResponse(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
+
+// readToken takes the group token from the X-Group-Token header, falling back
+// to the token query parameter if allowQuery is set.
+func readToken(r *http.Request, allowQuery bool) (string, error) {
 	token := r.Header.Get("X-Group-Token")
+	if token == "" && allowQuery {
+		token = r.URL.Query().Get("token")
+	}
+
 	if token == "" {
 		return "", errors.New("invalid token header")
 	}
 
 	return token, nil
 }
+
+// RequirePostgres answers with 501 Not Implemented unless the data is stored
+// in Postgres, which the features behind next need.
+func (app *App) RequirePostgres(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !app.Data.Postgres() {
+			app.PostgresRequiredResponse(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
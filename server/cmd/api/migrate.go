@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+)
+
+var errMigrateUsage = errors.New("usage: api migrate up | down [steps] | status | force version")
+
+// runMigrate runs the migrate subcommand, which manages the migrations
+// embedded into the binary:
+//
+//	api migrate up              apply all pending migrations
+//	api migrate down [steps]    revert the last steps migrations, one by default
+//	api migrate status          print the version of the schema
+//	api migrate force version   set the version after fixing a failed migration
+func runMigrate(cfg *Config, args []string) error {
+	if len(args) == 0 {
+		return errMigrateUsage
+	}
+
+	migrations, err := data.NewMigrations(&cfg.Data, func(format string, v ...interface{}) {
+		log.Printf(strings.TrimSpace(format), v...)
+	})
+	if err != nil {
+		return err
+	}
+	defer migrations.Close()
+
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		err = migrations.Up()
+	case args[0] == "down" && len(args) <= 2:
+		steps := 1
+		if len(args) == 2 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return errMigrateUsage
+			}
+		}
+		err = migrations.Down(steps)
+	case args[0] == "force" && len(args) == 2:
+		version, convErr := strconv.Atoi(args[1])
+		if convErr != nil || version < -1 {
+			return errMigrateUsage
+		}
+		err = migrations.Force(version)
+	case args[0] == "status" && len(args) == 1:
+	default:
+		return errMigrateUsage
+	}
+
+	if err != nil {
+		return err
+	}
+
+	status, err := migrations.Status()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("version %d of %d", status.Version, status.Latest)
+	switch {
+	case status.Dirty:
+		fmt.Print(", dirty")
+	case status.Pending():
+		fmt.Printf(", %d pending", status.Latest-status.Version)
+	}
+	fmt.Println()
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/internal/report"
+	"github.com/soumikc1729/splitty/server/internal/validator"
+	"github.com/soumikc1729/splitty/server/model"
+)
+
+func (app *App) ReportHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+
+	today := model.Today(group.Settings.Location())
+	firstDay := model.NewDate(today.Year(), today.Month(), 1)
+
+	v := validator.New()
+
+	from, to := app.readDateRange(r.URL.Query(), firstDay, data.Date{Time: firstDay.AddDate(0, 1, -1)}, v)
+
+	if !v.Valid() {
+		app.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	builder := report.NewBuilder(group, from, to)
+
+	err := app.Data.Transactions.StreamAllBefore(to, group.ID, app.Config.Data.StreamTimeout, builder.Add)
+	if err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := report.Render(&buf, builder.Statement()); err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	// The page is usually opened from a link holding the group token, which
+	// must not be passed on to the pages it links to.
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.WriteHeader(http.StatusOK)
+	buf.WriteTo(w)
+
+	app.Logger.Info().Int64("group-id", group.ID).Stringer("from", from).Stringer("to", to).Msg("rendered report")
+}
@@ -6,21 +6,88 @@ import (
 	"github.com/julienschmidt/httprouter"
 )
 
+// route is an endpoint of the API. Routes marked postgres need features only
+// Postgres provides, such as comments, attachments, stats and webhooks, and
+// are answered with 501 Not Implemented when the data is stored elsewhere.
+type route struct {
+	method   string
+	path     string
+	handler  http.HandlerFunc
+	postgres bool
+}
+
+func (app *App) routes() []route {
+	return []route{
+		{http.MethodGet, "/v1/openapi.json", app.OpenAPIHandler, false},
+		{http.MethodGet, "/v1/docs", app.DocsHandler, false},
+
+		{http.MethodPost, "/v1/groups", app.CreateGroupHandler, false},
+		{http.MethodPost, "/v1/import", app.ImportGroupHandler, false},
+		{http.MethodGet, "/v1/groups/:groupID", app.AuthenticateGroup(app.GetGroupHandler), false},
+		{http.MethodPatch, "/v1/groups/:groupID", app.AuthenticateGroup(app.RequireMutableGroup(app.UpdateGroupHandler)), false},
+		{http.MethodDelete, "/v1/groups/:groupID", app.AuthenticateGroup(app.DeleteGroupHandler), false},
+		{http.MethodPatch, "/v1/groups/:groupID/settings", app.AuthenticateGroup(app.RequireMutableGroup(app.UpdateGroupSettingsHandler)), false},
+		{http.MethodDelete, "/v1/groups/:groupID/deletion", app.AuthenticateGroup(app.CancelGroupDeletionHandler), false},
+		{http.MethodPost, "/v1/groups/:groupID/archive", app.AuthenticateGroup(app.RequireMutableGroup(app.ArchiveGroupHandler)), false},
+		{http.MethodDelete, "/v1/groups/:groupID/archive", app.AuthenticateGroup(app.UnarchiveGroupHandler), false},
+		{http.MethodPost, "/v1/groups/:groupID/merge", app.AuthenticateGroup(app.RequireMutableGroup(app.MergeGroupHandler)), false},
+		{http.MethodGet, "/v1/groups/:groupID/export", app.AuthenticateGroup(app.ExportGroupHandler), false},
+		{http.MethodGet, "/v1/groups/:groupID/report", app.AuthenticateGroupLink(app.ReportHandler), false},
+		{http.MethodPost, "/v1/groups/:groupID/import", app.AuthenticateGroup(app.RequireMutableGroup(app.ImportTransactionsHandler)), false},
+
+		{http.MethodPost, "/v1/groups/:groupID/transactions", app.AuthenticateGroup(app.RequireMutableGroup(app.CreateTransactionHandler)), false},
+		{http.MethodGet, "/v1/groups/:groupID/transactions", app.AuthenticateGroup(app.ListTransactionsHandler), false},
+		{http.MethodPatch, "/v1/groups/:groupID/transactions/:transactionID", app.AuthenticateGroup(app.RequireMutableGroup(app.UpdateTransactionHandler)), false},
+		{http.MethodDelete, "/v1/groups/:groupID/transactions/:transactionID", app.AuthenticateGroup(app.RequireMutableGroup(app.DeleteTransactionHandler)), false},
+
+		{http.MethodGet, "/v1/groups/:groupID/events", app.AuthenticateGroupLink(app.GroupEventsHandler), false},
+		{http.MethodGet, "/v1/groups/:groupID/socket", app.AuthenticateGroup(app.GroupSocketHandler), false},
+		{http.MethodGet, "/v1/groups/:groupID/stats", app.AuthenticateGroup(app.StatsHandler), true},
+		{http.MethodGet, "/v1/groups/:groupID/charts/spending", app.AuthenticateGroupLink(app.SpendingChartHandler), true},
+		{http.MethodGet, "/v1/groups/:groupID/charts/balances", app.AuthenticateGroupLink(app.BalanceChartHandler), false},
+		{http.MethodGet, "/v1/groups/:groupID/charts/categories", app.AuthenticateGroupLink(app.CategoryChartHandler), true},
+		{http.MethodPost, "/v1/groups/:groupID/import/statement", app.AuthenticateGroup(app.RequireMutableGroup(app.ImportStatementHandler)), true},
+
+		{http.MethodPost, "/v1/groups/:groupID/import/rules", app.AuthenticateGroup(app.RequireMutableGroup(app.CreateImportRuleHandler)), true},
+		{http.MethodGet, "/v1/groups/:groupID/import/rules", app.AuthenticateGroup(app.ListImportRulesHandler), true},
+		{http.MethodPatch, "/v1/groups/:groupID/import/rules/:ruleID", app.AuthenticateGroup(app.RequireMutableGroup(app.UpdateImportRuleHandler)), true},
+		{http.MethodDelete, "/v1/groups/:groupID/import/rules/:ruleID", app.AuthenticateGroup(app.RequireMutableGroup(app.DeleteImportRuleHandler)), true},
+
+		{http.MethodPost, "/v1/groups/:groupID/webhooks", app.AuthenticateGroup(app.RequireMutableGroup(app.CreateWebhookHandler)), true},
+		{http.MethodGet, "/v1/groups/:groupID/webhooks", app.AuthenticateGroup(app.ListWebhooksHandler), true},
+		{http.MethodPatch, "/v1/groups/:groupID/webhooks/:webhookID", app.AuthenticateGroup(app.RequireMutableGroup(app.UpdateWebhookHandler)), true},
+		{http.MethodDelete, "/v1/groups/:groupID/webhooks/:webhookID", app.AuthenticateGroup(app.RequireMutableGroup(app.DeleteWebhookHandler)), true},
+		{http.MethodGet, "/v1/groups/:groupID/webhooks/:webhookID/deliveries", app.AuthenticateGroup(app.ListWebhookDeliveriesHandler), true},
+
+		{http.MethodPost, "/v1/groups/:groupID/sync", app.AuthenticateGroup(app.RequireMutableGroup(app.SyncHandler)), false},
+
+		{http.MethodPost, "/v1/groups/:groupID/transactions/:transactionID/comments", app.AuthenticateGroup(app.RequireMutableGroup(app.CreateCommentHandler)), true},
+		{http.MethodGet, "/v1/groups/:groupID/transactions/:transactionID/comments", app.AuthenticateGroup(app.ListCommentsHandler), true},
+		{http.MethodPatch, "/v1/groups/:groupID/transactions/:transactionID/comments/:commentID", app.AuthenticateGroup(app.RequireMutableGroup(app.UpdateCommentHandler)), true},
+		{http.MethodDelete, "/v1/groups/:groupID/transactions/:transactionID/comments/:commentID", app.AuthenticateGroup(app.RequireMutableGroup(app.DeleteCommentHandler)), true},
+
+		{http.MethodPost, "/v1/groups/:groupID/transactions/:transactionID/attachments", app.AuthenticateGroup(app.RequireMutableGroup(app.UploadAttachmentHandler)), true},
+		{http.MethodGet, "/v1/groups/:groupID/transactions/:transactionID/attachments", app.AuthenticateGroup(app.ListAttachmentsHandler), true},
+		{http.MethodGet, "/v1/groups/:groupID/transactions/:transactionID/attachments/:attachmentID", app.AuthenticateGroup(app.GetAttachmentHandler), true},
+		{http.MethodGet, "/v1/groups/:groupID/transactions/:transactionID/attachments/:attachmentID/thumbnail", app.AuthenticateGroup(app.GetAttachmentThumbnailHandler), true},
+		{http.MethodDelete, "/v1/groups/:groupID/transactions/:transactionID/attachments/:attachmentID", app.AuthenticateGroup(app.RequireMutableGroup(app.DeleteAttachmentHandler)), true},
+	}
+}
+
 func (app *App) Routes() http.Handler {
 	router := httprouter.New()
 
 	router.NotFound = http.HandlerFunc(app.NotFoundResponse)
 	router.MethodNotAllowed = http.HandlerFunc(app.MethodNotAllowedResponse)
 
-	router.HandlerFunc(http.MethodPost, "/v1/groups", app.CreateGroupHandler)
-	router.HandlerFunc(http.MethodGet, "/v1/groups/:groupID", app.AuthenticateGroup(app.GetGroupHandler))
-	router.HandlerFunc(http.MethodPatch, "/v1/groups/:groupID", app.AuthenticateGroup(app.UpdateGroupHandler))
-	router.HandlerFunc(http.MethodDelete, "/v1/groups/:groupID", app.AuthenticateGroup(app.DeleteGroupHandler))
+	for _, route := range app.routes() {
+		handler := route.handler
+		if route.postgres {
+			handler = app.RequirePostgres(handler)
+		}
 
-	router.HandlerFunc(http.MethodPost, "/v1/groups/:groupID/transactions", app.AuthenticateGroup(app.CreateTransactionHandler))
-	router.HandlerFunc(http.MethodGet, "/v1/groups/:groupID/transactions", app.AuthenticateGroup(app.ListTransactionsHandler))
-	router.HandlerFunc(http.MethodPatch, "/v1/groups/:groupID/transactions/:transactionID", app.AuthenticateGroup(app.UpdateTransactionHandler))
-	router.HandlerFunc(http.MethodDelete, "/v1/groups/:groupID/transactions/:transactionID", app.AuthenticateGroup(app.DeleteTransactionHandler))
+		router.HandlerFunc(route.method, route.path, handler)
+	}
 
 	return router
 }
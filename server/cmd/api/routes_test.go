@@ -0,0 +1,262 @@
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/soumikc1729/splitty/server/docs"
+	"github.com/soumikc1729/splitty/server/internal/data"
+)
+
+var (
+	specMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+	pathParamRX = regexp.MustCompile(`\{([^}]+)\}`)
+)
+
+// specOperation is an operation of the OpenAPI document.
+type specOperation struct {
+	method   string
+	path     string
+	postgres bool
+	params   []specParam
+	op       map[string]interface{}
+}
+
+func (op specOperation) String() string {
+	return strings.ToUpper(op.method) + " " + op.path
+}
+
+type specParam struct {
+	name string
+	in   string
+}
+
+// specPath turns the :param segments of a router path into {param} as in the
+// document.
+func specPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func routeName(r route) string {
+	return r.method + " " + specPath(r.path)
+}
+
+func readSpec(t *testing.T) (map[string]interface{}, []specOperation) {
+	t.Helper()
+
+	var spec map[string]interface{}
+	if err := json.Unmarshal(docs.OpenAPI, &spec); err != nil {
+		t.Fatalf("the document is not valid JSON: %v", err)
+	}
+
+	paths, ok := spec["paths"].(map[string]interface{})
+	if !ok {
+		t.Fatal("the document has no paths")
+	}
+
+	var operations []specOperation
+
+	for path, v := range paths {
+		item, ok := v.(map[string]interface{})
+		if !ok {
+			t.Fatalf("path %s is not an object", path)
+		}
+
+		shared := readSpecParams(spec, item["parameters"])
+
+		for _, method := range specMethods {
+			op, ok := item[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			postgres, _ := op["x-requires-postgres"].(bool)
+
+			operations = append(operations, specOperation{
+				method:   method,
+				path:     path,
+				postgres: postgres,
+				params:   append(slices.Clone(shared), readSpecParams(spec, op["parameters"])...),
+				op:       op,
+			})
+		}
+	}
+
+	return spec, operations
+}
+
+// readSpecParams reads the names of parameters, resolving references to the
+// parameters of the components.
+func readSpecParams(spec map[string]interface{}, v interface{}) []specParam {
+	list, _ := v.([]interface{})
+
+	var params []specParam
+
+	for _, p := range list {
+		obj, _ := p.(map[string]interface{})
+
+		if ref, ok := obj["$ref"].(string); ok {
+			obj, _ = resolveRef(spec, ref).(map[string]interface{})
+		}
+
+		name, _ := obj["name"].(string)
+		in, _ := obj["in"].(string)
+		params = append(params, specParam{name: name, in: in})
+	}
+
+	return params
+}
+
+// resolveRef returns what a local reference such as
+// #/components/schemas/Group points to, or nil.
+func resolveRef(spec map[string]interface{}, ref string) interface{} {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil
+	}
+
+	var v interface{} = spec
+
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		v = obj[token]
+	}
+
+	return v
+}
+
+func TestRoutesMatchSpec(t *testing.T) {
+	spec, operations := readSpec(t)
+
+	app := &App{Data: &data.Data{Driver: data.Postgres}}
+	routes := app.routes()
+
+	findOperation := func(r route) (specOperation, bool) {
+		i := slices.IndexFunc(operations, func(op specOperation) bool {
+			return strings.EqualFold(op.method, r.method) && op.path == specPath(r.path)
+		})
+		if i < 0 {
+			return specOperation{}, false
+		}
+		return operations[i], true
+	}
+
+	t.Run("every route is documented", func(t *testing.T) {
+		for _, r := range routes {
+			if _, ok := findOperation(r); !ok {
+				t.Errorf("%s is not documented", routeName(r))
+			}
+		}
+	})
+
+	t.Run("every documented operation is routed", func(t *testing.T) {
+		router, ok := app.Routes().(*httprouter.Router)
+		if !ok {
+			t.Fatalf("Routes returns a %T rather than the router", app.Routes())
+		}
+
+		for _, op := range operations {
+			path := pathParamRX.ReplaceAllString(op.path, "1")
+			if handle, _, _ := router.Lookup(strings.ToUpper(op.method), path); handle == nil {
+				t.Errorf("%s is not routed", op)
+			}
+		}
+	})
+
+	t.Run("operations only served by Postgres are marked", func(t *testing.T) {
+		for _, r := range routes {
+			op, ok := findOperation(r)
+			switch {
+			case !ok:
+			case r.postgres && !op.postgres:
+				t.Errorf("%s needs Postgres but lacks x-requires-postgres", op)
+			case !r.postgres && op.postgres:
+				t.Errorf("%s does not need Postgres but has x-requires-postgres", op)
+			}
+
+			if responses, _ := op.op["responses"].(map[string]interface{}); ok && r.postgres && responses["501"] == nil {
+				t.Errorf("%s needs Postgres but does not document the 501 response", op)
+			}
+		}
+	})
+
+	t.Run("path parameters are declared", func(t *testing.T) {
+		for _, op := range operations {
+			var inPath []string
+			for _, m := range pathParamRX.FindAllStringSubmatch(op.path, -1) {
+				inPath = append(inPath, m[1])
+			}
+
+			var declared []string
+			for _, p := range op.params {
+				if p.in == "path" {
+					declared = append(declared, p.name)
+				}
+			}
+
+			for _, name := range inPath {
+				if !slices.Contains(declared, name) {
+					t.Errorf("%s does not declare path parameter %s", op, name)
+				}
+			}
+
+			for _, name := range declared {
+				if !slices.Contains(inPath, name) {
+					t.Errorf("%s declares path parameter %s, which is not in its path", op, name)
+				}
+			}
+		}
+	})
+
+	t.Run("operation IDs are unique", func(t *testing.T) {
+		seen := map[string]string{}
+
+		for _, op := range operations {
+			id, _ := op.op["operationId"].(string)
+
+			switch other, ok := seen[id]; {
+			case id == "":
+				t.Errorf("%s has no operationId", op)
+			case ok:
+				t.Errorf("%s and %s share operationId %s", other, op, id)
+			default:
+				seen[id] = op.String()
+			}
+		}
+	})
+
+	t.Run("references resolve", func(t *testing.T) {
+		var walk func(v interface{})
+		walk = func(v interface{}) {
+			switch v := v.(type) {
+			case map[string]interface{}:
+				if ref, ok := v["$ref"].(string); ok && resolveRef(spec, ref) == nil {
+					t.Errorf("%s does not resolve", ref)
+				}
+				for _, child := range v {
+					walk(child)
+				}
+			case []interface{}:
+				for _, child := range v {
+					walk(child)
+				}
+			}
+		}
+
+		walk(spec)
+	})
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/internal/events"
+	"github.com/soumikc1729/splitty/server/internal/socket"
+	"github.com/soumikc1729/splitty/server/internal/util"
+	"github.com/soumikc1729/splitty/server/internal/validator"
+	"github.com/soumikc1729/splitty/server/model"
+)
+
+const (
+	OpCreateTransaction = "create_transaction"
+	OpUpdateTransaction = "update_transaction"
+	OpDeleteTransaction = "delete_transaction"
+)
+
+// socketRequest is an operation sent by a client. ID is chosen by the client
+// and echoed in the reply. A non-zero Version makes updates and deletions
+// fail unless the transaction is still at that version.
+type socketRequest struct {
+	ID            string            `json:"id"`
+	Op            string            `json:"op"`
+	TransactionID int64             `json:"transaction_id"`
+	Version       int               `json:"version"`
+	Transaction   *transactionInput `json:"transaction"`
+}
+
+// socketMessage is sent to clients. It is either an event of the group, the
+// acknowledgement of an operation or the error it failed with.
+type socketMessage struct {
+	Type          string            `json:"type"`
+	ID            string            `json:"id,omitempty"`
+	Event         *events.Event     `json:"event,omitempty"`
+	TransactionID int64             `json:"transaction_id,omitempty"`
+	Transaction   *data.Transaction `json:"transaction,omitempty"`
+	Version       int               `json:"version,omitempty"`
+	Status        int               `json:"status,omitempty"`
+	Error         interface{}       `json:"error,omitempty"`
+}
+
+// GroupSocketHandler upgrades the request to a WebSocket on which the client
+// receives the events of the group and can change its transactions.
+func (app *App) GroupSocketHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+
+	sub := app.Events.Subscribe(group.ID)
+
+	conn, err := app.Sockets.Serve(w, r, func(c *socket.Conn, message []byte) {
+		app.handleSocketRequest(c, group.ID, group.Token, message)
+	})
+	if err != nil {
+		sub.Close()
+		app.Logger.Warn().Err(err).Int64("group-id", group.ID).Msg("failed to open socket")
+		return
+	}
+
+	app.Server.Background(func(_ context.Context) {
+		defer sub.Close()
+
+		for {
+			select {
+			case <-conn.Done():
+				return
+			case event, ok := <-sub.C:
+				if !ok {
+					conn.Close(socket.CloseTryAgainLater, "too slow to receive events")
+					return
+				}
+				conn.Send(socketMessage{Type: "event", Event: event})
+			}
+		}
+	})
+
+	app.Logger.Info().Int64("group-id", group.ID).Msg("opened socket")
+}
+
+func (app *App) handleSocketRequest(c *socket.Conn, groupID int64, token string, message []byte) {
+	var req socketRequest
+
+	if err := json.Unmarshal(message, &req); err != nil {
+		c.Send(socketMessage{Type: "error", Status: http.StatusBadRequest, Error: "message contains badly-formed JSON"})
+		return
+	}
+
+	// The group is read again for every operation, as it may have changed
+	// since the socket was opened.
+	group, err := app.Data.Groups.GetByIDAndToken(groupID, token, app.Config.Data.QueryTimeout)
+	if err != nil {
+		if errors.Is(err, data.ErrRecordNotFound) {
+			c.Close(socket.ClosePolicyViolation, "the group no longer exists")
+			return
+		}
+		app.sendSocketError(c, req.ID, err)
+		return
+	}
+
+	switch {
+	case group.PendingDeletion():
+		c.Send(socketMessage{Type: "error", ID: req.ID, Status: http.StatusConflict, Error: "the group is scheduled for deletion and is read-only, cancel the deletion to modify it"})
+		return
+	case group.Archived():
+		app.sendSocketError(c, req.ID, data.ErrGroupArchived)
+		return
+	}
+
+	var reply *socketMessage
+
+	switch req.Op {
+	case OpCreateTransaction:
+		reply = app.createSocketTransaction(c, group, &req)
+	case OpUpdateTransaction:
+		reply = app.updateSocketTransaction(c, group, &req)
+	case OpDeleteTransaction:
+		reply = app.deleteSocketTransaction(c, group, &req)
+	default:
+		c.Send(socketMessage{Type: "error", ID: req.ID, Status: http.StatusBadRequest, Error: "unknown op, use create_transaction, update_transaction or delete_transaction"})
+		return
+	}
+
+	if reply == nil {
+		return
+	}
+
+	reply.Type = "ack"
+	reply.ID = req.ID
+	c.Send(reply)
+
+	app.Logger.Info().Int64("group-id", group.ID).Str("op", req.Op).Int64("transaction-id", reply.TransactionID).Msg("handled socket request")
+}
+
+func (app *App) createSocketTransaction(c *socket.Conn, group *data.Group, req *socketRequest) *socketMessage {
+	transaction := app.validateSocketTransaction(c, group, req, model.Today(group.Settings.Location()))
+	if transaction == nil {
+		return nil
+	}
+
+	if err := app.Data.Transactions.Insert(transaction, app.Config.Data.QueryTimeout); err != nil {
+		app.sendSocketError(c, req.ID, err)
+		return nil
+	}
+
+	app.publish(events.TransactionCreated, group.ID, transaction)
+
+	return &socketMessage{TransactionID: transaction.ID, Transaction: transaction, Version: transaction.Version}
+}
+
+func (app *App) updateSocketTransaction(c *socket.Conn, group *data.Group, req *socketRequest) *socketMessage {
+	existing := app.readSocketTransaction(c, group, req)
+	if existing == nil {
+		return nil
+	}
+
+	transaction := app.validateSocketTransaction(c, group, req, existing.Date)
+	if transaction == nil {
+		return nil
+	}
+
+	transaction.ID = existing.ID
+	transaction.CreatedAt = existing.CreatedAt
+	transaction.Version = existing.Version
+
+	if err := app.Data.Transactions.Update(transaction, app.Config.Data.QueryTimeout); err != nil {
+		app.sendSocketError(c, req.ID, err)
+		return nil
+	}
+
+	app.publish(events.TransactionUpdated, group.ID, transaction)
+
+	return &socketMessage{TransactionID: transaction.ID, Transaction: transaction, Version: transaction.Version}
+}
+
+func (app *App) deleteSocketTransaction(c *socket.Conn, group *data.Group, req *socketRequest) *socketMessage {
+	existing := app.readSocketTransaction(c, group, req)
+	if existing == nil {
+		return nil
+	}
+
+	blobKeys, err := app.transactionBlobKeys(existing.ID, group.ID)
+	if err != nil {
+		app.sendSocketError(c, req.ID, err)
+		return nil
+	}
+
+	if err := app.Data.Transactions.Delete(existing.ID, group.ID, app.Config.Data.QueryTimeout); err != nil {
+		app.sendSocketError(c, req.ID, err)
+		return nil
+	}
+
+	app.deleteBlobs(blobKeys)
+
+	app.publish(events.TransactionDeleted, group.ID, util.Envelope{"id": existing.ID, "group_id": group.ID})
+
+	return &socketMessage{TransactionID: existing.ID}
+}
+
+// readSocketTransaction returns the transaction the request refers to, as
+// long as it is still at the version the client expects.
+func (app *App) readSocketTransaction(c *socket.Conn, group *data.Group, req *socketRequest) *data.Transaction {
+	transaction, err := app.Data.Transactions.Get(req.TransactionID, group.ID, app.Config.Data.QueryTimeout)
+	if err != nil {
+		app.sendSocketError(c, req.ID, err)
+		return nil
+	}
+
+	if req.Version != 0 && req.Version != transaction.Version {
+		app.sendSocketError(c, req.ID, data.ErrEditConflict)
+		return nil
+	}
+
+	return transaction
+}
+
+func (app *App) validateSocketTransaction(c *socket.Conn, group *data.Group, req *socketRequest, defaultDate data.Date) *data.Transaction {
+	v := validator.New()
+
+	v.Check(req.Transaction != nil, "transaction", "must be provided")
+
+	if !v.Valid() {
+		c.Send(socketMessage{Type: "error", ID: req.ID, Status: http.StatusUnprocessableEntity, Error: v.Errors})
+		return nil
+	}
+
+	transaction := req.Transaction.transaction(group, defaultDate)
+
+	if data.ValidateTransaction(v, transaction, group); !v.Valid() {
+		c.Send(socketMessage{Type: "error", ID: req.ID, Status: http.StatusUnprocessableEntity, Error: v.Errors})
+		return nil
+	}
+
+	return transaction
+}
+
+// sendSocketError replies with the error the same way DataErrorResponse does
+// for HTTP requests.
+func (app *App) sendSocketError(c *socket.Conn, id string, err error) {
+	msg := socketMessage{Type: "error", ID: id}
+
+	switch {
+	case errors.Is(err, data.ErrRecordNotFound):
+		msg.Status, msg.Error = http.StatusNotFound, "the requested resource could not be found"
+	case errors.Is(err, data.ErrEditConflict):
+		msg.Status, msg.Error = http.StatusConflict, "unable to update the record due to an edit conflict, please try again"
+	case errors.Is(err, data.ErrGroupArchived):
+		msg.Status, msg.Error = http.StatusConflict, "the group is archived and is read-only, unarchive it to modify it"
+	default:
+		app.Logger.Err(err).Msg("failed to handle socket request")
+		msg.Status, msg.Error = http.StatusInternalServerError, "the server encountered a problem and could not process your request"
+	}
+
+	c.Send(msg)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/internal/util"
+	"github.com/soumikc1729/splitty/server/internal/validator"
+	"github.com/soumikc1729/splitty/server/model"
+)
+
+func (app *App) StatsHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+
+	qs := r.URL.Query()
+	today := model.Today(group.Settings.Location())
+
+	v := validator.New()
+
+	bucket := data.Bucket(app.readString(qs, "bucket", string(data.Month)))
+	from, to := app.readDateRange(qs, data.Date{Time: today.AddDate(-1, 0, 1)}, today, v)
+	top := app.readInt(qs, "top", 5, v)
+
+	v.Check(validator.In(string(bucket), data.Buckets...), "bucket", "must be one of day, week or month")
+	v.Check(validator.Between(top, 0, 50), "top", "must be between 0 and 50")
+	v.Check(to.Sub(from.Time) <= 10*366*24*time.Hour, "to", "must be at most ten years after from")
+	v.Check(bucket != data.Day || to.Sub(from.Time) <= 366*24*time.Hour, "to", "must be at most one year after from when bucketing by day")
+
+	if !v.Valid() {
+		app.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	series, err := app.Data.Stats.SpendingSeries(group.ID, bucket, from, to, app.Config.Data.QueryTimeout)
+	if err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	topTransactions, err := app.Data.Stats.TopTransactions(group.ID, from, to, top, app.Config.Data.QueryTimeout)
+	if err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	type memberStats struct {
+		Paid     float64 `json:"paid"`
+		Consumed float64 `json:"consumed"`
+		Net      float64 `json:"net"`
+	}
+
+	var total float64
+	members := make(map[string]*memberStats)
+	for _, user := range group.Users {
+		members[user] = &memberStats{}
+	}
+
+	for _, b := range series {
+		total += b.Total
+		for user, spending := range b.Members {
+			if _, ok := members[user]; !ok {
+				members[user] = &memberStats{}
+			}
+			members[user].Paid += spending.Paid
+			members[user].Consumed += spending.Consumed
+			members[user].Net += spending.Paid - spending.Consumed
+		}
+	}
+
+	stats := util.Envelope{
+		"from":             from,
+		"to":               to.AddDays(-1),
+		"bucket":           bucket,
+		"total":            total,
+		"series":           series,
+		"members":          members,
+		"top_transactions": topTransactions,
+	}
+
+	if err := util.WriteJSON(w, http.StatusOK, util.Envelope{"stats": stats}, nil); err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	app.Logger.Info().Int64("group-id", group.ID).Str("bucket", string(bucket)).Msg("retrieved stats")
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/internal/events"
+	"github.com/soumikc1729/splitty/server/internal/util"
+	"github.com/soumikc1729/splitty/server/internal/validator"
+	"github.com/soumikc1729/splitty/server/model"
+)
+
+const (
+	SyncCreate = "create"
+	SyncUpdate = "update"
+	SyncDelete = "delete"
+
+	// maxSyncOperations is how many queued operations a client may push at
+	// once.
+	maxSyncOperations = 500
+	// syncRetries is how often a merge is redone when the transaction changes
+	// while it is being merged.
+	syncRetries = 3
+)
+
+// syncOperation is a change a client queued while offline. Transactions are
+// referred to by their ID or by the client ID they were created with.
+type syncOperation struct {
+	Op          string            `json:"op"`
+	ID          int64             `json:"id"`
+	ClientID    string            `json:"client_id"`
+	BaseVersion int               `json:"base_version"`
+	Base        *transactionInput `json:"base"`
+	Transaction *transactionInput `json:"transaction"`
+	ModifiedAt  time.Time         `json:"modified_at"`
+}
+
+// syncResult tells what became of an operation. Status is one of created,
+// updated, merged, unchanged, deleted or failed. Transaction is the
+// transaction as it is stored after the operation.
+type syncResult struct {
+	Index       int               `json:"index"`
+	Status      string            `json:"status"`
+	Transaction *data.Transaction `json:"transaction,omitempty"`
+	Conflicts   []string          `json:"conflicts,omitempty"`
+	Error       interface{}       `json:"error,omitempty"`
+}
+
+// SyncHandler applies the operations a client queued while offline, in order,
+// and returns the events of the group since last_event_id so that the client
+// catches up with the changes of others.
+//
+// Updates made against an older version than the current one are merged with
+// data.MergeTransaction, using base as the common ancestor. Such updates fail
+// unless they come with base or modified_at. Deletions always
+// apply. Updates of transactions that no longer exist are dropped and
+// reported as deleted. Creations are idempotent by client ID, so an operation
+// that is pushed twice is only applied once.
+func (app *App) SyncHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+
+	var input struct {
+		Operations  []syncOperation `json:"operations"`
+		LastEventID int64           `json:"last_event_id"`
+	}
+
+	if err := util.ReadJSON(r, &input); err != nil {
+		app.BadRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+
+	v.Check(len(input.Operations) <= maxSyncOperations, "operations", fmt.Sprintf("must not contain more than %d values", maxSyncOperations))
+	v.Check(input.LastEventID >= 0, "last_event_id", "must not be negative")
+
+	if !v.Valid() {
+		app.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	results := make([]syncResult, 0, len(input.Operations))
+
+	for i := range input.Operations {
+		result := app.applySyncOperation(group, &input.Operations[i])
+		result.Index = i
+		results = append(results, result)
+	}
+
+	changes, err := app.Data.Events.GetAllAfterID(input.LastEventID, group.ID, replayBatchSize, app.Config.Data.QueryTimeout)
+	if err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	lastEventID := input.LastEventID
+	if len(changes) > 0 {
+		lastEventID = changes[len(changes)-1].ID
+	}
+
+	env := util.Envelope{
+		"results":       results,
+		"events":        changes,
+		"last_event_id": lastEventID,
+		"has_more":      len(changes) == replayBatchSize,
+	}
+
+	if err := util.WriteJSON(w, http.StatusOK, env, nil); err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	app.Logger.Info().Int64("group-id", group.ID).Int("operations", len(input.Operations)).Int64("last-event-id", lastEventID).Msg("synced group")
+}
+
+func (app *App) applySyncOperation(group *data.Group, op *syncOperation) syncResult {
+	op.ClientID = strings.ToLower(op.ClientID)
+
+	v := validator.New()
+
+	v.Check(validator.In(op.Op, SyncCreate, SyncUpdate, SyncDelete), "op", "must be one of create, update or delete")
+
+	if op.Op == SyncCreate || op.ClientID != "" {
+		data.ValidateClientID(v, "client_id", op.ClientID)
+	}
+	if op.Op != SyncCreate {
+		v.Check(op.ID > 0 || op.ClientID != "", "id", "must be provided unless client_id is")
+	}
+	if op.Op != SyncDelete {
+		v.Check(op.Transaction != nil, "transaction", "must be provided")
+	}
+
+	if !v.Valid() {
+		return syncResult{Status: "failed", Error: v.Errors}
+	}
+
+	switch op.Op {
+	case SyncCreate:
+		return app.syncCreate(group, op)
+	case SyncUpdate:
+		return app.syncUpdate(group, op)
+	default:
+		return app.syncDelete(group, op)
+	}
+}
+
+func (app *App) syncCreate(group *data.Group, op *syncOperation) syncResult {
+	existing, err := app.Data.Transactions.GetByClientID(op.ClientID, group.ID, app.Config.Data.QueryTimeout)
+	switch {
+	case err == nil:
+		return syncResult{Status: "unchanged", Transaction: existing}
+	case !errors.Is(err, data.ErrRecordNotFound):
+		return app.syncError(err)
+	}
+
+	transaction := op.Transaction.transaction(group, model.Today(group.Settings.Location()))
+	transaction.ClientID = op.ClientID
+
+	v := validator.New()
+
+	if data.ValidateTransaction(v, transaction, group); !v.Valid() {
+		return syncResult{Status: "failed", Error: v.Errors}
+	}
+
+	if err := app.Data.Transactions.Insert(transaction, app.Config.Data.QueryTimeout); err != nil {
+		return app.syncError(err)
+	}
+
+	app.publish(events.TransactionCreated, group.ID, transaction)
+
+	return syncResult{Status: "created", Transaction: transaction}
+}
+
+func (app *App) syncUpdate(group *data.Group, op *syncOperation) syncResult {
+	for range syncRetries {
+		current, err := app.readSyncTransaction(group, op)
+		if err != nil {
+			if errors.Is(err, data.ErrRecordNotFound) {
+				return syncResult{Status: "deleted"}
+			}
+			return app.syncError(err)
+		}
+
+		mine := op.Transaction.transaction(group, current.Date)
+
+		var base *data.Transaction
+		if op.Base != nil {
+			base = op.Base.transaction(group, current.Date)
+		}
+
+		status := "updated"
+		merged, conflicts := mine, []string(nil)
+
+		if op.BaseVersion != current.Version {
+			// Without a base or the time of the change, every field the client
+			// set differently would be a conflict the server wins, dropping the
+			// change silently.
+			if base == nil && op.ModifiedAt.IsZero() {
+				return syncResult{Status: "failed", Error: map[string]string{"base": "must be provided, or modified_at must be, when base_version is not the current version"}}
+			}
+
+			status = "merged"
+			merged, conflicts = data.MergeTransaction(base, current, mine, op.ModifiedAt)
+		}
+
+		if merged.SameContent(current) {
+			return syncResult{Status: "unchanged", Transaction: current, Conflicts: conflicts}
+		}
+
+		merged.ID = current.ID
+		merged.GroupID = group.ID
+		merged.ClientID = current.ClientID
+		merged.CreatedAt = current.CreatedAt
+		merged.Version = current.Version
+
+		v := validator.New()
+
+		if data.ValidateTransaction(v, merged, group); !v.Valid() {
+			return syncResult{Status: "failed", Conflicts: conflicts, Error: v.Errors}
+		}
+
+		err = app.Data.Transactions.Update(merged, app.Config.Data.QueryTimeout)
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			continue
+		case err != nil:
+			return app.syncError(err)
+		}
+
+		app.publish(events.TransactionUpdated, group.ID, merged)
+
+		return syncResult{Status: status, Transaction: merged, Conflicts: conflicts}
+	}
+
+	return app.syncError(data.ErrEditConflict)
+}
+
+func (app *App) syncDelete(group *data.Group, op *syncOperation) syncResult {
+	current, err := app.readSyncTransaction(group, op)
+	if err != nil {
+		if errors.Is(err, data.ErrRecordNotFound) {
+			return syncResult{Status: "deleted"}
+		}
+		return app.syncError(err)
+	}
+
+	blobKeys, err := app.transactionBlobKeys(current.ID, group.ID)
+	if err != nil {
+		return app.syncError(err)
+	}
+
+	err = app.Data.Transactions.Delete(current.ID, group.ID, app.Config.Data.QueryTimeout)
+	switch {
+	case errors.Is(err, data.ErrRecordNotFound):
+		return syncResult{Status: "deleted"}
+	case err != nil:
+		return app.syncError(err)
+	}
+
+	app.deleteBlobs(blobKeys)
+
+	app.publish(events.TransactionDeleted, group.ID, util.Envelope{"id": current.ID, "group_id": group.ID})
+
+	return syncResult{Status: "deleted"}
+}
+
+func (app *App) readSyncTransaction(group *data.Group, op *syncOperation) (*data.Transaction, error) {
+	if op.ID > 0 {
+		return app.Data.Transactions.Get(op.ID, group.ID, app.Config.Data.QueryTimeout)
+	}
+
+	return app.Data.Transactions.GetByClientID(op.ClientID, group.ID, app.Config.Data.QueryTimeout)
+}
+
+func (app *App) syncError(err error) syncResult {
+	switch {
+	case errors.Is(err, data.ErrEditConflict):
+		return syncResult{Status: "failed", Error: "the transaction kept changing while it was being merged, please try again"}
+	case errors.Is(err, data.ErrDuplicateClientID):
+		return syncResult{Status: "failed", Error: "the client_id is already used by another transaction"}
+	case errors.Is(err, data.ErrGroupArchived):
+		return syncResult{Status: "failed", Error: "the group is archived and is read-only, unarchive it to modify it"}
+	default:
+		app.Logger.Err(err).Msg("failed to apply sync operation")
+		return syncResult{Status: "failed", Error: "the server encountered a problem and could not process your request"}
+	}
+}
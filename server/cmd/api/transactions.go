@@ -6,18 +6,22 @@ import (
 	"strconv"
 
 	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/internal/events"
 	"github.com/soumikc1729/splitty/server/internal/util"
 	"github.com/soumikc1729/splitty/server/internal/validator"
+	"github.com/soumikc1729/splitty/server/model"
 )
 
 func (app *App) CreateTransactionHandler(w http.ResponseWriter, r *http.Request) {
 	group := app.ContextGetGroup(r)
-	if transaction := app.validateTransactionInput(w, r, group); transaction != nil {
+	if transaction := app.validateTransactionInput(w, r, group, model.Today(group.Settings.Location())); transaction != nil {
 		if err := app.Data.Transactions.Insert(transaction, app.Config.Data.QueryTimeout); err != nil {
-			app.ServerErrorResponse(w, r, err)
+			app.DataErrorResponse(w, r, err)
 			return
 		}
 
+		app.publish(events.TransactionCreated, group.ID, transaction)
+
 		header := make(http.Header)
 		header.Set("Location", fmt.Sprintf("/v1/transactions/%d", transaction.ID))
 
@@ -45,7 +49,26 @@ func (app *App) ListTransactionsHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err = util.WriteJSON(w, http.StatusOK, util.Envelope{"transactions": transactions}, nil)
+	commentCounts := map[int64]int{}
+	if app.Data.Postgres() {
+		commentCounts, err = app.Data.Comments.CountAfterID(after, group.ID, app.Config.Data.QueryTimeout)
+		if err != nil {
+			app.ServerErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	type listedTransaction struct {
+		data.Transaction
+		CommentCount int `json:"comment_count"`
+	}
+
+	listed := make([]listedTransaction, 0, len(*transactions))
+	for _, transaction := range *transactions {
+		listed = append(listed, listedTransaction{Transaction: transaction, CommentCount: commentCounts[transaction.ID]})
+	}
+
+	err = util.WriteJSON(w, http.StatusOK, util.Envelope{"transactions": listed}, nil)
 	if err != nil {
 		app.ServerErrorResponse(w, r, err)
 		return
@@ -56,20 +79,23 @@ func (app *App) ListTransactionsHandler(w http.ResponseWriter, r *http.Request)
 
 func (app *App) UpdateTransactionHandler(w http.ResponseWriter, r *http.Request) {
 	group := app.ContextGetGroup(r)
-	if updatedTransaction := app.validateTransactionInput(w, r, group); updatedTransaction != nil {
-		id, err := util.ReadParam("transactionID", r)
-		if err != nil {
-			app.BadRequestResponse(w, r, err)
-			return
-		}
 
-		transaction, err := app.Data.Transactions.Get(id, group.ID, app.Config.Data.QueryTimeout)
-		if err != nil {
-			app.DataErrorResponse(w, r, err)
-			return
-		}
+	id, err := util.ReadParam("transactionID", r)
+	if err != nil {
+		app.BadRequestResponse(w, r, err)
+		return
+	}
 
+	transaction, err := app.Data.Transactions.Get(id, group.ID, app.Config.Data.QueryTimeout)
+	if err != nil {
+		app.DataErrorResponse(w, r, err)
+		return
+	}
+
+	if updatedTransaction := app.validateTransactionInput(w, r, group, transaction.Date); updatedTransaction != nil {
 		updatedTransaction.ID = id
+		updatedTransaction.ClientID = transaction.ClientID
+		updatedTransaction.CreatedAt = transaction.CreatedAt
 		updatedTransaction.Version = transaction.Version
 
 		if err := app.Data.Transactions.Update(updatedTransaction, app.Config.Data.QueryTimeout); err != nil {
@@ -77,6 +103,8 @@ func (app *App) UpdateTransactionHandler(w http.ResponseWriter, r *http.Request)
 			return
 		}
 
+		app.publish(events.TransactionUpdated, group.ID, updatedTransaction)
+
 		if err := util.WriteJSON(w, http.StatusOK, util.Envelope{"transaction": updatedTransaction}, nil); err != nil {
 			app.ServerErrorResponse(w, r, err)
 			return
@@ -95,11 +123,21 @@ func (app *App) DeleteTransactionHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	blobKeys, err := app.transactionBlobKeys(id, group.ID)
+	if err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
 	if err = app.Data.Transactions.Delete(id, group.ID, app.Config.Data.QueryTimeout); err != nil {
 		app.DataErrorResponse(w, r, err)
 		return
 	}
 
+	app.deleteBlobs(blobKeys)
+
+	app.publish(events.TransactionDeleted, group.ID, util.Envelope{"id": id, "group_id": group.ID})
+
 	if err := util.WriteJSON(w, http.StatusOK, util.Envelope{"message": "transaction successfully deleted"}, nil); err != nil {
 		app.ServerErrorResponse(w, r, err)
 		return
@@ -108,21 +146,20 @@ func (app *App) DeleteTransactionHandler(w http.ResponseWriter, r *http.Request)
 	app.Logger.Info().Int64("group-id", group.ID).Int64("transaction-id", id).Msg("deleted transaction")
 }
 
-func (app *App) validateTransactionInput(w http.ResponseWriter, r *http.Request, group *data.Group) *data.Transaction {
-	var input struct {
-		Title    string `json:"title"`
-		Payments []struct {
-			Amount float64 `json:"amount"`
-			Payer  string  `json:"payer"`
-		} `json:"payments"`
-	}
-
-	err := util.ReadJSON(r, &input)
-	if err != nil {
-		app.BadRequestResponse(w, r, err)
-		return nil
-	}
+// transactionInput holds a transaction sent by a client.
+type transactionInput struct {
+	Title    string     `json:"title"`
+	Category string     `json:"category"`
+	Date     *data.Date `json:"date"`
+	Payments []struct {
+		Amount float64 `json:"amount"`
+		Payer  string  `json:"payer"`
+	} `json:"payments"`
+}
 
+// transaction builds the transaction of the group described by the input,
+// splitting it equally if the group does so by default.
+func (input *transactionInput) transaction(group *data.Group, defaultDate data.Date) *data.Transaction {
 	payments := []data.Payment{}
 	for _, p := range input.Payments {
 		payments = append(payments, data.Payment{
@@ -130,12 +167,36 @@ func (app *App) validateTransactionInput(w http.ResponseWriter, r *http.Request,
 			Payer:  p.Payer,
 		})
 	}
+	if group.Settings.DefaultSplit == model.SplitEqual && onlyPaid(payments) {
+		payments = group.Settings.SplitEqually(payments, group.Users)
+	}
+
 	transaction := &data.Transaction{
 		Title:    input.Title,
+		Category: input.Category,
 		Payments: payments,
+		Date:     defaultDate,
 		GroupID:  group.ID,
 	}
 
+	if input.Date != nil {
+		transaction.Date = *input.Date
+	}
+
+	return transaction
+}
+
+func (app *App) validateTransactionInput(w http.ResponseWriter, r *http.Request, group *data.Group, defaultDate data.Date) *data.Transaction {
+	var input transactionInput
+
+	err := util.ReadJSON(r, &input)
+	if err != nil {
+		app.BadRequestResponse(w, r, err)
+		return nil
+	}
+
+	transaction := input.transaction(group, defaultDate)
+
 	v := validator.New()
 
 	if data.ValidateTransaction(v, transaction, group); !v.Valid() {
@@ -145,3 +206,19 @@ func (app *App) validateTransactionInput(w http.ResponseWriter, r *http.Request,
 
 	return transaction
 }
+
+// onlyPaid reports whether payments only lists what was paid, leaving out who
+// consumed it.
+func onlyPaid(payments []data.Payment) bool {
+	if len(payments) == 0 {
+		return false
+	}
+
+	for _, p := range payments {
+		if p.Amount <= 0 {
+			return false
+		}
+	}
+
+	return true
+}
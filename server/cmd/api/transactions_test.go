@@ -0,0 +1,324 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+)
+
+// racingTransactions updates a transaction between it being read and written
+// by a handler, as a concurrent request would.
+type racingTransactions struct {
+	data.TransactionStore
+}
+
+func (s racingTransactions) Get(id int64, groupID int64, timeout time.Duration) (*data.Transaction, error) {
+	transaction, err := s.TransactionStore.Get(id, groupID, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	other, err := s.TransactionStore.Get(id, groupID, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	other.Title = "Changed elsewhere"
+	if err := s.TransactionStore.Update(other, timeout); err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// dinner is a transaction of 30 paid by alice and shared with bob.
+func dinner() map[string]interface{} {
+	return map[string]interface{}{
+		"title":    "Dinner",
+		"category": "Food",
+		"date":     "2024-05-01",
+		"payments": []map[string]interface{}{
+			{"payer": "alice", "amount": 15},
+			{"payer": "bob", "amount": -15},
+		},
+	}
+}
+
+func transactionPath(group *data.Group, id int64) string {
+	return groupPath(group, fmt.Sprintf("/transactions/%d", id))
+}
+
+func (app *App) createTestTransaction(t *testing.T, group *data.Group, body interface{}) *data.Transaction {
+	t.Helper()
+
+	res := app.do(t, http.MethodPost, groupPath(group, "/transactions"), group.Token, body)
+	wantStatus(t, res, http.StatusCreated)
+
+	var transaction data.Transaction
+	res.decode(t, "transaction", &transaction)
+	return &transaction
+}
+
+func (app *App) listTestTransactions(t *testing.T, group *data.Group) []data.Transaction {
+	t.Helper()
+
+	res := app.do(t, http.MethodGet, groupPath(group, "/transactions"), group.Token, nil)
+	wantStatus(t, res, http.StatusOK)
+
+	var transactions []data.Transaction
+	res.decode(t, "transactions", &transactions)
+	return transactions
+}
+
+func TestTransactions(t *testing.T) {
+	app := newTestApp(t)
+	group := app.createTestGroup(t)
+
+	transaction := app.createTestTransaction(t, group, dinner())
+
+	t.Run("created", func(t *testing.T) {
+		if transaction.ID == 0 || transaction.GroupID != group.ID || transaction.Version != 1 {
+			t.Errorf("got id %d, group %d and version %d", transaction.ID, transaction.GroupID, transaction.Version)
+		}
+		if transaction.Title != "Dinner" || transaction.Date.String() != "2024-05-01" {
+			t.Errorf("got title %q and date %s", transaction.Title, transaction.Date)
+		}
+	})
+
+	t.Run("listed", func(t *testing.T) {
+		transactions := app.listTestTransactions(t, group)
+		if len(transactions) != 1 || transactions[0].ID != transaction.ID {
+			t.Fatalf("got %+v, want the created transaction", transactions)
+		}
+	})
+
+	t.Run("split equally", func(t *testing.T) {
+		res := app.do(t, http.MethodPatch, groupPath(group, "/settings"), group.Token, map[string]interface{}{"default_split": "equal"})
+		wantStatus(t, res, http.StatusOK)
+
+		paid := app.createTestTransaction(t, group, map[string]interface{}{
+			"title":    "Taxi",
+			"payments": []map[string]interface{}{{"payer": "carol", "amount": 30}},
+		})
+
+		amounts := map[string]float64{}
+		for _, p := range paid.Payments {
+			amounts[p.Payer] += p.Amount
+		}
+		if want := map[string]float64{"alice": -10, "bob": -10, "carol": 20}; !maps.Equal(amounts, want) {
+			t.Errorf("got %v, want %v", amounts, want)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		body := dinner()
+		body["payments"] = []map[string]interface{}{
+			{"payer": "alice", "amount": 30},
+			{"payer": "bob", "amount": -20},
+		}
+
+		res := app.do(t, http.MethodPost, groupPath(group, "/transactions"), group.Token, body)
+		wantStatus(t, res, http.StatusUnprocessableEntity)
+
+		var errs map[string]string
+		res.decode(t, "error", &errs)
+		if errs["payments"] == "" {
+			t.Errorf("got errors %v, want one for payments", errs)
+		}
+	})
+
+	t.Run("updated", func(t *testing.T) {
+		body := dinner()
+		body["title"] = "Late dinner"
+
+		res := app.do(t, http.MethodPatch, transactionPath(group, transaction.ID), group.Token, body)
+		wantStatus(t, res, http.StatusOK)
+
+		var updated data.Transaction
+		res.decode(t, "transaction", &updated)
+		if updated.Title != "Late dinner" || updated.Version != transaction.Version+1 {
+			t.Errorf("got title %q and version %d", updated.Title, updated.Version)
+		}
+		if !updated.CreatedAt.Equal(transaction.CreatedAt) {
+			t.Errorf("got created_at %v, want %v", updated.CreatedAt, transaction.CreatedAt)
+		}
+	})
+
+	t.Run("deleted", func(t *testing.T) {
+		res := app.do(t, http.MethodDelete, transactionPath(group, transaction.ID), group.Token, nil)
+		wantStatus(t, res, http.StatusOK)
+
+		ids := []int64{}
+		for _, listed := range app.listTestTransactions(t, group) {
+			ids = append(ids, listed.ID)
+		}
+		if slices.Contains(ids, transaction.ID) {
+			t.Errorf("got %v, want the deleted transaction gone", ids)
+		}
+	})
+
+	for _, tt := range []struct {
+		name   string
+		method string
+		body   interface{}
+	}{
+		{"update deleted", http.MethodPatch, dinner()},
+		{"delete deleted", http.MethodDelete, nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			res := app.do(t, tt.method, transactionPath(group, transaction.ID), group.Token, tt.body)
+			wantStatus(t, res, http.StatusBadRequest)
+
+			if got, want := res.errorMessage(t), "the requested resource could not be found"; got != want {
+				t.Errorf("got error %q, want %q", got, want)
+			}
+		})
+	}
+
+	t.Run("other group", func(t *testing.T) {
+		other := app.createTestGroup(t)
+		theirs := app.createTestTransaction(t, other, dinner())
+
+		res := app.do(t, http.MethodDelete, transactionPath(group, theirs.ID), group.Token, nil)
+		wantStatus(t, res, http.StatusBadRequest)
+
+		if got := app.listTestTransactions(t, other); len(got) != 1 {
+			t.Errorf("got %d transactions in the other group, want 1", len(got))
+		}
+	})
+}
+
+func TestUpdateTransactionConflict(t *testing.T) {
+	app := newTestApp(t)
+	group := app.createTestGroup(t)
+	transaction := app.createTestTransaction(t, group, dinner())
+
+	app.Data.Transactions = racingTransactions{app.Data.Transactions}
+
+	res := app.do(t, http.MethodPatch, transactionPath(group, transaction.ID), group.Token, dinner())
+	wantStatus(t, res, http.StatusConflict)
+
+	if got, want := res.errorMessage(t), "unable to update the record due to an edit conflict, please try again"; got != want {
+		t.Errorf("got error %q, want %q", got, want)
+	}
+}
+
+func TestSyncStaleVersion(t *testing.T) {
+	app := newTestApp(t)
+	group := app.createTestGroup(t)
+	transaction := app.createTestTransaction(t, group, dinner())
+
+	// Someone else renames the transaction while the client is offline.
+	renamed := dinner()
+	renamed["title"] = "Team dinner"
+	res := app.do(t, http.MethodPatch, transactionPath(group, transaction.ID), group.Token, renamed)
+	wantStatus(t, res, http.StatusOK)
+
+	recategorized := dinner()
+	recategorized["category"] = "Restaurants"
+
+	type result struct {
+		Status      string           `json:"status"`
+		Transaction data.Transaction `json:"transaction"`
+		Error       interface{}      `json:"error"`
+	}
+
+	sync := func(t *testing.T, op map[string]interface{}) result {
+		t.Helper()
+
+		res := app.do(t, http.MethodPost, groupPath(group, "/sync"), group.Token, map[string]interface{}{
+			"operations": []interface{}{op},
+		})
+		wantStatus(t, res, http.StatusOK)
+
+		var results []result
+		res.decode(t, "results", &results)
+		if len(results) != 1 {
+			t.Fatalf("got %d results, want 1", len(results))
+		}
+		return results[0]
+	}
+
+	t.Run("without base", func(t *testing.T) {
+		got := sync(t, map[string]interface{}{
+			"op":           "update",
+			"id":           transaction.ID,
+			"base_version": transaction.Version,
+			"transaction":  recategorized,
+		})
+		if got.Status != "failed" || got.Error == nil {
+			t.Errorf("got status %q and error %v, want a failure", got.Status, got.Error)
+		}
+	})
+
+	t.Run("with base", func(t *testing.T) {
+		got := sync(t, map[string]interface{}{
+			"op":           "update",
+			"id":           transaction.ID,
+			"base_version": transaction.Version,
+			"base":         dinner(),
+			"transaction":  recategorized,
+		})
+		if got.Status != "merged" {
+			t.Fatalf("got status %q and error %v, want merged", got.Status, got.Error)
+		}
+		if got.Transaction.Title != "Team dinner" || got.Transaction.Category != "Restaurants" {
+			t.Errorf("got title %q and category %q, want both changes kept", got.Transaction.Title, got.Transaction.Category)
+		}
+	})
+}
+
+func TestUpdateKeepsClientID(t *testing.T) {
+	app := newTestApp(t)
+	group := app.createTestGroup(t)
+
+	const clientID = "0b6f1c2e-8d3a-4f5b-9c7d-000000000001"
+
+	res := app.do(t, http.MethodPost, groupPath(group, "/sync"), group.Token, map[string]interface{}{
+		"operations": []interface{}{
+			map[string]interface{}{"op": "create", "client_id": clientID, "transaction": dinner()},
+		},
+	})
+	wantStatus(t, res, http.StatusOK)
+
+	var results []struct {
+		Transaction data.Transaction `json:"transaction"`
+	}
+	res.decode(t, "results", &results)
+	if len(results) != 1 || results[0].Transaction.ID == 0 {
+		t.Fatalf("got %+v, want the created transaction", results)
+	}
+
+	sub := app.Events.Subscribe(group.ID)
+	defer sub.Close()
+
+	body := dinner()
+	body["title"] = "Late dinner"
+
+	res = app.do(t, http.MethodPatch, transactionPath(group, results[0].Transaction.ID), group.Token, body)
+	wantStatus(t, res, http.StatusOK)
+
+	var updated data.Transaction
+	res.decode(t, "transaction", &updated)
+	if updated.ClientID != clientID {
+		t.Errorf("got client_id %q in the response, want %q", updated.ClientID, clientID)
+	}
+
+	event := <-sub.C
+
+	js, err := json.Marshal(event.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var published data.Transaction
+	if err := json.Unmarshal(js, &published); err != nil || published.ClientID != clientID {
+		t.Errorf("got event data %s, want the transaction with client_id %q", js, clientID)
+	}
+}
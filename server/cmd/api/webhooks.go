@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/internal/util"
+	"github.com/soumikc1729/splitty/server/internal/validator"
+)
+
+func (app *App) CreateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+
+	var input struct {
+		URL    string   `json:"url"`
+		Secret string   `json:"secret"`
+		Events []string `json:"events"`
+	}
+
+	if err := util.ReadJSON(r, &input); err != nil {
+		app.BadRequestResponse(w, r, err)
+		return
+	}
+
+	webhook := &data.Webhook{GroupID: group.ID, URL: input.URL, Secret: input.Secret, Events: input.Events}
+	if webhook.Secret == "" {
+		webhook.Secret = data.GenerateWebhookSecret()
+	}
+
+	v := validator.New()
+
+	if data.ValidateWebhook(v, webhook); !v.Valid() {
+		app.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if err := app.Data.Webhooks.Insert(webhook, app.Config.Data.QueryTimeout); err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	header := make(http.Header)
+	header.Set("Location", fmt.Sprintf("/v1/groups/%d/webhooks/%d", group.ID, webhook.ID))
+
+	// The secret is only ever returned here, so that subscribers can verify
+	// signatures without it being readable later on.
+	if err := util.WriteJSON(w, http.StatusCreated, util.Envelope{"webhook": webhook}, header); err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	app.Logger.Info().Int64("group-id", group.ID).Int64("webhook-id", webhook.ID).Msg("created webhook")
+}
+
+func (app *App) ListWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+
+	webhooks, err := app.Data.Webhooks.GetAll(group.ID, app.Config.Data.QueryTimeout)
+	if err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if err := util.WriteJSON(w, http.StatusOK, util.Envelope{"webhooks": webhooks}, nil); err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	app.Logger.Info().Int64("group-id", group.ID).Msg("retrieved webhooks")
+}
+
+func (app *App) UpdateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+
+	id, err := util.ReadParam("webhookID", r)
+	if err != nil {
+		app.BadRequestResponse(w, r, err)
+		return
+	}
+
+	webhook, err := app.Data.Webhooks.Get(id, group.ID, app.Config.Data.QueryTimeout)
+	if err != nil {
+		app.DataErrorResponse(w, r, err)
+		return
+	}
+
+	var input struct {
+		URL    *string  `json:"url"`
+		Secret *string  `json:"secret"`
+		Events []string `json:"events"`
+	}
+
+	if err := util.ReadJSON(r, &input); err != nil {
+		app.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if input.URL != nil {
+		webhook.URL = *input.URL
+	}
+	if input.Secret != nil {
+		webhook.Secret = *input.Secret
+	}
+	if input.Events != nil {
+		webhook.Events = input.Events
+	}
+
+	v := validator.New()
+
+	if data.ValidateWebhook(v, webhook); !v.Valid() {
+		app.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if err := app.Data.Webhooks.Update(webhook, app.Config.Data.QueryTimeout); err != nil {
+		app.DataErrorResponse(w, r, err)
+		return
+	}
+
+	webhook.Secret = ""
+
+	if err := util.WriteJSON(w, http.StatusOK, util.Envelope{"webhook": webhook}, nil); err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	app.Logger.Info().Int64("group-id", group.ID).Int64("webhook-id", id).Msg("updated webhook")
+}
+
+func (app *App) DeleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+
+	id, err := util.ReadParam("webhookID", r)
+	if err != nil {
+		app.BadRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.Data.Webhooks.Delete(id, group.ID, app.Config.Data.QueryTimeout); err != nil {
+		app.DataErrorResponse(w, r, err)
+		return
+	}
+
+	if err := util.WriteJSON(w, http.StatusOK, util.Envelope{"message": "webhook successfully deleted"}, nil); err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	app.Logger.Info().Int64("group-id", group.ID).Int64("webhook-id", id).Msg("deleted webhook")
+}
+
+func (app *App) ListWebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	group := app.ContextGetGroup(r)
+
+	id, err := util.ReadParam("webhookID", r)
+	if err != nil {
+		app.BadRequestResponse(w, r, err)
+		return
+	}
+
+	qs := r.URL.Query()
+
+	v := validator.New()
+
+	status := app.readString(qs, "status", "")
+	limit := app.readInt(qs, "limit", 50, v)
+
+	v.Check(status == "" || validator.In(status, data.DeliveryStatuses...), "status", "must be one of pending, succeeded or failed")
+	v.Check(validator.Between(limit, 1, 200), "limit", "must be between 1 and 200")
+
+	if !v.Valid() {
+		app.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	if _, err := app.Data.Webhooks.Get(id, group.ID, app.Config.Data.QueryTimeout); err != nil {
+		app.DataErrorResponse(w, r, err)
+		return
+	}
+
+	deliveries, err := app.Data.Deliveries.GetAllForWebhook(id, group.ID, status, limit, app.Config.Data.QueryTimeout)
+	if err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	if err := util.WriteJSON(w, http.StatusOK, util.Envelope{"deliveries": deliveries}, nil); err != nil {
+		app.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	app.Logger.Info().Int64("group-id", group.ID).Int64("webhook-id", id).Msg("retrieved webhook deliveries")
+}
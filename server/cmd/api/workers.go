@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/internal/events"
+	"github.com/soumikc1729/splitty/server/internal/webhook"
+)
+
+// PurgeGroups periodically deletes the groups whose deletion grace period has
+// passed and the events older than their retention, until ctx is cancelled.
+func (app *App) PurgeGroups(ctx context.Context) {
+	ticker := time.NewTicker(app.Config.Data.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			app.purgeDueGroups()
+			app.pruneEvents()
+		}
+	}
+}
+
+func (app *App) purgeDueGroups() {
+	now := time.Now()
+
+	ids, err := app.Data.Groups.GetAllDueForDeletion(now, app.Config.Data.QueryTimeout)
+	if err != nil {
+		app.Logger.Err(err).Msg("failed to list groups due for deletion")
+		return
+	}
+
+	for _, id := range ids {
+		blobKeys, err := app.groupBlobKeys(id)
+		if err != nil {
+			app.Logger.Err(err).Int64("id", id).Msg("failed to list attachments of group")
+			continue
+		}
+
+		err = app.Data.Groups.Delete(id, now, app.Config.Data.StreamTimeout)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrRecordNotFound):
+				continue
+			default:
+				app.Logger.Err(err).Int64("id", id).Msg("failed to delete group")
+				continue
+			}
+		}
+
+		app.deleteBlobs(blobKeys)
+
+		app.Logger.Info().Int64("id", id).Msg("deleted group")
+	}
+}
+
+func (app *App) pruneEvents() {
+	deleted, err := app.Data.Events.DeleteBefore(time.Now().Add(-app.Config.Events.Retention), app.Config.Data.QueryTimeout)
+	if err != nil {
+		app.Logger.Err(err).Msg("failed to prune events")
+		return
+	}
+
+	if deleted > 0 {
+		app.Logger.Info().Int64("events", deleted).Msg("pruned events")
+	}
+}
+
+// DeliverWebhooks delivers queued webhook payloads whenever some are due,
+// and periodically deletes the finished deliveries older than their
+// retention, until ctx is cancelled. Every delivery runs as a background task
+// of its own, so shutdown waits for the deliveries in flight.
+func (app *App) DeliverWebhooks(ctx context.Context) {
+	ticker := time.NewTicker(app.Config.Webhook.PollInterval)
+	defer ticker.Stop()
+
+	purge := time.NewTicker(app.Config.Data.PurgeInterval)
+	defer purge.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-app.webhookWakeup:
+		case <-purge.C:
+			app.pruneDeliveries()
+			continue
+		}
+
+		app.deliverDueWebhooks()
+	}
+}
+
+func (app *App) pruneDeliveries() {
+	deleted, err := app.Data.Deliveries.DeleteFinishedBefore(time.Now().Add(-app.Config.Webhook.Retention), app.Config.Data.QueryTimeout)
+	if err != nil {
+		app.Logger.Err(err).Msg("failed to prune webhook deliveries")
+		return
+	}
+
+	if deleted > 0 {
+		app.Logger.Info().Int64("deliveries", deleted).Msg("pruned webhook deliveries")
+	}
+}
+
+func (app *App) deliverDueWebhooks() {
+	// Claimed deliveries are retried by anyone once the lease has passed, so it
+	// has to outlast the attempt itself.
+	lease := 2*app.Config.Webhook.Timeout + app.Config.Data.QueryTimeout
+
+	deliveries, err := app.Data.Deliveries.Claim(app.Config.Webhook.BatchSize, lease, app.Config.Data.QueryTimeout)
+	if err != nil {
+		app.Logger.Err(err).Msg("failed to claim webhook deliveries")
+		return
+	}
+
+	for _, delivery := range deliveries {
+		app.Server.Background(func(_ context.Context) {
+			app.deliverWebhook(delivery)
+		})
+	}
+}
+
+func (app *App) deliverWebhook(delivery *data.Delivery) {
+	ctx, cancel := context.WithTimeout(context.Background(), app.Config.Webhook.Timeout)
+	defer cancel()
+
+	status, err := webhook.Send(ctx, app.webhookClient, &webhook.Request{
+		URL:        delivery.URL,
+		Secret:     delivery.Secret,
+		Event:      delivery.EventType,
+		DeliveryID: delivery.ID,
+		Body:       delivery.Payload,
+	})
+
+	delivery.Attempts++
+	delivery.ResponseStatus = status
+
+	switch {
+	case err == nil:
+		delivery.Status = data.DeliverySucceeded
+		delivery.LastError = ""
+	case delivery.Attempts >= app.Config.Webhook.MaxAttempts:
+		delivery.Status = data.DeliveryFailed
+		delivery.LastError = webhook.Describe(err)
+	default:
+		delivery.NextAttemptAt = time.Now().Add(app.Config.Webhook.Backoff(delivery.Attempts))
+		delivery.LastError = webhook.Describe(err)
+	}
+
+	if err != nil {
+		app.Logger.Err(err).Int64("webhook-id", delivery.WebhookID).Int64("delivery-id", delivery.ID).Msg("failed to deliver webhook")
+	}
+
+	if err := app.Data.Deliveries.Record(delivery, app.Config.Data.QueryTimeout); err != nil {
+		app.Logger.Err(err).Int64("delivery-id", delivery.ID).Msg("failed to record webhook delivery")
+		return
+	}
+
+	app.Logger.Info().Int64("webhook-id", delivery.WebhookID).Int64("delivery-id", delivery.ID).Int("attempts", delivery.Attempts).Str("status", string(delivery.Status)).Msg("delivered webhook")
+}
+
+// ForwardEvents passes the events stored by any instance to the local
+// subscribers of their group, until ctx is cancelled. Events that were missed
+// while the connection to the database was lost are read back afterwards,
+// from a window behind the last one forwarded, as an event can be stored
+// after events with higher IDs.
+func (app *App) ForwardEvents(ctx context.Context) {
+	lastID, err := app.Data.Events.LatestID(app.Config.Data.QueryTimeout)
+	if err != nil {
+		app.Logger.Err(err).Msg("failed to read latest event")
+	}
+
+	cursor := events.NewCursor(lastID)
+
+	listener, err := data.NewEventListener(&app.Config.Data, app.Config.Events.ListenerMinReconnect, app.Config.Events.ListenerMaxReconnect, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			app.Logger.Err(err).Msg("event listener failed")
+		}
+	})
+	if err != nil {
+		app.Logger.Err(err).Msg("failed to listen for events")
+		return
+	}
+	defer listener.Close()
+
+	ping := time.NewTicker(app.Config.Events.ListenerPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n := <-listener.Notify:
+			if n == nil {
+				app.forwardMissedEvents(cursor)
+				continue
+			}
+
+			notification, err := data.ParseEventNotification(n)
+			if err != nil {
+				app.Logger.Err(err).Str("payload", n.Extra).Msg("failed to parse event notification")
+				continue
+			}
+
+			// Events read back after a reconnect may be notified as well.
+			if !cursor.Add(notification.ID) {
+				continue
+			}
+
+			if !app.Events.HasSubscribers(notification.GroupID) {
+				continue
+			}
+
+			event, err := app.Data.Events.Get(notification.ID, app.Config.Data.QueryTimeout)
+			if err != nil {
+				app.Logger.Err(err).Int64("event-id", notification.ID).Msg("failed to read event")
+				continue
+			}
+
+			app.Events.Publish(event)
+		case <-ping.C:
+			// Pinging detects a connection that was silently lost.
+			if err := listener.Ping(); err != nil {
+				app.Logger.Err(err).Msg("failed to ping event listener")
+			}
+		}
+	}
+}
+
+func (app *App) forwardMissedEvents(cursor *events.Cursor) {
+	afterID := cursor.Resume()
+	recovered := 0
+
+	for {
+		missed, err := app.Data.Events.GetAllGroupsAfterID(afterID, replayBatchSize, app.Config.Data.QueryTimeout)
+		if err != nil {
+			app.Logger.Err(err).Msg("failed to read missed events")
+			return
+		}
+
+		for _, event := range missed {
+			afterID = event.ID
+
+			if cursor.Add(event.ID) {
+				app.Events.Publish(event)
+				recovered++
+			}
+		}
+
+		if len(missed) < replayBatchSize {
+			app.Logger.Info().Int64("last-event-id", afterID).Int("recovered", recovered).Msg("recovered missed events")
+			return
+		}
+	}
+}
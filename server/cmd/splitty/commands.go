@@ -0,0 +1,377 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/soumikc1729/splitty/server/client"
+	"github.com/soumikc1729/splitty/server/internal/balance"
+	"github.com/soumikc1729/splitty/server/model"
+)
+
+func newFlags(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet("splitty "+name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: splitty %s %s\n", name, commands[name].usage)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+func runCreate(cli *CLI, args []string) error {
+	var name, users, alias string
+
+	flags := newFlags("create")
+	flags.StringVar(&name, "name", "", "name of the group")
+	flags.StringVar(&users, "users", "", "comma-separated users of the group")
+	flags.StringVar(&alias, "alias", "", "alias to remember the group by (default derived from the name)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if alias == "" {
+		alias = aliasOf(name)
+	}
+
+	if _, ok := cli.Config.Groups[alias]; ok {
+		return fmt.Errorf("there already is a group called %q, choose another alias", alias)
+	}
+
+	group, err := cli.Client.CreateGroup(context.Background(), client.GroupInput{Name: name, Users: splitList(users)})
+	if err != nil {
+		return err
+	}
+
+	if err := cli.remember(alias, group); err != nil {
+		return err
+	}
+
+	if cli.JSON {
+		return cli.printJSON(map[string]interface{}{"group": group})
+	}
+
+	fmt.Fprintf(cli.Out, "created group %s with id %d and token %s, remembered as %s\n", group.Name, group.ID, group.Token, alias)
+	return nil
+}
+
+func runJoin(cli *CLI, args []string) error {
+	var id int64
+	var token, alias string
+
+	flags := newFlags("join")
+	flags.Int64Var(&id, "id", 0, "id of the group")
+	flags.StringVar(&token, "token", "", "token of the group")
+	flags.StringVar(&alias, "alias", "", "alias to remember the group by (default derived from the name)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	group, err := cli.getGroup(GroupConfig{ID: id, Token: token})
+	if err != nil {
+		return err
+	}
+
+	if alias == "" {
+		alias = aliasOf(group.Name)
+	}
+
+	if err := cli.remember(alias, group); err != nil {
+		return err
+	}
+
+	if cli.JSON {
+		return cli.printJSON(map[string]interface{}{"group": group})
+	}
+
+	fmt.Fprintf(cli.Out, "joined group %s, remembered as %s\n", group.Name, alias)
+	return nil
+}
+
+func runGroups(cli *CLI, args []string) error {
+	if err := newFlags("groups").Parse(args); err != nil {
+		return err
+	}
+
+	if cli.JSON {
+		return cli.printJSON(cli.Config)
+	}
+
+	tw := cli.table("ALIAS", "ID", "DEFAULT")
+	for _, alias := range cli.Config.aliases() {
+		isDefault := ""
+		if alias == cli.Config.Default {
+			isDefault = "*"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", alias, cli.Config.Groups[alias].ID, isDefault)
+	}
+
+	return tw.Flush()
+}
+
+func runUse(cli *CLI, args []string) error {
+	flags := newFlags("use")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("use needs exactly one alias")
+	}
+
+	alias, _, err := cli.Config.group(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	cli.Config.Default = alias
+	return cli.Config.save()
+}
+
+func runAdd(cli *CLI, args []string) error {
+	var title, date, category string
+
+	flags := newFlags("add")
+	flags.StringVar(&title, "title", "", "title of the expense")
+	flags.StringVar(&date, "date", "", "date of the expense as YYYY-MM-DD (default today in the group's time zone)")
+	flags.StringVar(&category, "category", "", "category of the expense")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	group, groupConfig, err := cli.loadGroup()
+	if err != nil {
+		return err
+	}
+
+	payments, err := parseSplit(flags.Args(), group)
+	if err != nil {
+		return err
+	}
+
+	input := client.TransactionInput{Title: title, Category: category, Payments: payments}
+	if date != "" {
+		d, err := model.ParseDate(date)
+		if err != nil {
+			return fmt.Errorf("invalid date %s, use YYYY-MM-DD", date)
+		}
+		input.Date = &d
+	}
+
+	transaction, err := cli.Client.Group(groupConfig.ID, groupConfig.Token).CreateTransaction(context.Background(), input)
+	if err != nil {
+		return err
+	}
+
+	if cli.JSON {
+		return cli.printJSON(map[string]interface{}{"transaction": transaction})
+	}
+
+	return cli.printTransactions(group, []client.ListedTransaction{{Transaction: *transaction}})
+}
+
+func runTransactions(cli *CLI, args []string) error {
+	var after int64
+
+	flags := newFlags("transactions")
+	flags.Int64Var(&after, "after", 0, "only list transactions with a greater id")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	group, groupConfig, err := cli.loadGroup()
+	if err != nil {
+		return err
+	}
+
+	transactions, err := cli.getTransactions(groupConfig, after)
+	if err != nil {
+		return err
+	}
+
+	if cli.JSON {
+		return cli.printJSON(map[string]interface{}{"transactions": transactions})
+	}
+
+	return cli.printTransactions(group, transactions)
+}
+
+func runBalances(cli *CLI, args []string) error {
+	if err := newFlags("balances").Parse(args); err != nil {
+		return err
+	}
+
+	group, groupConfig, err := cli.loadGroup()
+	if err != nil {
+		return err
+	}
+
+	transactions, err := cli.getTransactions(groupConfig, 0)
+	if err != nil {
+		return err
+	}
+
+	summary := balance.New(group.Users)
+	for i := range transactions {
+		summary.Add(&transactions[i].Transaction)
+	}
+
+	settlements := summary.Debts()
+	if group.Settings.SimplifyDebts {
+		settlements = summary.Settlements()
+	}
+
+	if cli.JSON {
+		type userBalance struct {
+			User     string  `json:"user"`
+			Paid     float64 `json:"paid"`
+			Consumed float64 `json:"consumed"`
+			Net      float64 `json:"net"`
+		}
+
+		balances := []userBalance{}
+		for _, user := range group.Users {
+			balances = append(balances, userBalance{user, summary.Paid[user], summary.Consumed[user], summary.Net(user)})
+		}
+
+		return cli.printJSON(map[string]interface{}{"balances": balances, "settlements": settlements})
+	}
+
+	format := func(amount float64) string {
+		return strconv.FormatFloat(amount, 'f', group.Settings.Precision, 64)
+	}
+
+	tw := cli.table("USER", "PAID", "CONSUMED", "NET")
+	for _, user := range group.Users {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", user, format(summary.Paid[user]), format(summary.Consumed[user]), format(summary.Net(user)))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(cli.Out)
+
+	if len(settlements) == 0 {
+		fmt.Fprintln(cli.Out, "everyone is settled up")
+		return nil
+	}
+
+	tw = cli.table("FROM", "TO", "AMOUNT")
+	for _, s := range settlements {
+		fmt.Fprintf(tw, "%s\t%s\t%s %s\n", s.From, s.To, format(s.Amount), group.Settings.Currency)
+	}
+
+	return tw.Flush()
+}
+
+func runExport(cli *CLI, args []string) error {
+	var format, output string
+
+	flags := newFlags("export")
+	flags.StringVar(&format, "format", "csv", "csv, xlsx, ledger, hledger or beancount")
+	flags.StringVar(&output, "o", "", "file to write to (default standard output)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	_, groupConfig, err := cli.Config.group(cli.Group)
+	if err != nil {
+		return err
+	}
+
+	w := cli.Out
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	opts := client.ExportOptions{Format: client.ExportFormat(format)}
+	return cli.Client.Group(groupConfig.ID, groupConfig.Token).Export(context.Background(), opts, w)
+}
+
+func (cli *CLI) getGroup(groupConfig GroupConfig) (*client.Group, error) {
+	return cli.Client.Group(groupConfig.ID, groupConfig.Token).Get(context.Background())
+}
+
+// loadGroup fetches the group the CLI works on.
+func (cli *CLI) loadGroup() (*client.Group, GroupConfig, error) {
+	_, groupConfig, err := cli.Config.group(cli.Group)
+	if err != nil {
+		return nil, GroupConfig{}, err
+	}
+
+	group, err := cli.getGroup(groupConfig)
+	if err != nil {
+		return nil, GroupConfig{}, err
+	}
+
+	return group, groupConfig, nil
+}
+
+func (cli *CLI) getTransactions(groupConfig GroupConfig, after int64) ([]client.ListedTransaction, error) {
+	return cli.Client.Group(groupConfig.ID, groupConfig.Token).Transactions(context.Background(), after)
+}
+
+// remember stores the group in the config file, along with the server it
+// lives on if no server was configured yet.
+func (cli *CLI) remember(alias string, group *client.Group) error {
+	if cli.Config.Server == "" {
+		cli.Config.Server = cli.Client.BaseURL
+	}
+
+	return cli.Config.addGroup(alias, GroupConfig{ID: group.ID, Token: group.Token})
+}
+
+func (cli *CLI) printTransactions(group *client.Group, transactions []client.ListedTransaction) error {
+	tw := cli.table("ID", "DATE", "TITLE", "CATEGORY", "PAYMENTS")
+
+	for _, t := range transactions {
+		payments := make([]string, 0, len(t.Payments))
+		for _, p := range t.Payments {
+			payments = append(payments, fmt.Sprintf("%s %+.*f", p.Payer, group.Settings.Precision, p.Amount))
+		}
+
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", t.ID, t.Date, t.Title, t.Category, strings.Join(payments, ", "))
+	}
+
+	return tw.Flush()
+}
+
+func (cli *CLI) table(columns ...string) *tabwriter.Writer {
+	tw := tabwriter.NewWriter(cli.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(columns, "\t"))
+	return tw
+}
+
+func (cli *CLI) printJSON(v interface{}) error {
+	enc := json.NewEncoder(cli.Out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// aliasOf derives an alias from the name of a group, such as trip-goa from
+// Trip Goa.
+func aliasOf(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), "-"))
+}
+
+func splitList(list string) []string {
+	items := []string{}
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Config is the local config file of the CLI. It remembers the groups the
+// user created or joined under short aliases, as the token of a group is all
+// it takes to access it.
+type Config struct {
+	Server  string                 `json:"server,omitempty"`
+	Default string                 `json:"default,omitempty"`
+	Groups  map[string]GroupConfig `json:"groups"`
+
+	path string
+}
+
+type GroupConfig struct {
+	ID    int64  `json:"id"`
+	Token string `json:"token"`
+}
+
+// defaultConfigPath is splitty/config.json in the user's config directory,
+// unless SPLITTY_CONFIG points elsewhere.
+func defaultConfigPath() string {
+	if path := os.Getenv("SPLITTY_CONFIG"); path != "" {
+		return path
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ".splitty.json"
+	}
+
+	return filepath.Join(dir, "splitty", "config.json")
+}
+
+// loadConfig reads the config file, which does not need to exist yet.
+func loadConfig(path string) (*Config, error) {
+	cfg := &Config{Groups: map[string]GroupConfig{}, path: path}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cfg, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(content, cfg); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	if cfg.Groups == nil {
+		cfg.Groups = map[string]GroupConfig{}
+	}
+
+	return cfg, nil
+}
+
+// save writes the config file readable only by the user, as it holds the
+// tokens of the groups.
+func (cfg *Config) save() error {
+	content, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cfg.path), 0o700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(cfg.path, append(content, '\n'), 0o600)
+}
+
+// group returns the group with the alias, or the default group if alias is
+// empty.
+func (cfg *Config) group(alias string) (string, GroupConfig, error) {
+	if alias == "" {
+		alias = cfg.Default
+	}
+
+	if alias == "" {
+		return "", GroupConfig{}, errors.New("no group given and no default group set, pass -group or run splitty use")
+	}
+
+	group, ok := cfg.Groups[alias]
+	if !ok {
+		return "", GroupConfig{}, fmt.Errorf("unknown group %q, run splitty groups to list the known ones", alias)
+	}
+
+	return alias, group, nil
+}
+
+// addGroup remembers the group under the alias and makes it the default if
+// there is none yet.
+func (cfg *Config) addGroup(alias string, group GroupConfig) error {
+	if _, ok := cfg.Groups[alias]; ok {
+		return fmt.Errorf("there already is a group called %q, choose another alias", alias)
+	}
+
+	cfg.Groups[alias] = group
+	if cfg.Default == "" {
+		cfg.Default = alias
+	}
+
+	return cfg.save()
+}
+
+func (cfg *Config) aliases() []string {
+	aliases := make([]string, 0, len(cfg.Groups))
+	for alias := range cfg.Groups {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return aliases
+}
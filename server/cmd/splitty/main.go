@@ -0,0 +1,124 @@
+// Command splitty is a command-line client for the Splitty API. It keeps the
+// IDs and tokens of the groups it created or joined in a local config file.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/soumikc1729/splitty/server/client"
+)
+
+type command struct {
+	usage string
+	help  string
+	run   func(cli *CLI, args []string) error
+}
+
+// commands is set up by init, as the commands refer back to it for their
+// usage.
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"create":       {"-name NAME -users A,B[,...] [-alias ALIAS]", "create a group and remember it", runCreate},
+		"join":         {"-id ID -token TOKEN [-alias ALIAS]", "remember a group someone else created", runJoin},
+		"groups":       {"", "list the remembered groups", runGroups},
+		"use":          {"ALIAS", "make a remembered group the default", runUse},
+		"add":          {"-title TITLE [-date DATE] [-category CATEGORY] SPLIT...", "add an expense, see below for the split shorthand", runAdd},
+		"transactions": {"[-after ID]", "list the transactions of the group", runTransactions},
+		"balances":     {"", "show what everyone paid and owes and how to settle up", runBalances},
+		"export":       {"[-format FORMAT] [-o FILE]", "export the transactions as csv, xlsx, ledger, hledger or beancount", runExport},
+	}
+}
+
+var commandOrder = []string{"create", "join", "groups", "use", "add", "transactions", "balances", "export"}
+
+// CLI holds what every command needs.
+type CLI struct {
+	Config *Config
+	Client *client.Client
+	// Group is the alias of the group to work on, the default one if empty.
+	Group string
+	JSON  bool
+	Out   io.Writer
+}
+
+func main() {
+	var configPath, server, group string
+	var asJSON bool
+
+	flags := flag.NewFlagSet("splitty", flag.ExitOnError)
+	flags.StringVar(&configPath, "config", defaultConfigPath(), "path of the config file")
+	flags.StringVar(&server, "server", "", "URL of the Splitty server (default from the config file or http://localhost:4000)")
+	flags.StringVar(&group, "group", "", "alias of the group to work on (default from the config file)")
+	flags.BoolVar(&asJSON, "json", false, "print JSON instead of tables")
+	flags.Usage = func() { usage(flags) }
+	flags.Parse(os.Args[1:])
+
+	if flags.NArg() == 0 {
+		usage(flags)
+		os.Exit(2)
+	}
+
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		fatal(err)
+	}
+
+	if server == "" {
+		server = cfg.Server
+	}
+	if server == "" {
+		server = "http://localhost:4000"
+	}
+
+	cmd, ok := commands[flags.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", flags.Arg(0))
+		usage(flags)
+		os.Exit(2)
+	}
+
+	cli := &CLI{Config: cfg, Client: client.New(server), Group: group, JSON: asJSON, Out: os.Stdout}
+
+	if err := cmd.run(cli, flags.Args()[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		fatal(err)
+	}
+}
+
+func usage(flags *flag.FlagSet) {
+	w := flags.Output()
+
+	fmt.Fprintln(w, "Usage: splitty [flags] COMMAND [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, name := range commandOrder {
+		cmd := commands[name]
+		fmt.Fprintf(w, "  %s %s\n      %s\n", name, cmd.usage, cmd.help)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Split shorthand:")
+	fmt.Fprintln(w, "  NAME+AMOUNT  NAME paid AMOUNT")
+	fmt.Fprintln(w, "  NAME-AMOUNT  NAME consumed exactly AMOUNT")
+	fmt.Fprintln(w, "  NAME         NAME shares the rest equally with the other bare names,")
+	fmt.Fprintln(w, "               everyone shares it if no bare names are given")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "  splitty add -title Dinner Soumik+60")
+	fmt.Fprintln(w, "  splitty add -title Taxi Paulomi+30 Paulomi Rohan")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Flags:")
+	flags.PrintDefaults()
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "splitty: %s\n", strings.TrimSpace(err.Error()))
+	os.Exit(1)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/internal/validator"
+)
+
+var (
+	amountRX = regexp.MustCompile(`^(.+?)([+-])([0-9]+(?:\.[0-9]+)?)$`)
+)
+
+// parseSplit turns the split shorthand into the payments of a transaction:
+//
+//	NAME+AMOUNT  NAME paid AMOUNT
+//	NAME-AMOUNT  NAME consumed exactly AMOUNT
+//	NAME         NAME shares what is left equally with the other bare names
+//
+// What was paid and not consumed exactly is shared equally among every user
+// of the group if no bare names are given.
+func parseSplit(args []string, group *data.Group) ([]data.Payment, error) {
+	settings := group.Settings
+
+	units := map[string]int64{}
+	var order, sharers []string
+	var left int64
+
+	add := func(user string, amount int64) {
+		if _, ok := units[user]; !ok {
+			order = append(order, user)
+		}
+		units[user] += amount
+	}
+
+	for _, arg := range args {
+		// Names such as team-2 look like an amount, so the argument is
+		// matched against the users as a whole first.
+		user, sign, amount := arg, "", ""
+		if m := amountRX.FindStringSubmatch(arg); m != nil && !validator.In(arg, group.Users...) {
+			user, sign, amount = m[1], m[2], m[3]
+		}
+
+		if !validator.In(user, group.Users...) {
+			return nil, fmt.Errorf("%s is not a user of the group", user)
+		}
+
+		if sign == "" {
+			if validator.In(user, sharers...) {
+				return nil, fmt.Errorf("%s shares more than once", user)
+			}
+			sharers = append(sharers, user)
+			continue
+		}
+
+		value, err := strconv.ParseFloat(amount, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid amount in %s", arg)
+		}
+
+		if !settings.HasPrecision(value) {
+			return nil, fmt.Errorf("%s has more than %d decimals", arg, settings.Precision)
+		}
+
+		if sign == "+" {
+			add(user, settings.Units(value))
+			left += settings.Units(value)
+		} else {
+			add(user, -settings.Units(value))
+			left -= settings.Units(value)
+		}
+	}
+
+	if left < 0 {
+		return nil, fmt.Errorf("more was consumed than paid")
+	}
+
+	if len(sharers) == 0 {
+		sharers = group.Users
+	}
+
+	if left > 0 {
+		n := int64(len(sharers))
+		share, remainder := left/n, left%n
+
+		for i, user := range sharers {
+			amount := share
+			if int64(i) < remainder {
+				amount++
+			}
+			add(user, -amount)
+		}
+	}
+
+	payments := []data.Payment{}
+	for _, user := range order {
+		if units[user] != 0 {
+			payments = append(payments, data.Payment{Payer: user, Amount: settings.Amount(units[user])})
+		}
+	}
+
+	if len(payments) == 0 {
+		return nil, fmt.Errorf("nothing was paid, give at least one NAME+AMOUNT")
+	}
+
+	return payments, nil
+}
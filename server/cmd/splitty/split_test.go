@@ -0,0 +1,88 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/model"
+)
+
+func TestParseSplit(t *testing.T) {
+	users := []string{"alice", "bob", "carol"}
+
+	tests := []struct {
+		name    string
+		users   []string
+		args    []string
+		want    []data.Payment
+		wantErr bool
+	}{
+		{
+			name: "shared by everyone",
+			args: []string{"alice+30"},
+			want: []data.Payment{{Payer: "alice", Amount: 20}, {Payer: "bob", Amount: -10}, {Payer: "carol", Amount: -10}},
+		},
+		{
+			name: "shared by some",
+			args: []string{"alice+30", "bob", "carol"},
+			want: []data.Payment{{Payer: "alice", Amount: 30}, {Payer: "bob", Amount: -15}, {Payer: "carol", Amount: -15}},
+		},
+		{
+			name: "remainder to the first",
+			args: []string{"alice+10"},
+			want: []data.Payment{{Payer: "alice", Amount: 6.66}, {Payer: "bob", Amount: -3.33}, {Payer: "carol", Amount: -3.33}},
+		},
+		{
+			name: "consumed exactly",
+			args: []string{"alice+30", "bob-10"},
+			want: []data.Payment{{Payer: "alice", Amount: 23.33}, {Payer: "bob", Amount: -16.67}, {Payer: "carol", Amount: -6.66}},
+		},
+		{
+			name: "paid twice",
+			args: []string{"alice+10", "alice+5", "bob"},
+			want: []data.Payment{{Payer: "alice", Amount: 15}, {Payer: "bob", Amount: -15}},
+		},
+		{
+			name:  "name that looks like an amount",
+			users: []string{"alice", "team-2"},
+			args:  []string{"alice+30", "team-2"},
+			want:  []data.Payment{{Payer: "alice", Amount: 30}, {Payer: "team-2", Amount: -30}},
+		},
+		{
+			name:  "amount after such a name",
+			users: []string{"alice", "team-2"},
+			args:  []string{"team-2+30", "alice"},
+			want:  []data.Payment{{Payer: "team-2", Amount: 30}, {Payer: "alice", Amount: -30}},
+		},
+		{name: "consumed more than paid", args: []string{"alice+10", "bob-20"}, wantErr: true},
+		{name: "shares twice", args: []string{"alice+10", "bob", "bob"}, wantErr: true},
+		{name: "unknown user", args: []string{"dave+10"}, wantErr: true},
+		{name: "too many decimals", args: []string{"alice+1.234"}, wantErr: true},
+		{name: "nothing paid", args: []string{"bob"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := &data.Group{Users: users, Settings: model.DefaultSettings()}
+			if tt.users != nil {
+				group.Users = tt.users
+			}
+
+			got, err := parseSplit(tt.args, group)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
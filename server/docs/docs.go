@@ -0,0 +1,18 @@
+// Package docs embeds the OpenAPI document of the API and a page to browse
+// it, so that the server can serve them itself.
+package docs
+
+import (
+	_ "embed"
+)
+
+// OpenAPI is the OpenAPI 3.1 document of the API.
+//
+//go:embed openapi.json
+var OpenAPI []byte
+
+// Page renders the OpenAPI document, which it fetches from openapi.json next
+// to it, in the browser.
+//
+//go:embed index.html
+var Page []byte
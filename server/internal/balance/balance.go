@@ -0,0 +1,137 @@
+package balance
+
+import (
+	"math"
+	"sort"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/model"
+)
+
+const (
+	epsilon = 1e-9
+)
+
+type Summary struct {
+	Users    []string
+	Count    int
+	Total    float64
+	Paid     map[string]float64
+	Consumed map[string]float64
+
+	// owed[debtor][creditor] is what debtor owes creditor across all
+	// transactions, before any simplification.
+	owed map[string]map[string]float64
+}
+
+func New(users []string) *Summary {
+	return &Summary{
+		Users:    users,
+		Paid:     make(map[string]float64),
+		Consumed: make(map[string]float64),
+		owed:     make(map[string]map[string]float64),
+	}
+}
+
+func (s *Summary) Add(transaction *data.Transaction) {
+	s.Count++
+
+	var paid float64
+	for _, p := range transaction.Payments {
+		if p.Amount > 0 {
+			s.Paid[p.Payer] += p.Amount
+			s.Total += p.Amount
+			paid += p.Amount
+		} else {
+			s.Consumed[p.Payer] -= p.Amount
+		}
+	}
+
+	// Every consumer owes each payer a share of what they consumed in
+	// proportion to what that payer paid.
+	for _, consumer := range transaction.Payments {
+		if consumer.Amount >= 0 {
+			continue
+		}
+
+		for _, payer := range transaction.Payments {
+			if payer.Amount <= 0 || payer.Payer == consumer.Payer {
+				continue
+			}
+
+			if s.owed[consumer.Payer] == nil {
+				s.owed[consumer.Payer] = make(map[string]float64)
+			}
+			s.owed[consumer.Payer][payer.Payer] += -consumer.Amount * payer.Amount / paid
+		}
+	}
+}
+
+func (s *Summary) Net(user string) float64 {
+	return s.Paid[user] - s.Consumed[user]
+}
+
+type Settlement = model.Settlement
+
+// Settlements suggests the transfers that settle every net balance, always
+// matching the largest debtor with the largest creditor so that the number of
+// transfers stays small.
+func (s *Summary) Settlements() []Settlement {
+	type position struct {
+		user   string
+		amount float64
+	}
+
+	var debtors, creditors []position
+	for _, user := range s.Users {
+		net := s.Net(user)
+		switch {
+		case net < -epsilon:
+			debtors = append(debtors, position{user, -net})
+		case net > epsilon:
+			creditors = append(creditors, position{user, net})
+		}
+	}
+
+	settlements := []Settlement{}
+
+	for len(debtors) > 0 && len(creditors) > 0 {
+		sort.SliceStable(debtors, func(i, j int) bool { return debtors[i].amount > debtors[j].amount })
+		sort.SliceStable(creditors, func(i, j int) bool { return creditors[i].amount > creditors[j].amount })
+
+		amount := math.Min(debtors[0].amount, creditors[0].amount)
+		settlements = append(settlements, Settlement{From: debtors[0].user, To: creditors[0].user, Amount: amount})
+
+		debtors[0].amount -= amount
+		creditors[0].amount -= amount
+
+		if debtors[0].amount <= epsilon {
+			debtors = debtors[1:]
+		}
+		if creditors[0].amount <= epsilon {
+			creditors = creditors[1:]
+		}
+	}
+
+	return settlements
+}
+
+// Debts lists what each user owes every other user without simplifying the
+// debts, only offsetting what two users owe each other.
+func (s *Summary) Debts() []Settlement {
+	debts := []Settlement{}
+
+	for i, a := range s.Users {
+		for _, b := range s.Users[i+1:] {
+			amount := s.owed[a][b] - s.owed[b][a]
+			switch {
+			case amount > epsilon:
+				debts = append(debts, Settlement{From: a, To: b, Amount: amount})
+			case amount < -epsilon:
+				debts = append(debts, Settlement{From: b, To: a, Amount: -amount})
+			}
+		}
+	}
+
+	return debts
+}
@@ -0,0 +1,63 @@
+package blob
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+)
+
+type Driver string
+
+const (
+	FS Driver = "fs"
+	S3 Driver = "s3"
+)
+
+var (
+	ErrNotFound      = errors.New("blob not found")
+	ErrInvalidKey    = errors.New("invalid blob key")
+	ErrUnknownDriver = errors.New("unknown blob driver")
+)
+
+type Config struct {
+	Driver  Driver `mapstructure:"driver"`
+	MaxSize int64  `mapstructure:"max-size"`
+
+	Dir string `mapstructure:"dir"`
+
+	Endpoint  string `mapstructure:"endpoint"`
+	Bucket    string `mapstructure:"bucket"`
+	Region    string `mapstructure:"region"`
+	Secure    bool   `mapstructure:"secure"`
+	AccessKey string `envconfig:"S3_ACCESS_KEY"`
+	SecretKey string `envconfig:"S3_SECRET_KEY"`
+}
+
+// Store keeps blobs of bytes under string keys.
+type Store interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get returns ErrNotFound if there is no blob for the key.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete succeeds if there is no blob for the key.
+	Delete(ctx context.Context, key string) error
+}
+
+func New(cfg *Config) (Store, error) {
+	switch cfg.Driver {
+	case FS:
+		return NewFSStore(cfg.Dir)
+	case S3:
+		return NewS3Store(cfg)
+	default:
+		return nil, ErrUnknownDriver
+	}
+}
+
+// NewKey returns a random key with the given prefix.
+func NewKey(prefix string) string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return prefix + "/" + hex.EncodeToString(b)
+}
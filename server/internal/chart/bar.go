@@ -0,0 +1,82 @@
+package chart
+
+import (
+	"io"
+	"math"
+)
+
+const (
+	marginTop    = 36
+	marginRight  = 16
+	marginBottom = 44
+	marginLeft   = 56
+	maxLabels    = 12
+)
+
+// Bar draws a vertical bar chart of values. Negative values are drawn below
+// the zero line in the theme's negative colour.
+func Bar(w io.Writer, values []Value, opts Options) error {
+	c := newCanvas(w, opts)
+	theme := opts.Theme
+
+	left, top := float64(marginLeft), float64(marginTop)
+	width := float64(opts.Width - marginLeft - marginRight)
+	height := float64(opts.Height - marginTop - marginBottom)
+
+	if len(values) == 0 || width <= 0 || height <= 0 {
+		c.text(float64(opts.Width)/2, float64(opts.Height)/2, "middle", 12, theme.Muted, "No data")
+		return c.close()
+	}
+
+	lo, hi := 0.0, 0.0
+	for _, v := range values {
+		lo = math.Min(lo, v.Value)
+		hi = math.Max(hi, v.Value)
+	}
+
+	step := niceStep(hi-lo, 5)
+	lo = math.Floor(lo/step) * step
+	hi = math.Ceil(hi/step) * step
+	if hi == lo {
+		hi = lo + step
+	}
+
+	y := func(value float64) float64 {
+		return top + height - (value-lo)/(hi-lo)*height
+	}
+
+	for tick := lo; tick <= hi+step/2; tick += step {
+		c.line(left, y(tick), left+width, y(tick), theme.Grid)
+		c.text(left-6, y(tick)+4, "end", 10, theme.Muted, formatValue(tick))
+	}
+
+	slot := width / float64(len(values))
+	barWidth := slot * 0.7
+	labelEvery := int(math.Ceil(float64(len(values)) / maxLabels))
+
+	for i, v := range values {
+		x := left + float64(i)*slot + (slot-barWidth)/2
+		fill := theme.Palette[0]
+		if v.Value < 0 {
+			fill = theme.Negative
+		}
+
+		c.rect(x, math.Min(y(v.Value), y(0)), barWidth, math.Abs(y(v.Value)-y(0)), fill)
+
+		if len(values) <= maxLabels {
+			valueY := y(v.Value) - 4
+			if v.Value < 0 {
+				valueY = y(v.Value) + 12
+			}
+			c.text(x+barWidth/2, valueY, "middle", 10, theme.Foreground, formatValue(v.Value))
+		}
+
+		if i%labelEvery == 0 {
+			c.text(x+barWidth/2, top+height+16, "middle", 10, theme.Foreground, v.Label)
+		}
+	}
+
+	c.line(left, y(0), left+width, y(0), theme.Foreground)
+
+	return c.close()
+}
@@ -0,0 +1,162 @@
+package chart
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+const (
+	fontFamily = "Helvetica, Arial, sans-serif"
+)
+
+var (
+	ErrUnknownTheme = errors.New("unknown chart theme")
+)
+
+type Theme struct {
+	Name       string
+	Background string
+	Foreground string
+	Muted      string
+	Grid       string
+	Negative   string
+	Palette    []string
+}
+
+var (
+	Light = Theme{
+		Name:       "light",
+		Background: "#ffffff",
+		Foreground: "#222222",
+		Muted:      "#666666",
+		Grid:       "#e0e0e0",
+		Negative:   "#c62828",
+		Palette:    []string{"#1e88e5", "#43a047", "#fb8c00", "#8e24aa", "#00acc1", "#f4511e", "#3949ab", "#7cb342", "#d81b60", "#6d4c41"},
+	}
+
+	Dark = Theme{
+		Name:       "dark",
+		Background: "#1e1e1e",
+		Foreground: "#eeeeee",
+		Muted:      "#aaaaaa",
+		Grid:       "#3a3a3a",
+		Negative:   "#ef5350",
+		Palette:    []string{"#64b5f6", "#81c784", "#ffb74d", "#ba68c8", "#4dd0e1", "#ff8a65", "#7986cb", "#aed581", "#f06292", "#a1887f"},
+	}
+
+	// Mono only uses black, white and greys so that charts stay legible on
+	// e-ink displays and black and white printers.
+	Mono = Theme{
+		Name:       "mono",
+		Background: "#ffffff",
+		Foreground: "#000000",
+		Muted:      "#000000",
+		Grid:       "#bbbbbb",
+		Negative:   "#000000",
+		Palette:    []string{"#000000", "#555555", "#888888", "#aaaaaa", "#cccccc", "#333333", "#777777", "#999999", "#bbbbbb", "#dddddd"},
+	}
+
+	Themes = []string{Light.Name, Dark.Name, Mono.Name}
+)
+
+func ThemeByName(name string) (Theme, error) {
+	for _, theme := range []Theme{Light, Dark, Mono} {
+		if theme.Name == name {
+			return theme, nil
+		}
+	}
+	return Theme{}, ErrUnknownTheme
+}
+
+type Options struct {
+	Title  string
+	Width  int
+	Height int
+	Theme  Theme
+}
+
+type Value struct {
+	Label string
+	Value float64
+}
+
+type canvas struct {
+	w    *bufio.Writer
+	opts Options
+}
+
+func newCanvas(w io.Writer, opts Options) *canvas {
+	c := &canvas{w: bufio.NewWriter(w), opts: opts}
+
+	fmt.Fprintf(c.w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="%s">`, opts.Width, opts.Height, opts.Width, opts.Height, fontFamily)
+	fmt.Fprintf(c.w, `<title>%s</title>`, escape(opts.Title))
+	fmt.Fprintf(c.w, `<rect width="100%%" height="100%%" fill="%s"/>`, opts.Theme.Background)
+	c.text(float64(opts.Width)/2, 20, "middle", 14, opts.Theme.Foreground, opts.Title)
+
+	return c
+}
+
+func (c *canvas) rect(x, y, width, height float64, fill string) {
+	fmt.Fprintf(c.w, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`, x, y, width, height, fill)
+}
+
+func (c *canvas) line(x1, y1, x2, y2 float64, stroke string) {
+	fmt.Fprintf(c.w, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s" stroke-width="1"/>`, x1, y1, x2, y2, stroke)
+}
+
+func (c *canvas) text(x, y float64, anchor string, size int, fill, value string) {
+	fmt.Fprintf(c.w, `<text x="%.1f" y="%.1f" text-anchor="%s" font-size="%d" fill="%s">%s</text>`, x, y, anchor, size, fill, escape(value))
+}
+
+func (c *canvas) raw(format string, args ...interface{}) {
+	fmt.Fprintf(c.w, format, args...)
+}
+
+func (c *canvas) close() error {
+	c.w.WriteString("</svg>\n")
+	return c.w.Flush()
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func formatValue(value float64) string {
+	abs := math.Abs(value)
+	switch {
+	case abs >= 1_000_000:
+		return fmt.Sprintf("%.1fM", value/1_000_000)
+	case abs >= 10_000:
+		return fmt.Sprintf("%.1fk", value/1_000)
+	case abs == math.Trunc(abs):
+		return fmt.Sprintf("%.0f", value)
+	default:
+		return fmt.Sprintf("%.2f", value)
+	}
+}
+
+// niceStep picks a tick spacing of 1, 2 or 5 times a power of ten so that
+// span is covered by roughly ticks intervals.
+func niceStep(span float64, ticks int) float64 {
+	if span <= 0 {
+		return 1
+	}
+
+	raw := span / float64(ticks)
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+
+	for _, factor := range []float64{1, 2, 5, 10} {
+		if step := factor * magnitude; step >= raw {
+			return step
+		}
+	}
+
+	return 10 * magnitude
+}
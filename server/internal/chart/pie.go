@@ -0,0 +1,74 @@
+package chart
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+const (
+	legendWidth = 160
+)
+
+// Pie draws a pie chart of the positive values with a legend listing every
+// slice and its share of the total.
+func Pie(w io.Writer, values []Value, opts Options) error {
+	c := newCanvas(w, opts)
+	theme := opts.Theme
+
+	var total float64
+	var slices []Value
+	for _, v := range values {
+		if v.Value > 0 {
+			total += v.Value
+			slices = append(slices, v)
+		}
+	}
+
+	chartWidth := float64(opts.Width - legendWidth)
+	radius := math.Min(chartWidth, float64(opts.Height-marginTop))/2 - 12
+
+	if total == 0 || radius <= 0 {
+		c.text(float64(opts.Width)/2, float64(opts.Height)/2, "middle", 12, theme.Muted, "No data")
+		return c.close()
+	}
+
+	cx := chartWidth / 2
+	cy := marginTop + (float64(opts.Height)-marginTop)/2
+
+	angle := -math.Pi / 2
+	for i, v := range slices {
+		fill := theme.Palette[i%len(theme.Palette)]
+		sweep := v.Value / total * 2 * math.Pi
+
+		if len(slices) == 1 {
+			c.raw(`<circle cx="%.1f" cy="%.1f" r="%.1f" fill="%s"/>`, cx, cy, radius, fill)
+		} else {
+			x1, y1 := cx+radius*math.Cos(angle), cy+radius*math.Sin(angle)
+			x2, y2 := cx+radius*math.Cos(angle+sweep), cy+radius*math.Sin(angle+sweep)
+
+			largeArc := 0
+			if sweep > math.Pi {
+				largeArc = 1
+			}
+
+			c.raw(`<path d="M%.1f,%.1f L%.1f,%.1f A%.1f,%.1f 0 %d 1 %.1f,%.1f Z" fill="%s" stroke="%s" stroke-width="1"/>`,
+				cx, cy, x1, y1, radius, radius, largeArc, x2, y2, fill, theme.Background)
+		}
+
+		angle += sweep
+	}
+
+	legendX := chartWidth + 8
+	for i, v := range slices {
+		legendY := float64(marginTop + 8 + i*18)
+		if legendY > float64(opts.Height-8) {
+			break
+		}
+
+		c.rect(legendX, legendY, 10, 10, theme.Palette[i%len(theme.Palette)])
+		c.text(legendX+16, legendY+9, "start", 11, theme.Foreground, fmt.Sprintf("%s %.0f%%", v.Label, v.Value/total*100))
+	}
+
+	return c.close()
+}
@@ -0,0 +1,182 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// GroupInfo is a group along with what operators want to know about it.
+// LastActivityAt is when the group was created or one of its transactions
+// was last changed, whichever is later.
+type GroupInfo struct {
+	Group          *Group    `json:"group"`
+	CreatedAt      time.Time `json:"created_at"`
+	Transactions   int64     `json:"transactions"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+}
+
+// InstanceStats counts what an instance holds. Features is only set for
+// Postgres, as only Postgres provides them.
+type InstanceStats struct {
+	Groups                int64         `json:"groups"`
+	ArchivedGroups        int64         `json:"archived_groups"`
+	GroupsPendingDeletion int64         `json:"groups_pending_deletion"`
+	Users                 int64         `json:"users"`
+	Transactions          int64         `json:"transactions"`
+	Features              *FeatureStats `json:"features,omitempty"`
+}
+
+type FeatureStats struct {
+	Comments          int64 `json:"comments"`
+	Attachments       int64 `json:"attachments"`
+	AttachmentBytes   int64 `json:"attachment_bytes"`
+	ImportRules       int64 `json:"import_rules"`
+	Webhooks          int64 `json:"webhooks"`
+	PendingDeliveries int64 `json:"pending_deliveries"`
+	Events            int64 `json:"events"`
+}
+
+// AdminModel gives operators access to every group in Postgres, regardless of
+// its token.
+type AdminModel struct {
+	DB *sql.DB
+}
+
+func (m *AdminModel) GetGroup(id int64, timeout time.Duration) (*GroupInfo, error) {
+	query := `
+		SELECT g.id, g.name, g.token, g.users, g.settings, g.delete_after, g.archived_at, g.version, g.created_at,
+			COUNT(t.id), GREATEST(g.created_at, MAX(t.updated_at))
+		FROM groups g
+		LEFT JOIN transactions t ON t.group_id = g.id
+		WHERE g.id = $1
+		GROUP BY g.id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var group Group
+	info := GroupInfo{Group: &group}
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(&group.ID, &group.Name, &group.Token, pq.Array(&group.Users), &group.Settings,
+		&group.DeleteAfter, &group.ArchivedAt, &group.Version, &info.CreatedAt, &info.Transactions, &info.LastActivityAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &info, nil
+}
+
+// RotateToken gives the group a new random token, after which the old one no
+// longer grants access. Archived groups get one as well.
+func (m *AdminModel) RotateToken(group *Group, timeout time.Duration) error {
+	query := `
+		UPDATE groups
+		SET token = $1, version = version + 1
+		WHERE id = $2 AND version = $3
+		RETURNING version`
+
+	for range 3 {
+		token := GenerateRandomToken()
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := m.DB.QueryRowContext(ctx, query, token, group.ID, group.Version).Scan(&group.Version)
+		cancel()
+
+		if err != nil {
+			var pqErr *pq.Error
+			switch {
+			case errors.As(err, &pqErr) && pqErr.Code == uniqueViolationCode:
+				continue
+			case errors.Is(err, sql.ErrNoRows):
+				return ErrEditConflict
+			default:
+				return err
+			}
+		}
+
+		group.Token = token
+		return nil
+	}
+
+	return ErrCannotGenerateUniqueToken
+}
+
+func (m *AdminModel) GetAllGroupIDs(timeout time.Duration) ([]int64, error) {
+	return queryIDs(m.DB, `SELECT id FROM groups ORDER BY id`, nil, timeout)
+}
+
+// GetAllInactiveSince returns the IDs of the groups without any activity since
+// the given time.
+func (m *AdminModel) GetAllInactiveSince(since time.Time, timeout time.Duration) ([]int64, error) {
+	query := `
+		SELECT g.id
+		FROM groups g
+		LEFT JOIN transactions t ON t.group_id = g.id
+		GROUP BY g.id
+		HAVING GREATEST(g.created_at, MAX(t.updated_at)) < $1
+		ORDER BY g.id`
+
+	return queryIDs(m.DB, query, []interface{}{since}, timeout)
+}
+
+func (m *AdminModel) Stats(timeout time.Duration) (*InstanceStats, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM groups),
+			(SELECT COUNT(*) FROM groups WHERE archived_at IS NOT NULL),
+			(SELECT COUNT(*) FROM groups WHERE delete_after IS NOT NULL),
+			(SELECT COALESCE(SUM(cardinality(users)), 0) FROM groups),
+			(SELECT COUNT(*) FROM transactions),
+			(SELECT COUNT(*) FROM comments),
+			(SELECT COUNT(*) FROM attachments),
+			(SELECT COALESCE(SUM(size), 0) FROM attachments),
+			(SELECT COUNT(*) FROM import_rules),
+			(SELECT COUNT(*) FROM webhooks),
+			(SELECT COUNT(*) FROM webhook_deliveries WHERE status = 'pending'),
+			(SELECT COUNT(*) FROM events)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	stats := InstanceStats{Features: &FeatureStats{}}
+	features := stats.Features
+
+	err := m.DB.QueryRowContext(ctx, query).Scan(&stats.Groups, &stats.ArchivedGroups, &stats.GroupsPendingDeletion, &stats.Users, &stats.Transactions,
+		&features.Comments, &features.Attachments, &features.AttachmentBytes, &features.ImportRules, &features.Webhooks, &features.PendingDeliveries, &features.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+func queryIDs(db *sql.DB, query string, args []interface{}, timeout time.Duration) ([]int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
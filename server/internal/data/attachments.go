@@ -0,0 +1,154 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type AttachmentModel struct {
+	DB *sql.DB
+}
+
+const attachmentColumns = `a.id, a.transaction_id, a.filename, a.content_type, a.size, a.blob_key, a.thumbnail_key, a.created_at`
+
+// Insert adds an attachment to a transaction of the group. It returns
+// ErrRecordNotFound if the transaction does not belong to the group.
+func (m *AttachmentModel) Insert(attachment *Attachment, groupID int64, timeout time.Duration) error {
+	query := `
+		INSERT INTO attachments (transaction_id, filename, content_type, size, blob_key, thumbnail_key)
+		SELECT t.id, $2, $3, $4, $5, $6
+		FROM transactions t
+		WHERE t.id = $1 AND t.group_id = $7
+		RETURNING id, created_at`
+
+	args := []interface{}{attachment.TransactionID, attachment.Filename, attachment.ContentType, attachment.Size, attachment.BlobKey, attachment.ThumbnailKey, groupID}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&attachment.ID, &attachment.CreatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	attachment.HasThumbnail = attachment.ThumbnailKey != ""
+
+	return nil
+}
+
+func (m *AttachmentModel) Get(id, transactionID, groupID int64, timeout time.Duration) (*Attachment, error) {
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM attachments a
+		JOIN transactions t ON t.id = a.transaction_id
+		WHERE a.id = $1 AND a.transaction_id = $2 AND t.group_id = $3`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	attachment, err := scanAttachment(m.DB.QueryRowContext(ctx, query, id, transactionID, groupID))
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return attachment, nil
+}
+
+func (m *AttachmentModel) GetAllForTransaction(transactionID, groupID int64, timeout time.Duration) ([]*Attachment, error) {
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM attachments a
+		JOIN transactions t ON t.id = a.transaction_id
+		WHERE a.transaction_id = $1 AND t.group_id = $2
+		ORDER BY a.id ASC`
+
+	return m.getAll(query, []interface{}{transactionID, groupID}, timeout)
+}
+
+func (m *AttachmentModel) GetAllForGroup(groupID int64, timeout time.Duration) ([]*Attachment, error) {
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM attachments a
+		JOIN transactions t ON t.id = a.transaction_id
+		WHERE t.group_id = $1
+		ORDER BY a.id ASC`
+
+	return m.getAll(query, []interface{}{groupID}, timeout)
+}
+
+// Delete removes the attachment and returns it, so that its blobs can be
+// deleted as well.
+func (m *AttachmentModel) Delete(id, transactionID, groupID int64, timeout time.Duration) (*Attachment, error) {
+	query := `
+		DELETE FROM attachments a
+		USING transactions t
+		WHERE t.id = a.transaction_id AND a.id = $1 AND a.transaction_id = $2 AND t.group_id = $3
+		RETURNING ` + attachmentColumns
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	attachment, err := scanAttachment(m.DB.QueryRowContext(ctx, query, id, transactionID, groupID))
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return attachment, nil
+}
+
+func (m *AttachmentModel) getAll(query string, args []interface{}, timeout time.Duration) ([]*Attachment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachments := []*Attachment{}
+
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+func scanAttachment(row interface{ Scan(...interface{}) error }) (*Attachment, error) {
+	var a Attachment
+
+	err := row.Scan(&a.ID, &a.TransactionID, &a.Filename, &a.ContentType, &a.Size, &a.BlobKey, &a.ThumbnailKey, &a.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	a.HasThumbnail = a.ThumbnailKey != ""
+
+	return &a, nil
+}
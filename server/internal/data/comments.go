@@ -0,0 +1,202 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/soumikc1729/splitty/server/internal/validator"
+)
+
+func ValidateComment(v *validator.Validator, comment *Comment, group *Group) {
+	v.Check(validator.In(comment.Author, group.Users...), "author", "must be one of the group users")
+	v.Check(strings.TrimSpace(comment.Body) != "", "body", "must be provided")
+	v.Check(utf8.RuneCountInString(comment.Body) <= 2000, "body", "must not be more than 2000 characters long")
+}
+
+type CommentModel struct {
+	DB *sql.DB
+}
+
+// Insert adds a comment to a transaction of the group. It returns
+// ErrRecordNotFound if the transaction does not belong to the group.
+func (m *CommentModel) Insert(comment *Comment, groupID int64, timeout time.Duration) error {
+	query := `
+		INSERT INTO comments (transaction_id, author, body)
+		SELECT t.id, $2, $3
+		FROM transactions t
+		WHERE t.id = $1 AND t.group_id = $4
+		RETURNING id, created_at, updated_at, version`
+
+	args := []interface{}{comment.TransactionID, comment.Author, comment.Body, groupID}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&comment.ID, &comment.CreatedAt, &comment.UpdatedAt, &comment.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *CommentModel) Get(id, transactionID, groupID int64, timeout time.Duration) (*Comment, error) {
+	query := `
+		SELECT c.id, c.transaction_id, c.author, c.body, c.created_at, c.updated_at, c.version
+		FROM comments c
+		JOIN transactions t ON t.id = c.transaction_id
+		WHERE c.id = $1 AND c.transaction_id = $2 AND t.group_id = $3`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var c Comment
+
+	err := m.DB.QueryRowContext(ctx, query, id, transactionID, groupID).Scan(&c.ID, &c.TransactionID, &c.Author, &c.Body, &c.CreatedAt, &c.UpdatedAt, &c.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &c, nil
+}
+
+func (m *CommentModel) GetAll(transactionID, groupID int64, timeout time.Duration) ([]Comment, error) {
+	query := `
+		SELECT c.id, c.transaction_id, c.author, c.body, c.created_at, c.updated_at, c.version
+		FROM comments c
+		JOIN transactions t ON t.id = c.transaction_id
+		WHERE c.transaction_id = $1 AND t.group_id = $2
+		ORDER BY c.id ASC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, transactionID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := []Comment{}
+
+	for rows.Next() {
+		var c Comment
+
+		if err := rows.Scan(&c.ID, &c.TransactionID, &c.Author, &c.Body, &c.CreatedAt, &c.UpdatedAt, &c.Version); err != nil {
+			return nil, err
+		}
+
+		comments = append(comments, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+// CountAfterID returns the number of comments of every transaction of the
+// group with an id greater than transactionID. Transactions without comments
+// are left out.
+func (m *CommentModel) CountAfterID(transactionID, groupID int64, timeout time.Duration) (map[int64]int, error) {
+	query := `
+		SELECT c.transaction_id, COUNT(*)
+		FROM comments c
+		JOIN transactions t ON t.id = c.transaction_id
+		WHERE t.id > $1 AND t.group_id = $2
+		GROUP BY c.transaction_id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, transactionID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[int64]int)
+
+	for rows.Next() {
+		var id int64
+		var count int
+
+		if err := rows.Scan(&id, &count); err != nil {
+			return nil, err
+		}
+
+		counts[id] = count
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+func (m *CommentModel) Update(comment *Comment, timeout time.Duration) error {
+	query := `
+		UPDATE comments
+		SET body = $1, updated_at = NOW(), version = version + 1
+		WHERE id = $2 AND transaction_id = $3 AND version = $4
+		RETURNING updated_at, version`
+
+	args := []interface{}{comment.Body, comment.ID, comment.TransactionID, comment.Version}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&comment.UpdatedAt, &comment.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *CommentModel) Delete(id, transactionID, groupID int64, timeout time.Duration) error {
+	query := `
+		DELETE FROM comments c
+		USING transactions t
+		WHERE t.id = c.transaction_id AND c.id = $1 AND c.transaction_id = $2 AND t.group_id = $3`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id, transactionID, groupID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
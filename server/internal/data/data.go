@@ -6,6 +6,30 @@ import (
 	"errors"
 	"regexp"
 	"time"
+
+	"github.com/lib/pq"
+	"github.com/soumikc1729/splitty/server/model"
+)
+
+// The records are defined by package model, which clients of the API share.
+type (
+	Group             = model.Group
+	Settings          = model.Settings
+	SplitMode         = model.SplitMode
+	Transaction       = model.Transaction
+	Payment           = model.Payment
+	Date              = model.Date
+	Comment           = model.Comment
+	Attachment        = model.Attachment
+	ImportRule        = model.ImportRule
+	Webhook           = model.Webhook
+	Delivery          = model.Delivery
+	DeliveryStatus    = model.DeliveryStatus
+	MergeAction       = model.MergeAction
+	Bucket            = model.Bucket
+	SpendingBucket    = model.SpendingBucket
+	MemberSpending    = model.MemberSpending
+	RankedTransaction = model.RankedTransaction
 )
 
 var (
@@ -13,40 +37,144 @@ var (
 )
 
 var (
-	ErrRecordNotFound = errors.New("record not found")
-	ErrEditConflict   = errors.New("edit conflict")
+	ErrRecordNotFound    = errors.New("record not found")
+	ErrEditConflict      = errors.New("edit conflict")
+	ErrGroupArchived     = errors.New("group is archived")
+	ErrDuplicateClientID = errors.New("duplicate client id")
+	ErrUnknownDriver     = errors.New("unknown data driver")
+)
+
+const (
+	// archivedGroupCode is the SQLSTATE raised by the triggers that reject
+	// writes to archived groups.
+	archivedGroupCode = "SP001"
+	// uniqueViolationCode is the SQLSTATE of a unique constraint violation.
+	uniqueViolationCode = "23505"
+)
+
+type Driver string
+
+const (
+	Postgres Driver = "postgres"
+	// SQLite keeps groups, transactions and events in a SQLite database.
+	// Every other feature requires Postgres and is unavailable: import rules,
+	// webhooks, comments, attachments and statistics are answered with 501
+	// Not Implemented.
+	SQLite Driver = "sqlite"
+	// Memory keeps groups, transactions and events in memory, with the same
+	// restrictions as SQLite.
+	Memory Driver = "memory"
 )
 
 type Config struct {
-	DSN          string        `envconfig:"DSN"`
-	QueryTimeout time.Duration `mapstructure:"query-timeout"`
-	MaxOpenConns int           `mapstructure:"max-open-conns"`
-	MaxIdleConns int           `mapstructure:"max-idle-conns"`
-	IdleTimeout  time.Duration `mapstructure:"idle-timeout"`
-	PingTimeout  time.Duration `mapstructure:"ping-timeout"`
+	// Driver is picked by the scheme of the DSN if it is not set.
+	Driver        Driver        `mapstructure:"driver" envconfig:"DRIVER"`
+	DSN           string        `envconfig:"DSN"`
+	QueryTimeout  time.Duration `mapstructure:"query-timeout"`
+	StreamTimeout time.Duration `mapstructure:"stream-timeout"`
+	MaxOpenConns  int           `mapstructure:"max-open-conns"`
+	MaxIdleConns  int           `mapstructure:"max-idle-conns"`
+	IdleTimeout   time.Duration `mapstructure:"idle-timeout"`
+	PingTimeout   time.Duration `mapstructure:"ping-timeout"`
+
+	// AutoMigrate applies pending migrations when the server starts.
+	AutoMigrate bool `mapstructure:"auto-migrate" envconfig:"AUTO_MIGRATE"`
+
+	DeletionGracePeriod time.Duration `mapstructure:"deletion-grace-period"`
+	PurgeInterval       time.Duration `mapstructure:"purge-interval"`
 }
 
 type Data struct {
+	Driver       Driver
 	DB           *sql.DB
-	Groups       GroupModel
-	Transactions TransactionModel
+	Groups       GroupStore
+	Transactions TransactionStore
+	Admin        AdminStore
+	ImportRules  ImportRuleModel
+	Stats        StatsModel
+	Attachments  AttachmentModel
+	Comments     CommentModel
+	Webhooks     WebhookModel
+	Deliveries   DeliveryModel
+	Events       EventStore
 }
 
 func New(cfg *Config) (*Data, error) {
+	switch cfg.driver() {
+	case Memory:
+		store := NewMemoryStore()
+		return &Data{Driver: Memory, Groups: &MemoryGroups{store}, Transactions: &MemoryTransactions{store}, Events: &MemoryEvents{store}}, nil
+	case SQLite:
+		db, err := openSQLite(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return &Data{Driver: SQLite, DB: db, Groups: &SQLiteGroupModel{DB: db}, Transactions: &SQLiteTransactionModel{DB: db}, Events: &SQLiteEventModel{DB: db}, Admin: &SQLiteAdminModel{DB: db}}, nil
+	case Postgres:
+	default:
+		return nil, ErrUnknownDriver
+	}
+
 	db, err := openDB(cfg)
 	if err != nil {
 		return nil, err
 	}
 
 	data := Data{
+		Driver:       Postgres,
 		DB:           db,
-		Groups:       GroupModel{DB: db},
-		Transactions: TransactionModel{DB: db},
+		Groups:       &GroupModel{DB: db},
+		Transactions: &TransactionModel{DB: db},
+		Admin:        &AdminModel{DB: db},
+		ImportRules:  ImportRuleModel{DB: db},
+		Stats:        StatsModel{DB: db},
+		Attachments:  AttachmentModel{DB: db},
+		Comments:     CommentModel{DB: db},
+		Webhooks:     WebhookModel{DB: db},
+		Deliveries:   DeliveryModel{DB: db},
+		Events:       &EventModel{DB: db},
 	}
 
 	return &data, nil
 }
 
+// driver returns the configured driver, or the one the DSN asks for.
+func (cfg *Config) driver() Driver {
+	if cfg.Driver == "" {
+		return DriverFromDSN(cfg.DSN)
+	}
+
+	return cfg.Driver
+}
+
+// Postgres reports whether the data is kept in Postgres, which every feature
+// other than keeping groups, transactions and events requires.
+func (d *Data) Postgres() bool {
+	return d.Driver == Postgres
+}
+
+// groupWriteError maps the error raised when a write hits an archived group
+// to ErrGroupArchived and returns any other error unchanged.
+func groupWriteError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == archivedGroupCode {
+		return ErrGroupArchived
+	}
+
+	return err
+}
+
+// clientIDError maps the error raised when a client ID is already taken to
+// ErrDuplicateClientID and returns any other error unchanged.
+func clientIDError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolationCode && pqErr.Constraint == "transactions_client_id_key" {
+		return ErrDuplicateClientID
+	}
+
+	return err
+}
+
 func openDB(cfg *Config) (*sql.DB, error) {
 	db, err := sql.Open("postgres", cfg.DSN)
 	if err != nil {
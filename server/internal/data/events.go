@@ -0,0 +1,186 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/soumikc1729/splitty/server/internal/events"
+)
+
+type EventModel struct {
+	DB *sql.DB
+}
+
+// Insert stores the event, assigns its ID and announces it on EventsChannel
+// once stored. The data of the event is replaced by its JSON encoding, so
+// that it is encoded only once.
+func (m *EventModel) Insert(event *events.Event, timeout time.Duration) error {
+	query := `
+		INSERT INTO events (group_id, type, data, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`
+
+	dataJSON, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, query, event.GroupID, event.Type, dataJSON, event.CreatedAt).Scan(&event.ID)
+	if err != nil {
+		return err
+	}
+
+	notification, err := json.Marshal(EventNotification{ID: event.ID, GroupID: event.GroupID})
+	if err != nil {
+		return err
+	}
+
+	// Notifications are only delivered once the transaction commits, so
+	// listeners can always read the event they are notified about.
+	if _, err := tx.ExecContext(ctx, `SELECT pg_notify($1, $2)`, EventsChannel, string(notification)); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	event.Data = json.RawMessage(dataJSON)
+
+	return nil
+}
+
+func (m *EventModel) Get(id int64, timeout time.Duration) (*events.Event, error) {
+	query := `
+		SELECT id, group_id, type, data, created_at
+		FROM events
+		WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	event, err := scanEvent(m.DB.QueryRowContext(ctx, query, id))
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return event, nil
+}
+
+// LatestID returns the ID of the latest event of any group, or 0 if there are
+// none.
+func (m *EventModel) LatestID(timeout time.Duration) (int64, error) {
+	query := `
+		SELECT COALESCE(MAX(id), 0)
+		FROM events`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var id int64
+
+	err := m.DB.QueryRowContext(ctx, query).Scan(&id)
+
+	return id, err
+}
+
+// GetAllAfterID returns up to limit events of the group that follow the event
+// with the given ID, oldest first.
+func (m *EventModel) GetAllAfterID(afterID int64, groupID int64, limit int, timeout time.Duration) ([]*events.Event, error) {
+	query := `
+		SELECT id, group_id, type, data, created_at
+		FROM events
+		WHERE group_id = $1 AND id > $2
+		ORDER BY id ASC
+		LIMIT $3`
+
+	return m.getAll(query, []interface{}{groupID, afterID, limit}, timeout)
+}
+
+// GetAllGroupsAfterID is like GetAllAfterID, but returns the events of every
+// group.
+func (m *EventModel) GetAllGroupsAfterID(afterID int64, limit int, timeout time.Duration) ([]*events.Event, error) {
+	query := `
+		SELECT id, group_id, type, data, created_at
+		FROM events
+		WHERE id > $1
+		ORDER BY id ASC
+		LIMIT $2`
+
+	return m.getAll(query, []interface{}{afterID, limit}, timeout)
+}
+
+// DeleteBefore removes the events created before t and returns how many were
+// removed.
+func (m *EventModel) DeleteBefore(t time.Time, timeout time.Duration) (int64, error) {
+	query := `
+		DELETE FROM events
+		WHERE created_at < $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, t)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (m *EventModel) getAll(query string, args []interface{}, timeout time.Duration) ([]*events.Event, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	all := []*events.Event{}
+
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return all, nil
+}
+
+func scanEvent(row interface{ Scan(...interface{}) error }) (*events.Event, error) {
+	var event events.Event
+	var dataJSON json.RawMessage
+
+	if err := row.Scan(&event.ID, &event.GroupID, &event.Type, &dataJSON, &event.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	event.Data = dataJSON
+
+	return &event, nil
+}
@@ -10,14 +10,6 @@ import (
 	"github.com/soumikc1729/splitty/server/internal/validator"
 )
 
-type Group struct {
-	ID      int64    `json:"id"`
-	Name    string   `json:"name"`
-	Token   string   `json:"token"`
-	Users   []string `json:"users"`
-	Version int      `json:"-"`
-}
-
 func ValidateGroup(v *validator.Validator, group *Group) {
 	v.Check(validator.Matches(group.Name, ShortTextRX), "name", "must be 3-50 characters long and contain only letters, numbers, spaces, hyphens, and underscores")
 
@@ -27,6 +19,8 @@ func ValidateGroup(v *validator.Validator, group *Group) {
 	v.Check(validator.All(group.Users, func(u string) bool {
 		return validator.Matches(u, ShortTextRX)
 	}), "users", "each value must be 3-50 characters long and contain only letters, numbers, spaces, hyphens, and underscores")
+
+	ValidateSettings(v, group.Settings)
 }
 
 type GroupModel struct {
@@ -35,15 +29,15 @@ type GroupModel struct {
 
 func (m *GroupModel) Insert(group *Group, timeout time.Duration) error {
 	query := `
-		INSERT INTO groups (name, token, users)
-		VALUES ($1, $2, $3)
+		INSERT INTO groups (name, token, users, settings)
+		VALUES ($1, $2, $3, $4)
 		RETURNING id, version`
 
 	retryCount := 3
 
 	for range retryCount {
 		token := GenerateRandomToken()
-		args := []interface{}{group.Name, token, pq.Array(group.Users)}
+		args := []interface{}{group.Name, token, pq.Array(group.Users), group.Settings}
 
 		ctx, cancel := context.WithTimeout(context.Background(), timeout)
 		defer cancel()
@@ -69,7 +63,7 @@ func (m *GroupModel) GetByIDAndToken(id int64, token string, timeout time.Durati
 	var group Group
 
 	query := `
-		SELECT id, name, token, users, version
+		SELECT id, name, token, users, settings, delete_after, archived_at, version
 		FROM groups
 		WHERE id = $1 AND token = $2`
 
@@ -77,7 +71,7 @@ func (m *GroupModel) GetByIDAndToken(id int64, token string, timeout time.Durati
 	defer cancel()
 
 	row := m.DB.QueryRowContext(ctx, query, id, token)
-	err := row.Scan(&group.ID, &group.Name, &group.Token, pq.Array(&group.Users), &group.Version)
+	err := row.Scan(&group.ID, &group.Name, &group.Token, pq.Array(&group.Users), &group.Settings, &group.DeleteAfter, &group.ArchivedAt, &group.Version)
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
@@ -93,16 +87,43 @@ func (m *GroupModel) GetByIDAndToken(id int64, token string, timeout time.Durati
 func (m *GroupModel) Update(group *Group, timeout time.Duration) error {
 	query := `
 		UPDATE groups
-		SET name = $1, users = $2, version = version + 1
-		WHERE id = $3 AND token = $4 AND version = $5
+		SET name = $1, users = $2, settings = $3, version = version + 1
+		WHERE id = $4 AND token = $5 AND version = $6
 		RETURNING version`
 
-	args := []interface{}{group.Name, pq.Array(group.Users), group.ID, group.Token, group.Version}
+	args := []interface{}{group.Name, pq.Array(group.Users), group.Settings, group.ID, group.Token, group.Version}
 
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
 	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&group.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return groupWriteError(err)
+		}
+	}
+
+	return nil
+}
+
+// ScheduleDeletion marks the group for deletion after the given time, or
+// cancels a scheduled deletion when deleteAfter is nil.
+func (m *GroupModel) ScheduleDeletion(group *Group, deleteAfter *time.Time, timeout time.Duration) error {
+	query := `
+		UPDATE groups
+		SET delete_after = $1, version = version + 1
+		WHERE id = $2 AND token = $3 AND version = $4
+		RETURNING delete_after, version`
+
+	args := []interface{}{deleteAfter, group.ID, group.Token, group.Version}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&group.DeleteAfter, &group.Version)
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
@@ -115,27 +136,105 @@ func (m *GroupModel) Update(group *Group, timeout time.Duration) error {
 	return nil
 }
 
-func (m *GroupModel) Delete(id int64, token string, timeout time.Duration) error {
+// SetArchived archives the group at the given time, or unarchives it when
+// archivedAt is nil.
+func (m *GroupModel) SetArchived(group *Group, archivedAt *time.Time, timeout time.Duration) error {
 	query := `
-		DELETE FROM groups
-		WHERE id = $1 AND token = $2`
+		UPDATE groups
+		SET archived_at = $1, version = version + 1
+		WHERE id = $2 AND token = $3 AND version = $4
+		RETURNING archived_at, version`
+
+	args := []interface{}{archivedAt, group.ID, group.Token, group.Version}
 
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	result, err := m.DB.ExecContext(ctx, query, id, token)
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&group.ArchivedAt, &group.Version)
 	if err != nil {
-		return err
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	return nil
+}
+
+func (m *GroupModel) GetAllDueForDeletion(now time.Time, timeout time.Duration) ([]int64, error) {
+	query := `
+		SELECT id
+		FROM groups
+		WHERE delete_after <= $1
+		ORDER BY delete_after ASC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// Delete removes a group that is due for deletion together with its
+// transactions and import rules in a single SQL transaction. It returns
+// ErrRecordNotFound if the group does not exist or its deletion has been
+// cancelled in the meantime.
+func (m *GroupModel) Delete(id int64, now time.Time, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	if rowsAffected == 0 {
-		return ErrRecordNotFound
+	query := `
+		SELECT id
+		FROM groups
+		WHERE id = $1 AND delete_after <= $2
+		FOR UPDATE`
+
+	err = tx.QueryRowContext(ctx, query, id, now).Scan(&id)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
 	}
 
-	return nil
+	for _, query := range []string{
+		`DELETE FROM transactions WHERE group_id = $1`,
+		`DELETE FROM import_rules WHERE group_id = $1`,
+		`DELETE FROM groups WHERE id = $1`,
+	} {
+		if _, err := tx.ExecContext(ctx, query, id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
@@ -0,0 +1,160 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/soumikc1729/splitty/server/internal/validator"
+)
+
+func ValidateImportRule(v *validator.Validator, rule *ImportRule, group *Group) {
+	_, err := regexp.Compile(rule.Pattern)
+	v.Check(rule.Pattern != "", "pattern", "must be provided")
+	v.Check(len(rule.Pattern) <= 200, "pattern", "must not be more than 200 characters long")
+	v.Check(err == nil, "pattern", "must be a valid regular expression")
+
+	v.Check(rule.Category == "" || validator.Matches(rule.Category, ShortTextRX), "category", "must be 3-50 characters long and contain only letters, numbers, spaces, hyphens, and underscores")
+	v.Check(rule.Payer == "" || validator.In(rule.Payer, group.Users...), "payer", fmt.Sprintf("%s not one of the group users", rule.Payer))
+
+	v.Check(validator.Unique(rule.Split), "split", "must not contain duplicate values")
+	for _, user := range rule.Split {
+		v.Check(validator.In(user, group.Users...), "split", fmt.Sprintf("%s not one of the group users", user))
+	}
+
+	v.Check(rule.GroupID == group.ID, "group_id", "must be same as the id of the group")
+}
+
+type ImportRuleModel struct {
+	DB *sql.DB
+}
+
+func (m *ImportRuleModel) Insert(rule *ImportRule, timeout time.Duration) error {
+	query := `
+		INSERT INTO import_rules (pattern, category, payer, split, group_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, version`
+
+	args := []interface{}{rule.Pattern, rule.Category, rule.Payer, pq.Array(rule.Split), rule.GroupID}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&rule.ID, &rule.Version)
+	return groupWriteError(err)
+}
+
+func (m *ImportRuleModel) Get(id int64, groupID int64, timeout time.Duration) (*ImportRule, error) {
+	query := `
+		SELECT id, pattern, category, payer, split, group_id, version
+		FROM import_rules
+		WHERE id = $1 AND group_id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var rule ImportRule
+
+	row := m.DB.QueryRowContext(ctx, query, id, groupID)
+	err := row.Scan(&rule.ID, &rule.Pattern, &rule.Category, &rule.Payer, pq.Array(&rule.Split), &rule.GroupID, &rule.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &rule, nil
+}
+
+func (m *ImportRuleModel) GetAll(groupID int64, timeout time.Duration) ([]ImportRule, error) {
+	query := `
+		SELECT id, pattern, category, payer, split, group_id, version
+		FROM import_rules
+		WHERE group_id = $1
+		ORDER BY id ASC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []ImportRule{}
+
+	for rows.Next() {
+		var rule ImportRule
+
+		err := rows.Scan(&rule.ID, &rule.Pattern, &rule.Category, &rule.Payer, pq.Array(&rule.Split), &rule.GroupID, &rule.Version)
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, rule)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func (m *ImportRuleModel) Update(rule *ImportRule, timeout time.Duration) error {
+	query := `
+		UPDATE import_rules
+		SET pattern = $1, category = $2, payer = $3, split = $4, version = version + 1
+		WHERE id = $5 AND group_id = $6 AND version = $7
+		RETURNING version`
+
+	args := []interface{}{rule.Pattern, rule.Category, rule.Payer, pq.Array(rule.Split), rule.ID, rule.GroupID, rule.Version}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&rule.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return groupWriteError(err)
+		}
+	}
+
+	return nil
+}
+
+func (m *ImportRuleModel) Delete(id int64, groupID int64, timeout time.Duration) error {
+	query := `
+		DELETE FROM import_rules
+		WHERE id = $1 AND group_id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id, groupID)
+	if err != nil {
+		return groupWriteError(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,564 @@
+package data
+
+import (
+	"cmp"
+	"encoding/json"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/soumikc1729/splitty/server/internal/events"
+)
+
+// MemoryStore keeps groups, their transactions and the events about them in
+// memory, for running the server without a database. Nothing survives a
+// restart.
+type MemoryStore struct {
+	mu sync.RWMutex
+
+	groups       map[int64]*Group
+	transactions map[int64]*Transaction
+	events       []*events.Event
+
+	nextGroupID       int64
+	nextTransactionID int64
+	nextEventID       int64
+}
+
+// MemoryGroups is the GroupStore of a MemoryStore.
+type MemoryGroups struct {
+	*MemoryStore
+}
+
+// MemoryTransactions is the TransactionStore of a MemoryStore.
+type MemoryTransactions struct {
+	*MemoryStore
+}
+
+// MemoryEvents is the EventStore of a MemoryStore.
+type MemoryEvents struct {
+	*MemoryStore
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		groups:       make(map[int64]*Group),
+		transactions: make(map[int64]*Transaction),
+	}
+}
+
+func copyGroup(g *Group) *Group {
+	c := *g
+	c.Users = slices.Clone(g.Users)
+	if g.DeleteAfter != nil {
+		t := *g.DeleteAfter
+		c.DeleteAfter = &t
+	}
+	if g.ArchivedAt != nil {
+		t := *g.ArchivedAt
+		c.ArchivedAt = &t
+	}
+	return &c
+}
+
+func copyTransaction(t *Transaction) *Transaction {
+	c := *t
+	c.Payments = slices.Clone(t.Payments)
+	return &c
+}
+
+// writableGroup returns the group the transactions of which may be written,
+// with the same rules as the triggers of the Postgres schema.
+func (s *MemoryStore) writableGroup(groupID int64, deleting bool) (*Group, error) {
+	group, ok := s.groups[groupID]
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+
+	if group.Archived() && !(deleting && group.DeleteAfter != nil && !group.DeleteAfter.After(time.Now())) {
+		return nil, ErrGroupArchived
+	}
+
+	return group, nil
+}
+
+// lockedGroup returns the stored group if it still has the version of group.
+func (s *MemoryStore) lockedGroup(group *Group) (*Group, error) {
+	stored, ok := s.groups[group.ID]
+	if !ok || stored.Token != group.Token || stored.Version != group.Version {
+		return nil, ErrEditConflict
+	}
+
+	return stored, nil
+}
+
+func (m *MemoryGroups) Insert(group *Group, timeout time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for range 3 {
+		token := GenerateRandomToken()
+
+		if slices.ContainsFunc(slices.Collect(maps.Values(m.groups)), func(g *Group) bool { return g.Token == token }) {
+			continue
+		}
+
+		m.nextGroupID++
+
+		group.ID = m.nextGroupID
+		group.Token = token
+		group.Version = 1
+
+		m.groups[group.ID] = copyGroup(group)
+		return nil
+	}
+
+	return ErrCannotGenerateUniqueToken
+}
+
+func (m *MemoryGroups) GetByIDAndToken(id int64, token string, timeout time.Duration) (*Group, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	group, ok := m.groups[id]
+	if !ok || group.Token != token {
+		return nil, ErrRecordNotFound
+	}
+
+	return copyGroup(group), nil
+}
+
+func (m *MemoryGroups) Update(group *Group, timeout time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, err := m.lockedGroup(group)
+	if err != nil {
+		return err
+	}
+
+	if stored.Archived() && (stored.Name != group.Name || !slices.Equal(stored.Users, group.Users) || stored.Settings != group.Settings) {
+		return ErrGroupArchived
+	}
+
+	stored.Name = group.Name
+	stored.Users = slices.Clone(group.Users)
+	stored.Settings = group.Settings
+	stored.Version++
+
+	group.Version = stored.Version
+	return nil
+}
+
+func (m *MemoryGroups) ScheduleDeletion(group *Group, deleteAfter *time.Time, timeout time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, err := m.lockedGroup(group)
+	if err != nil {
+		return err
+	}
+
+	stored.DeleteAfter = nil
+	if deleteAfter != nil {
+		t := deleteAfter.Truncate(time.Second)
+		stored.DeleteAfter = &t
+	}
+	stored.Version++
+
+	group.DeleteAfter = copyGroup(stored).DeleteAfter
+	group.Version = stored.Version
+	return nil
+}
+
+func (m *MemoryGroups) SetArchived(group *Group, archivedAt *time.Time, timeout time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, err := m.lockedGroup(group)
+	if err != nil {
+		return err
+	}
+
+	stored.ArchivedAt = nil
+	if archivedAt != nil {
+		t := archivedAt.Truncate(time.Second)
+		stored.ArchivedAt = &t
+	}
+	stored.Version++
+
+	group.ArchivedAt = copyGroup(stored).ArchivedAt
+	group.Version = stored.Version
+	return nil
+}
+
+func (m *MemoryGroups) GetAllDueForDeletion(now time.Time, timeout time.Duration) ([]int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	due := []*Group{}
+	for _, group := range m.groups {
+		if group.DeleteAfter != nil && !group.DeleteAfter.After(now) {
+			due = append(due, group)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool { return due[i].DeleteAfter.Before(*due[j].DeleteAfter) })
+
+	ids := []int64{}
+	for _, group := range due {
+		ids = append(ids, group.ID)
+	}
+
+	return ids, nil
+}
+
+func (m *MemoryGroups) Delete(id int64, now time.Time, timeout time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	group, ok := m.groups[id]
+	if !ok || group.DeleteAfter == nil || group.DeleteAfter.After(now) {
+		return ErrRecordNotFound
+	}
+
+	for tid, transaction := range m.transactions {
+		if transaction.GroupID == id {
+			delete(m.transactions, tid)
+		}
+	}
+
+	delete(m.groups, id)
+	return nil
+}
+
+func (m *MemoryGroups) Merge(target, source *Group, mapping map[string]string, action MergeAction, timeout time.Duration) ([]*Transaction, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	storedSource, err := m.lockedGroup(source)
+	if err != nil {
+		return nil, err
+	}
+
+	storedTarget, ok := m.groups[target.ID]
+	if !ok || storedTarget.Version != target.Version {
+		return nil, ErrEditConflict
+	}
+
+	if storedTarget.Archived() {
+		return nil, ErrGroupArchived
+	}
+
+	storedTarget.Users = slices.Clone(target.Users)
+	storedTarget.Version++
+	target.Version = storedTarget.Version
+
+	moved := []*Transaction{}
+	for _, transaction := range m.transactions {
+		if transaction.GroupID == source.ID {
+			transaction.GroupID = target.ID
+			transaction.Payments = remapPayments(transaction.Payments, mapping)
+			transaction.Version++
+			moved = append(moved, copyTransaction(transaction))
+		}
+	}
+
+	slices.SortFunc(moved, func(a, b *Transaction) int { return cmp.Compare(a.ID, b.ID) })
+
+	switch action {
+	case MergeArchive:
+		now := time.Now().Truncate(time.Second)
+		storedSource.ArchivedAt = &now
+		storedSource.Version++
+		source.ArchivedAt = copyGroup(storedSource).ArchivedAt
+		source.Version = storedSource.Version
+	default:
+		delete(m.groups, source.ID)
+	}
+
+	return moved, nil
+}
+
+func (m *MemoryTransactions) Insert(transaction *Transaction, timeout time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.insert(transaction)
+}
+
+func (m *MemoryTransactions) InsertAll(transactions []*Transaction, timeout time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Checking every transaction first keeps InsertAll all or nothing. Client
+	// IDs have to be unique within the batch too.
+	batch := map[string]bool{}
+
+	for _, transaction := range transactions {
+		if _, err := m.writableGroup(transaction.GroupID, false); err != nil {
+			return err
+		}
+		if m.clientIDTaken(transaction.ClientID) || batch[transaction.ClientID] {
+			return ErrDuplicateClientID
+		}
+		if transaction.ClientID != "" {
+			batch[transaction.ClientID] = true
+		}
+	}
+
+	for _, transaction := range transactions {
+		if err := m.insert(transaction); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *MemoryTransactions) insert(transaction *Transaction) error {
+	if _, err := m.writableGroup(transaction.GroupID, false); err != nil {
+		return err
+	}
+
+	if m.clientIDTaken(transaction.ClientID) {
+		return ErrDuplicateClientID
+	}
+
+	m.nextTransactionID++
+
+	now := time.Now().Truncate(time.Second)
+
+	transaction.ID = m.nextTransactionID
+	transaction.CreatedAt = now
+	transaction.UpdatedAt = now
+	transaction.Version = 1
+
+	m.transactions[transaction.ID] = copyTransaction(transaction)
+	return nil
+}
+
+func (m *MemoryTransactions) clientIDTaken(clientID string) bool {
+	if clientID == "" {
+		return false
+	}
+
+	return slices.ContainsFunc(slices.Collect(maps.Values(m.transactions)), func(t *Transaction) bool { return t.ClientID == clientID })
+}
+
+func (m *MemoryTransactions) Get(id int64, groupID int64, timeout time.Duration) (*Transaction, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	transaction, ok := m.transactions[id]
+	if !ok || transaction.GroupID != groupID {
+		return nil, ErrRecordNotFound
+	}
+
+	return copyTransaction(transaction), nil
+}
+
+func (m *MemoryTransactions) GetByClientID(clientID string, groupID int64, timeout time.Duration) (*Transaction, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, transaction := range m.transactions {
+		if transaction.ClientID == clientID && transaction.GroupID == groupID {
+			return copyTransaction(transaction), nil
+		}
+	}
+
+	return nil, ErrRecordNotFound
+}
+
+func (m *MemoryTransactions) GetAllAfterID(id int64, groupID int64, timeout time.Duration) (*[]Transaction, error) {
+	var transactions []Transaction
+
+	err := m.StreamAllAfterID(id, groupID, timeout, func(transaction *Transaction) error {
+		transactions = append(transactions, *transaction)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &transactions, nil
+}
+
+func (m *MemoryTransactions) StreamAllAfterID(id int64, groupID int64, timeout time.Duration, fn func(*Transaction) error) error {
+	transactions := m.filter(func(t *Transaction) bool { return t.GroupID == groupID && t.ID > id })
+
+	sort.Slice(transactions, func(i, j int) bool { return transactions[i].ID < transactions[j].ID })
+
+	return stream(transactions, fn)
+}
+
+func (m *MemoryTransactions) StreamAllBefore(before Date, groupID int64, timeout time.Duration, fn func(*Transaction) error) error {
+	transactions := m.filter(func(t *Transaction) bool { return t.GroupID == groupID && t.Date.Before(before.Time) })
+
+	sort.Slice(transactions, func(i, j int) bool {
+		if !transactions[i].Date.Equal(transactions[j].Date.Time) {
+			return transactions[i].Date.Before(transactions[j].Date.Time)
+		}
+		return transactions[i].ID < transactions[j].ID
+	})
+
+	return stream(transactions, fn)
+}
+
+// filter returns copies of the matching transactions, so that fn may be
+// called without holding the lock.
+func (m *MemoryTransactions) filter(match func(*Transaction) bool) []*Transaction {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	transactions := []*Transaction{}
+	for _, transaction := range m.transactions {
+		if match(transaction) {
+			transactions = append(transactions, copyTransaction(transaction))
+		}
+	}
+
+	return transactions
+}
+
+func stream(transactions []*Transaction, fn func(*Transaction) error) error {
+	for _, transaction := range transactions {
+		if err := fn(transaction); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *MemoryTransactions) Update(transaction *Transaction, timeout time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.transactions[transaction.ID]
+	if !ok || stored.GroupID != transaction.GroupID || stored.Version != transaction.Version {
+		return ErrEditConflict
+	}
+
+	if _, err := m.writableGroup(stored.GroupID, false); err != nil {
+		return err
+	}
+
+	stored.Title = transaction.Title
+	stored.Category = transaction.Category
+	stored.Payments = slices.Clone(transaction.Payments)
+	stored.Date = transaction.Date
+	stored.UpdatedAt = time.Now()
+	stored.Version++
+
+	transaction.UpdatedAt = stored.UpdatedAt
+	transaction.Version = stored.Version
+	return nil
+}
+
+func (m *MemoryTransactions) Delete(id int64, groupID int64, timeout time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.transactions[id]
+	if !ok || stored.GroupID != groupID {
+		return ErrRecordNotFound
+	}
+
+	if _, err := m.writableGroup(groupID, true); err != nil {
+		return err
+	}
+
+	delete(m.transactions, id)
+	return nil
+}
+
+// Insert keeps the event and assigns its ID. As with EventModel, the data of
+// the event is replaced by its JSON encoding.
+func (m *MemoryEvents) Insert(event *events.Event, timeout time.Duration) error {
+	dataJSON, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextEventID++
+	event.ID = m.nextEventID
+	event.Data = json.RawMessage(dataJSON)
+
+	stored := *event
+	m.events = append(m.events, &stored)
+
+	return nil
+}
+
+func (m *MemoryEvents) Get(id int64, timeout time.Duration) (*events.Event, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	i, found := slices.BinarySearchFunc(m.events, id, func(e *events.Event, id int64) int {
+		return cmp.Compare(e.ID, id)
+	})
+	if !found {
+		return nil, ErrRecordNotFound
+	}
+
+	event := *m.events[i]
+	return &event, nil
+}
+
+func (m *MemoryEvents) LatestID(timeout time.Duration) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(m.events) == 0 {
+		return 0, nil
+	}
+
+	return m.events[len(m.events)-1].ID, nil
+}
+
+func (m *MemoryEvents) GetAllAfterID(afterID int64, groupID int64, limit int, timeout time.Duration) ([]*events.Event, error) {
+	return m.getAll(afterID, limit, func(e *events.Event) bool { return e.GroupID == groupID }), nil
+}
+
+func (m *MemoryEvents) GetAllGroupsAfterID(afterID int64, limit int, timeout time.Duration) ([]*events.Event, error) {
+	return m.getAll(afterID, limit, func(*events.Event) bool { return true }), nil
+}
+
+// getAll returns copies of up to limit matching events following the event
+// with the given ID. Events are kept in the order of their IDs.
+func (m *MemoryEvents) getAll(afterID int64, limit int, match func(*events.Event) bool) []*events.Event {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	all := []*events.Event{}
+
+	for _, e := range m.events {
+		if len(all) == limit {
+			break
+		}
+
+		if e.ID > afterID && match(e) {
+			event := *e
+			all = append(all, &event)
+		}
+	}
+
+	return all
+}
+
+func (m *MemoryEvents) DeleteBefore(t time.Time, timeout time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n := len(m.events)
+	m.events = slices.DeleteFunc(m.events, func(e *events.Event) bool { return e.CreatedAt.Before(t) })
+
+	return int64(n - len(m.events)), nil
+}
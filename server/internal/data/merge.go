@@ -0,0 +1,188 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	MergeDelete  MergeAction = "delete"
+	MergeArchive MergeAction = "archive"
+)
+
+var (
+	MergeActions = []string{string(MergeDelete), string(MergeArchive)}
+)
+
+// Merge moves every transaction of source into target, renaming payers
+// according to mapping, and then deletes or archives source. target.Users must
+// already contain every mapped member. Both groups are checked against their
+// versions and all changes happen in a single SQL transaction. The moved
+// transactions are returned as they are stored in target.
+func (m *GroupModel) Merge(target, source *Group, mapping map[string]string, action MergeAction, timeout time.Duration) ([]*Transaction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Locking the source row first keeps new transactions from being added
+	// to it while its transactions are being moved.
+	var sourceVersion int
+	err = tx.QueryRowContext(ctx, `SELECT version FROM groups WHERE id = $1 FOR UPDATE`, source.ID).Scan(&sourceVersion)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrEditConflict
+		default:
+			return nil, err
+		}
+	}
+
+	if sourceVersion != source.Version {
+		return nil, ErrEditConflict
+	}
+
+	query := `
+		UPDATE groups
+		SET users = $1, version = version + 1
+		WHERE id = $2 AND version = $3
+		RETURNING version`
+
+	err = tx.QueryRowContext(ctx, query, pq.Array(target.Users), target.ID, target.Version).Scan(&target.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrEditConflict
+		default:
+			return nil, groupWriteError(err)
+		}
+	}
+
+	moved, err := moveTransactions(ctx, tx, target.ID, source.ID, mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	switch action {
+	case MergeArchive:
+		query = `
+			UPDATE groups
+			SET archived_at = now(), version = version + 1
+			WHERE id = $1
+			RETURNING archived_at, version`
+
+		err = tx.QueryRowContext(ctx, query, source.ID).Scan(&source.ArchivedAt, &source.Version)
+	default:
+		_, err = tx.ExecContext(ctx, `DELETE FROM groups WHERE id = $1`, source.ID)
+	}
+
+	if err != nil {
+		return nil, groupWriteError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return moved, nil
+}
+
+func moveTransactions(ctx context.Context, tx *sql.Tx, targetID, sourceID int64, mapping map[string]string) ([]*Transaction, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id, payments FROM transactions WHERE group_id = $1 ORDER BY id FOR UPDATE`, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type move struct {
+		id       int64
+		payments []Payment
+	}
+
+	var moves []move
+
+	for rows.Next() {
+		var mv move
+		var paymentsJSON []byte
+
+		if err := rows.Scan(&mv.id, &paymentsJSON); err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(paymentsJSON, &mv.payments); err != nil {
+			return nil, err
+		}
+
+		moves = append(moves, mv)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE transactions
+		SET group_id = $1, payments = $2, version = version + 1
+		WHERE id = $3
+		RETURNING id, title, category, payments, date, group_id, COALESCE(client_id::text, ''), created_at, updated_at, version`
+
+	moved := []*Transaction{}
+
+	for _, mv := range moves {
+		paymentsJSON, err := json.Marshal(remapPayments(mv.payments, mapping))
+		if err != nil {
+			return nil, err
+		}
+
+		var transaction Transaction
+
+		err = tx.QueryRowContext(ctx, query, targetID, paymentsJSON, mv.id).Scan(&transaction.ID, &transaction.Title, &transaction.Category, &paymentsJSON, &transaction.Date, &transaction.GroupID, &transaction.ClientID, &transaction.CreatedAt, &transaction.UpdatedAt, &transaction.Version)
+		if err != nil {
+			return nil, groupWriteError(err)
+		}
+
+		if err := json.Unmarshal(paymentsJSON, &transaction.Payments); err != nil {
+			return nil, err
+		}
+
+		moved = append(moved, &transaction)
+	}
+
+	return moved, nil
+}
+
+// remapPayments renames every payer according to mapping, merging the
+// payments of payers that end up with the same name.
+func remapPayments(payments []Payment, mapping map[string]string) []Payment {
+	var remapped []Payment
+
+	for _, p := range payments {
+		if mapped, ok := mapping[p.Payer]; ok {
+			p.Payer = mapped
+		}
+
+		merged := false
+		for i := range remapped {
+			if remapped[i].Payer == p.Payer {
+				remapped[i].Amount += p.Amount
+				merged = true
+				break
+			}
+		}
+
+		if !merged {
+			remapped = append(remapped, p)
+		}
+	}
+
+	return remapped
+}
@@ -0,0 +1,215 @@
+package data
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	migratesqlite "github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/soumikc1729/splitty/server/migrations"
+)
+
+var (
+	ErrSchemaBehind = errors.New("database schema is behind the migrations")
+	ErrNoMigrations = errors.New("the data driver has no migrations")
+	ErrSchemaDirty  = errors.New("database schema is dirty, fix it and force its version")
+)
+
+// MigrationStatus tells how far the database schema is migrated.
+type MigrationStatus struct {
+	// Version is the last applied migration, 0 if there is none.
+	Version uint
+	// Dirty is set if applying the migration failed halfway.
+	Dirty bool
+	// Latest is the last of the embedded migrations.
+	Latest uint
+}
+
+func (s MigrationStatus) Pending() bool {
+	return s.Version < s.Latest
+}
+
+// Migrations applies the migrations embedded into the binary to the database
+// of the config. Postgres migrations run under an advisory lock, so that
+// servers starting at the same time do not apply them twice.
+type Migrations struct {
+	m      *migrate.Migrate
+	latest uint
+}
+
+// MigrationLogger receives the progress of migrations.
+type MigrationLogger func(format string, v ...interface{})
+
+func (l MigrationLogger) Printf(format string, v ...interface{}) {
+	l(format, v...)
+}
+
+func (l MigrationLogger) Verbose() bool {
+	return false
+}
+
+// NewMigrations opens a connection of its own to the database of the config,
+// which Close closes again.
+func NewMigrations(cfg *Config, logger MigrationLogger) (*Migrations, error) {
+	var (
+		files fs.FS
+		db    *sql.DB
+		err   error
+	)
+
+	switch cfg.driver() {
+	case Postgres:
+		files = migrations.Postgres
+		db, err = openDB(cfg)
+	case SQLite:
+		files, err = fs.Sub(migrations.SQLite, "sqlite")
+		if err == nil {
+			db, err = openSQLite(cfg)
+		}
+	case Memory:
+		return nil, ErrNoMigrations
+	default:
+		return nil, ErrUnknownDriver
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := iofs.New(files, ".")
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	latest, err := latestMigration(src)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	var driver database.Driver
+	switch cfg.driver() {
+	case SQLite:
+		driver, err = migratesqlite.WithInstance(db, &migratesqlite.Config{})
+	default:
+		driver, err = postgres.WithInstance(db, &postgres.Config{})
+	}
+
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, string(cfg.driver()), driver)
+	if err != nil {
+		driver.Close()
+		return nil, err
+	}
+
+	if logger != nil {
+		m.Log = logger
+	}
+
+	return &Migrations{m: m, latest: latest}, nil
+}
+
+func latestMigration(src source.Driver) (uint, error) {
+	version, err := src.First()
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	for {
+		next, err := src.Next(version)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return version, nil
+			}
+			return 0, err
+		}
+		version = next
+	}
+}
+
+// Up applies every pending migration.
+func (m *Migrations) Up() error {
+	if err := m.m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+
+	return nil
+}
+
+// Down reverts the last steps migrations.
+func (m *Migrations) Down(steps int) error {
+	if err := m.m.Steps(-steps); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+
+	return nil
+}
+
+// Force sets the version of the schema without migrating it and clears the
+// dirty flag, after a failed migration was cleaned up by hand.
+func (m *Migrations) Force(version int) error {
+	return m.m.Force(version)
+}
+
+func (m *Migrations) Status() (MigrationStatus, error) {
+	version, dirty, err := m.m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return MigrationStatus{}, err
+	}
+
+	return MigrationStatus{Version: version, Dirty: dirty, Latest: m.latest}, nil
+}
+
+func (m *Migrations) Close() error {
+	sourceErr, dbErr := m.m.Close()
+	return errors.Join(sourceErr, dbErr)
+}
+
+// CheckSchema makes sure the database schema is up to date before the server
+// starts, applying pending migrations first if the config asks for it.
+func CheckSchema(cfg *Config, logger MigrationLogger) error {
+	if cfg.driver() == Memory {
+		return nil
+	}
+
+	m, err := NewMigrations(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if cfg.AutoMigrate {
+		if err := m.Up(); err != nil {
+			return err
+		}
+	}
+
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case status.Dirty:
+		return fmt.Errorf("%w: version %d", ErrSchemaDirty, status.Version)
+	case status.Pending():
+		return fmt.Errorf("%w: at version %d of %d", ErrSchemaBehind, status.Version, status.Latest)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,47 @@
+package data
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	// EventsChannel is the channel on which every stored event is announced,
+	// so that every instance of the server can pass it on to its own
+	// subscribers.
+	EventsChannel = "splitty_events"
+)
+
+// EventNotification is the payload sent on EventsChannel. Events themselves
+// may be larger than a notification allows, so they are read separately.
+type EventNotification struct {
+	ID      int64 `json:"id"`
+	GroupID int64 `json:"group_id"`
+}
+
+// NewEventListener opens a dedicated connection that listens on
+// EventsChannel. It reconnects on its own after losing the connection, which
+// is signalled by a nil notification, as notifications sent in the meantime
+// are lost.
+func NewEventListener(cfg *Config, minReconnectInterval, maxReconnectInterval time.Duration, callback pq.EventCallbackType) (*pq.Listener, error) {
+	listener := pq.NewListener(cfg.DSN, minReconnectInterval, maxReconnectInterval, callback)
+
+	if err := listener.Listen(EventsChannel); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	return listener, nil
+}
+
+func ParseEventNotification(n *pq.Notification) (*EventNotification, error) {
+	var notification EventNotification
+
+	if err := json.Unmarshal([]byte(n.Extra), &notification); err != nil {
+		return nil, err
+	}
+
+	return &notification, nil
+}
@@ -0,0 +1,26 @@
+package data
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/soumikc1729/splitty/server/internal/validator"
+	"github.com/soumikc1729/splitty/server/model"
+)
+
+var (
+	SplitModes = []string{string(model.SplitManual), string(model.SplitEqual)}
+
+	CurrencyRX = regexp.MustCompile(`^[A-Z]{3}$`)
+	LocaleRX   = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z][a-z]{3})?(-([A-Z]{2}|[0-9]{3}))?$`)
+)
+
+func ValidateSettings(v *validator.Validator, settings Settings) {
+	v.Check(validator.Matches(settings.Currency, CurrencyRX), "settings.currency", "must be a three letter ISO 4217 currency code")
+	v.Check(validator.In(string(settings.DefaultSplit), SplitModes...), "settings.default_split", "must be one of manual or equal")
+	v.Check(validator.Matches(settings.Locale, LocaleRX), "settings.locale", "must be a language tag such as en-US")
+	v.Check(validator.Between(settings.Precision, 0, 4), "settings.precision", "must be between 0 and 4")
+
+	_, err := time.LoadLocation(settings.Timezone)
+	v.Check(settings.Timezone != "" && err == nil, "settings.timezone", "must be an IANA time zone such as Europe/Berlin")
+}
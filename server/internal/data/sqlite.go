@@ -0,0 +1,955 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/soumikc1729/splitty/server/internal/events"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+const (
+	// sqliteTimeLayout keeps times as text that sorts chronologically, which
+	// the schema relies on when comparing them. Times are always stored in
+	// UTC.
+	sqliteTimeLayout = "2006-01-02 15:04:05-07:00"
+	// sqlitePragmas are set on every connection. Foreign keys are needed for
+	// the transactions of a group to be deleted with it, and taking the write
+	// lock when a transaction begins keeps concurrent writers from failing
+	// instead of waiting for each other.
+	sqlitePragmas = "_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_txlock=immediate"
+)
+
+// DriverFromDSN picks the driver by the scheme of the DSN: sqlite: and file:
+// DSNs are SQLite databases and anything else is Postgres.
+func DriverFromDSN(dsn string) Driver {
+	scheme, _, _ := strings.Cut(dsn, ":")
+
+	switch scheme {
+	case "sqlite", "file":
+		return SQLite
+	default:
+		return Postgres
+	}
+}
+
+// sqliteDSN turns a sqlite://path or sqlite:path DSN into one the SQLite
+// driver understands and adds the pragmas every connection needs.
+func sqliteDSN(dsn string) string {
+	if path, ok := strings.CutPrefix(dsn, "sqlite://"); ok {
+		dsn = path
+	} else if path, ok := strings.CutPrefix(dsn, "sqlite:"); ok {
+		dsn = path
+	}
+
+	if strings.Contains(dsn, "?") {
+		return dsn + "&" + sqlitePragmas
+	}
+
+	return dsn + "?" + sqlitePragmas
+}
+
+func openSQLite(cfg *Config) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", sqliteDSN(cfg.DSN))
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxIdleTime(cfg.IdleTimeout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.PingTimeout)
+	defer cancel()
+
+	err = db.PingContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func sqliteTime(t time.Time) string {
+	return t.UTC().Format(sqliteTimeLayout)
+}
+
+// sqliteNullTime is sqliteTime for optional times.
+func sqliteNullTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+
+	return sqliteTime(*t)
+}
+
+// sqliteWriteError maps the errors raised by the constraints and triggers of
+// the SQLite schema like groupWriteError and clientIDError do for Postgres.
+func sqliteWriteError(err error) error {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+
+	switch {
+	case sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_TRIGGER && strings.Contains(sqliteErr.Error(), archivedGroupCode):
+		return ErrGroupArchived
+	case sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE && strings.Contains(sqliteErr.Error(), "transactions.client_id"):
+		return ErrDuplicateClientID
+	default:
+		return err
+	}
+}
+
+func isSQLiteDuplicateToken(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE && strings.Contains(sqliteErr.Error(), "groups.token")
+}
+
+// SQLiteGroupModel keeps groups in SQLite, for self-hosting without Postgres.
+// Users and settings are kept as JSON.
+type SQLiteGroupModel struct {
+	DB *sql.DB
+}
+
+const sqliteGroupColumns = `id, name, token, users, settings, delete_after, archived_at, version`
+
+func (m *SQLiteGroupModel) Insert(group *Group, timeout time.Duration) error {
+	query := `
+		INSERT INTO groups (name, token, users, settings, created_at)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING id, version`
+
+	usersJSON, err := json.Marshal(group.Users)
+	if err != nil {
+		return err
+	}
+
+	settingsJSON, err := json.Marshal(group.Settings)
+	if err != nil {
+		return err
+	}
+
+	retryCount := 3
+
+	for range retryCount {
+		token := GenerateRandomToken()
+		args := []interface{}{group.Name, token, string(usersJSON), string(settingsJSON), sqliteTime(time.Now())}
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		err := m.DB.QueryRowContext(ctx, query, args...).Scan(&group.ID, &group.Version)
+		if err != nil {
+			switch {
+			case isSQLiteDuplicateToken(err):
+				continue
+			default:
+				return err
+			}
+		}
+
+		group.Token = token
+		return nil
+	}
+
+	return ErrCannotGenerateUniqueToken
+}
+
+func (m *SQLiteGroupModel) GetByIDAndToken(id int64, token string, timeout time.Duration) (*Group, error) {
+	query := `
+		SELECT ` + sqliteGroupColumns + `
+		FROM groups
+		WHERE id = ? AND token = ?`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	group, err := scanSQLiteGroup(m.DB.QueryRowContext(ctx, query, id, token))
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return group, nil
+}
+
+func (m *SQLiteGroupModel) Update(group *Group, timeout time.Duration) error {
+	query := `
+		UPDATE groups
+		SET name = ?, users = ?, settings = ?, version = version + 1
+		WHERE id = ? AND token = ? AND version = ?
+		RETURNING version`
+
+	usersJSON, err := json.Marshal(group.Users)
+	if err != nil {
+		return err
+	}
+
+	settingsJSON, err := json.Marshal(group.Settings)
+	if err != nil {
+		return err
+	}
+
+	args := []interface{}{group.Name, string(usersJSON), string(settingsJSON), group.ID, group.Token, group.Version}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err = m.DB.QueryRowContext(ctx, query, args...).Scan(&group.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return sqliteWriteError(err)
+		}
+	}
+
+	return nil
+}
+
+func (m *SQLiteGroupModel) ScheduleDeletion(group *Group, deleteAfter *time.Time, timeout time.Duration) error {
+	query := `
+		UPDATE groups
+		SET delete_after = ?, version = version + 1
+		WHERE id = ? AND token = ? AND version = ?
+		RETURNING delete_after, version`
+
+	return m.setTime(query, group, deleteAfter, &group.DeleteAfter, timeout)
+}
+
+func (m *SQLiteGroupModel) SetArchived(group *Group, archivedAt *time.Time, timeout time.Duration) error {
+	query := `
+		UPDATE groups
+		SET archived_at = ?, version = version + 1
+		WHERE id = ? AND token = ? AND version = ?
+		RETURNING archived_at, version`
+
+	return m.setTime(query, group, archivedAt, &group.ArchivedAt, timeout)
+}
+
+// setTime runs a query setting one of the optional times of the group and
+// scans the stored time into dest.
+func (m *SQLiteGroupModel) setTime(query string, group *Group, t *time.Time, dest **time.Time, timeout time.Duration) error {
+	args := []interface{}{sqliteNullTime(t), group.ID, group.Token, group.Version}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(dest, &group.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *SQLiteGroupModel) GetAllDueForDeletion(now time.Time, timeout time.Duration) ([]int64, error) {
+	query := `
+		SELECT id
+		FROM groups
+		WHERE delete_after <= ?
+		ORDER BY delete_after ASC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, sqliteTime(now))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// Delete removes a group that is due for deletion. Its transactions are
+// deleted with it by the foreign key.
+func (m *SQLiteGroupModel) Delete(id int64, now time.Time, timeout time.Duration) error {
+	query := `
+		DELETE FROM groups
+		WHERE id = ? AND delete_after <= ?`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id, sqliteTime(now))
+	if err != nil {
+		return sqliteWriteError(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Merge is GroupModel.Merge for SQLite. The write lock taken when the SQL
+// transaction begins keeps anyone else from changing either group meanwhile.
+func (m *SQLiteGroupModel) Merge(target, source *Group, mapping map[string]string, action MergeAction, timeout time.Duration) ([]*Transaction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var sourceVersion int
+	err = tx.QueryRowContext(ctx, `SELECT version FROM groups WHERE id = ?`, source.ID).Scan(&sourceVersion)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrEditConflict
+		default:
+			return nil, err
+		}
+	}
+
+	if sourceVersion != source.Version {
+		return nil, ErrEditConflict
+	}
+
+	usersJSON, err := json.Marshal(target.Users)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE groups
+		SET users = ?, version = version + 1
+		WHERE id = ? AND version = ?
+		RETURNING version`
+
+	err = tx.QueryRowContext(ctx, query, string(usersJSON), target.ID, target.Version).Scan(&target.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrEditConflict
+		default:
+			return nil, sqliteWriteError(err)
+		}
+	}
+
+	moved, err := moveSQLiteTransactions(ctx, tx, target.ID, source.ID, mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	switch action {
+	case MergeArchive:
+		query = `
+			UPDATE groups
+			SET archived_at = ?, version = version + 1
+			WHERE id = ?
+			RETURNING archived_at, version`
+
+		err = tx.QueryRowContext(ctx, query, sqliteTime(time.Now()), source.ID).Scan(&source.ArchivedAt, &source.Version)
+	default:
+		_, err = tx.ExecContext(ctx, `DELETE FROM groups WHERE id = ?`, source.ID)
+	}
+
+	if err != nil {
+		return nil, sqliteWriteError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return moved, nil
+}
+
+func moveSQLiteTransactions(ctx context.Context, tx *sql.Tx, targetID, sourceID int64, mapping map[string]string) ([]*Transaction, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT id, payments FROM transactions WHERE group_id = ? ORDER BY id`, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type move struct {
+		id       int64
+		payments []Payment
+	}
+
+	var moves []move
+
+	for rows.Next() {
+		var mv move
+		var paymentsJSON []byte
+
+		if err := rows.Scan(&mv.id, &paymentsJSON); err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(paymentsJSON, &mv.payments); err != nil {
+			return nil, err
+		}
+
+		moves = append(moves, mv)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE transactions
+		SET group_id = ?, payments = ?, version = version + 1
+		WHERE id = ?
+		RETURNING ` + sqliteTransactionColumns
+
+	moved := []*Transaction{}
+
+	for _, mv := range moves {
+		paymentsJSON, err := json.Marshal(remapPayments(mv.payments, mapping))
+		if err != nil {
+			return nil, err
+		}
+
+		transaction, err := scanSQLiteTransaction(tx.QueryRowContext(ctx, query, targetID, string(paymentsJSON), mv.id))
+		if err != nil {
+			return nil, sqliteWriteError(err)
+		}
+
+		moved = append(moved, transaction)
+	}
+
+	return moved, nil
+}
+
+// scanSQLiteGroup scans the sqliteGroupColumns of the row, followed by the
+// columns to scan into extra.
+func scanSQLiteGroup(row interface{ Scan(...interface{}) error }, extra ...interface{}) (*Group, error) {
+	var group Group
+	var usersJSON []byte
+
+	dest := []interface{}{&group.ID, &group.Name, &group.Token, &usersJSON, &group.Settings, &group.DeleteAfter, &group.ArchivedAt, &group.Version}
+
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(usersJSON, &group.Users); err != nil {
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+// SQLiteTransactionModel keeps transactions in SQLite. Payments are kept as
+// JSON.
+type SQLiteTransactionModel struct {
+	DB *sql.DB
+}
+
+const sqliteTransactionColumns = `id, title, category, payments, date, group_id, COALESCE(client_id, ''), created_at, updated_at, version`
+
+func (t *SQLiteTransactionModel) Insert(transaction *Transaction, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return insertSQLiteTransaction(ctx, t.DB, transaction)
+}
+
+func (t *SQLiteTransactionModel) InsertAll(transactions []*Transaction, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	tx, err := t.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, transaction := range transactions {
+		if err := insertSQLiteTransaction(ctx, tx, transaction); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func insertSQLiteTransaction(ctx context.Context, db interface {
+	QueryRowContext(context.Context, string, ...interface{}) *sql.Row
+}, transaction *Transaction) error {
+	query := `
+		INSERT INTO transactions (title, category, payments, date, group_id, client_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, NULLIF(?, ''), ?, ?)
+		RETURNING id, created_at, updated_at, version`
+
+	paymentsJSON, err := json.Marshal(transaction.Payments)
+	if err != nil {
+		return err
+	}
+
+	now := sqliteTime(time.Now())
+
+	args := []interface{}{transaction.Title, transaction.Category, string(paymentsJSON), transaction.Date, transaction.GroupID, strings.ToLower(transaction.ClientID), now, now}
+
+	err = db.QueryRowContext(ctx, query, args...).Scan(&transaction.ID, &transaction.CreatedAt, &transaction.UpdatedAt, &transaction.Version)
+	return sqliteWriteError(err)
+}
+
+func (t *SQLiteTransactionModel) Get(id int64, groupID int64, timeout time.Duration) (*Transaction, error) {
+	query := `
+		SELECT ` + sqliteTransactionColumns + `
+		FROM transactions
+		WHERE id = ? AND group_id = ?`
+
+	return t.get(query, []interface{}{id, groupID}, timeout)
+}
+
+func (t *SQLiteTransactionModel) GetByClientID(clientID string, groupID int64, timeout time.Duration) (*Transaction, error) {
+	query := `
+		SELECT ` + sqliteTransactionColumns + `
+		FROM transactions
+		WHERE client_id = ? AND group_id = ?`
+
+	return t.get(query, []interface{}{strings.ToLower(clientID), groupID}, timeout)
+}
+
+func (t *SQLiteTransactionModel) get(query string, args []interface{}, timeout time.Duration) (*Transaction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	transaction, err := scanSQLiteTransaction(t.DB.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return transaction, nil
+}
+
+func (t *SQLiteTransactionModel) GetAllAfterID(id int64, groupID int64, timeout time.Duration) (*[]Transaction, error) {
+	var transactions []Transaction
+
+	err := t.StreamAllAfterID(id, groupID, timeout, func(transaction *Transaction) error {
+		transactions = append(transactions, *transaction)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &transactions, nil
+}
+
+func (t *SQLiteTransactionModel) StreamAllAfterID(id int64, groupID int64, timeout time.Duration, fn func(*Transaction) error) error {
+	query := `
+		SELECT ` + sqliteTransactionColumns + `
+		FROM transactions
+		WHERE id > ? AND group_id = ?
+		ORDER BY id ASC`
+
+	return t.stream(query, []interface{}{id, groupID}, timeout, fn)
+}
+
+func (t *SQLiteTransactionModel) StreamAllBefore(before Date, groupID int64, timeout time.Duration, fn func(*Transaction) error) error {
+	query := `
+		SELECT ` + sqliteTransactionColumns + `
+		FROM transactions
+		WHERE date < ? AND group_id = ?
+		ORDER BY date ASC, id ASC`
+
+	return t.stream(query, []interface{}{before, groupID}, timeout, fn)
+}
+
+func (t *SQLiteTransactionModel) stream(query string, args []interface{}, timeout time.Duration, fn func(*Transaction) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	rows, err := t.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		transaction, err := scanSQLiteTransaction(rows)
+		if err != nil {
+			return err
+		}
+
+		if err = fn(transaction); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (t *SQLiteTransactionModel) Update(transaction *Transaction, timeout time.Duration) error {
+	query := `
+		UPDATE transactions
+		SET title = ?, category = ?, payments = ?, date = ?, updated_at = ?, version = version + 1
+		WHERE id = ? AND group_id = ? AND version = ?
+		RETURNING updated_at, version`
+
+	paymentsJSON, err := json.Marshal(transaction.Payments)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	args := []interface{}{transaction.Title, transaction.Category, string(paymentsJSON), transaction.Date, sqliteTime(time.Now()), transaction.ID, transaction.GroupID, transaction.Version}
+
+	err = t.DB.QueryRowContext(ctx, query, args...).Scan(&transaction.UpdatedAt, &transaction.Version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrEditConflict
+		}
+		return sqliteWriteError(err)
+	}
+
+	return nil
+}
+
+func (t *SQLiteTransactionModel) Delete(id int64, groupID int64, timeout time.Duration) error {
+	query := `
+		DELETE FROM transactions
+		WHERE id = ? AND group_id = ?`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	result, err := t.DB.ExecContext(ctx, query, id, groupID)
+	if err != nil {
+		return sqliteWriteError(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func scanSQLiteTransaction(row interface{ Scan(...interface{}) error }) (*Transaction, error) {
+	var transaction Transaction
+	var paymentsJSON []byte
+
+	err := row.Scan(&transaction.ID, &transaction.Title, &transaction.Category, &paymentsJSON, &transaction.Date, &transaction.GroupID, &transaction.ClientID, &transaction.CreatedAt, &transaction.UpdatedAt, &transaction.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(paymentsJSON, &transaction.Payments); err != nil {
+		return nil, err
+	}
+
+	return &transaction, nil
+}
+
+// SQLiteAdminModel gives operators access to every group in SQLite,
+// regardless of its token.
+type SQLiteAdminModel struct {
+	DB *sql.DB
+}
+
+func (m *SQLiteAdminModel) GetGroup(id int64, timeout time.Duration) (*GroupInfo, error) {
+	// MAX with several arguments is NULL if any of them is, hence the
+	// COALESCE for groups without transactions.
+	query := `
+		SELECT g.id, g.name, g.token, g.users, g.settings, g.delete_after, g.archived_at, g.version, g.created_at,
+			COUNT(t.id), MAX(g.created_at, COALESCE(MAX(t.updated_at), g.created_at))
+		FROM groups g
+		LEFT JOIN transactions t ON t.group_id = g.id
+		WHERE g.id = ?
+		GROUP BY g.id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var info GroupInfo
+	var lastActivityAt string
+
+	group, err := scanSQLiteGroup(m.DB.QueryRowContext(ctx, query, id), &info.CreatedAt, &info.Transactions, &lastActivityAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	info.Group = group
+
+	info.LastActivityAt, err = time.Parse(sqliteTimeLayout, lastActivityAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+func (m *SQLiteAdminModel) RotateToken(group *Group, timeout time.Duration) error {
+	query := `
+		UPDATE groups
+		SET token = ?, version = version + 1
+		WHERE id = ? AND version = ?
+		RETURNING version`
+
+	for range 3 {
+		token := GenerateRandomToken()
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := m.DB.QueryRowContext(ctx, query, token, group.ID, group.Version).Scan(&group.Version)
+		cancel()
+
+		if err != nil {
+			switch {
+			case isSQLiteDuplicateToken(err):
+				continue
+			case errors.Is(err, sql.ErrNoRows):
+				return ErrEditConflict
+			default:
+				return err
+			}
+		}
+
+		group.Token = token
+		return nil
+	}
+
+	return ErrCannotGenerateUniqueToken
+}
+
+func (m *SQLiteAdminModel) GetAllGroupIDs(timeout time.Duration) ([]int64, error) {
+	return queryIDs(m.DB, `SELECT id FROM groups ORDER BY id`, nil, timeout)
+}
+
+func (m *SQLiteAdminModel) GetAllInactiveSince(since time.Time, timeout time.Duration) ([]int64, error) {
+	query := `
+		SELECT g.id
+		FROM groups g
+		LEFT JOIN transactions t ON t.group_id = g.id
+		GROUP BY g.id
+		HAVING MAX(g.created_at, COALESCE(MAX(t.updated_at), g.created_at)) < ?
+		ORDER BY g.id`
+
+	return queryIDs(m.DB, query, []interface{}{sqliteTime(since)}, timeout)
+}
+
+func (m *SQLiteAdminModel) Stats(timeout time.Duration) (*InstanceStats, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM groups),
+			(SELECT COUNT(*) FROM groups WHERE archived_at IS NOT NULL),
+			(SELECT COUNT(*) FROM groups WHERE delete_after IS NOT NULL),
+			(SELECT COALESCE(SUM(json_array_length(users)), 0) FROM groups),
+			(SELECT COUNT(*) FROM transactions)`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stats InstanceStats
+
+	err := m.DB.QueryRowContext(ctx, query).Scan(&stats.Groups, &stats.ArchivedGroups, &stats.GroupsPendingDeletion, &stats.Users, &stats.Transactions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+// SQLiteEventModel keeps events in SQLite. A SQLite database is only used by
+// a single instance of the server, so no other instance needs to be notified
+// of new events.
+type SQLiteEventModel struct {
+	DB *sql.DB
+}
+
+const sqliteEventColumns = `id, group_id, type, data, created_at`
+
+// Insert stores the event and assigns its ID. As with EventModel, the data of
+// the event is replaced by its JSON encoding.
+func (m *SQLiteEventModel) Insert(event *events.Event, timeout time.Duration) error {
+	query := `
+		INSERT INTO events (group_id, type, data, created_at)
+		VALUES (?, ?, ?, ?)
+		RETURNING id`
+
+	dataJSON, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err = m.DB.QueryRowContext(ctx, query, event.GroupID, event.Type, string(dataJSON), sqliteTime(event.CreatedAt)).Scan(&event.ID)
+	if err != nil {
+		return err
+	}
+
+	event.Data = json.RawMessage(dataJSON)
+
+	return nil
+}
+
+func (m *SQLiteEventModel) Get(id int64, timeout time.Duration) (*events.Event, error) {
+	query := `
+		SELECT ` + sqliteEventColumns + `
+		FROM events
+		WHERE id = ?`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	event, err := scanSQLiteEvent(m.DB.QueryRowContext(ctx, query, id))
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return event, nil
+}
+
+func (m *SQLiteEventModel) LatestID(timeout time.Duration) (int64, error) {
+	query := `
+		SELECT COALESCE(MAX(id), 0)
+		FROM events`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var id int64
+
+	err := m.DB.QueryRowContext(ctx, query).Scan(&id)
+
+	return id, err
+}
+
+func (m *SQLiteEventModel) GetAllAfterID(afterID int64, groupID int64, limit int, timeout time.Duration) ([]*events.Event, error) {
+	query := `
+		SELECT ` + sqliteEventColumns + `
+		FROM events
+		WHERE group_id = ? AND id > ?
+		ORDER BY id ASC
+		LIMIT ?`
+
+	return m.getAll(query, []interface{}{groupID, afterID, limit}, timeout)
+}
+
+func (m *SQLiteEventModel) GetAllGroupsAfterID(afterID int64, limit int, timeout time.Duration) ([]*events.Event, error) {
+	query := `
+		SELECT ` + sqliteEventColumns + `
+		FROM events
+		WHERE id > ?
+		ORDER BY id ASC
+		LIMIT ?`
+
+	return m.getAll(query, []interface{}{afterID, limit}, timeout)
+}
+
+func (m *SQLiteEventModel) DeleteBefore(t time.Time, timeout time.Duration) (int64, error) {
+	query := `
+		DELETE FROM events
+		WHERE created_at < ?`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, sqliteTime(t))
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (m *SQLiteEventModel) getAll(query string, args []interface{}, timeout time.Duration) ([]*events.Event, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	all := []*events.Event{}
+
+	for rows.Next() {
+		event, err := scanSQLiteEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return all, nil
+}
+
+func scanSQLiteEvent(row interface{ Scan(...interface{}) error }) (*events.Event, error) {
+	var event events.Event
+	var dataJSON string
+
+	if err := row.Scan(&event.ID, &event.GroupID, &event.Type, &dataJSON, &event.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	event.Data = json.RawMessage(dataJSON)
+
+	return &event, nil
+}
@@ -0,0 +1,172 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+const (
+	Day   Bucket = "day"
+	Week  Bucket = "week"
+	Month Bucket = "month"
+)
+
+var (
+	Buckets = []string{string(Day), string(Week), string(Month)}
+)
+
+type StatsModel struct {
+	DB *sql.DB
+}
+
+// SpendingSeries aggregates the payments of the transactions dated within
+// [from, to) into buckets of the given size. A positive payment counts as paid
+// by its payer, a negative one as consumed.
+func (m *StatsModel) SpendingSeries(groupID int64, bucket Bucket, from, to Date, timeout time.Duration) ([]SpendingBucket, error) {
+	query := `
+		SELECT date_trunc($1, t.date::timestamp)::date AS bucket,
+			p->>'payer' AS payer,
+			SUM(GREATEST((p->>'amount')::numeric, 0)) AS paid,
+			SUM(GREATEST(-(p->>'amount')::numeric, 0)) AS consumed
+		FROM transactions t, jsonb_array_elements(t.payments) AS p
+		WHERE t.group_id = $2 AND t.date >= $3 AND t.date < $4
+		GROUP BY bucket, payer
+		ORDER BY bucket ASC, payer ASC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, string(bucket), groupID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	series := []SpendingBucket{}
+
+	for rows.Next() {
+		var start Date
+		var payer string
+		var spending MemberSpending
+
+		if err := rows.Scan(&start, &payer, &spending.Paid, &spending.Consumed); err != nil {
+			return nil, err
+		}
+
+		if len(series) == 0 || !series[len(series)-1].Start.Equal(start.Time) {
+			series = append(series, SpendingBucket{Start: start, Members: make(map[string]MemberSpending)})
+		}
+
+		current := &series[len(series)-1]
+		current.Total += spending.Paid
+		current.Members[payer] = spending
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return series, nil
+}
+
+// TopTransactions returns the limit most expensive transactions dated within
+// [from, to), ranked by the total amount paid.
+func (m *StatsModel) TopTransactions(groupID int64, from, to Date, limit int, timeout time.Duration) ([]RankedTransaction, error) {
+	query := `
+		SELECT t.id, t.title, t.category, t.payments, t.date, t.group_id, t.created_at, t.version,
+			(SELECT COALESCE(SUM(GREATEST((p->>'amount')::numeric, 0)), 0) FROM jsonb_array_elements(t.payments) AS p) AS total
+		FROM transactions t
+		WHERE t.group_id = $1 AND t.date >= $2 AND t.date < $3
+		ORDER BY total DESC, t.id ASC
+		LIMIT $4`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, groupID, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ranked := []RankedTransaction{}
+
+	for rows.Next() {
+		var r RankedTransaction
+		var paymentsJSON []byte
+
+		err := rows.Scan(
+			&r.ID,
+			&r.Title,
+			&r.Category,
+			&paymentsJSON,
+			&r.Date,
+			&r.GroupID,
+			&r.CreatedAt,
+			&r.Version,
+			&r.Total,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err = json.Unmarshal(paymentsJSON, &r.Payments); err != nil {
+			return nil, err
+		}
+
+		ranked = append(ranked, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ranked, nil
+}
+
+type CategoryTotal struct {
+	Category string  `json:"category"`
+	Total    float64 `json:"total"`
+}
+
+// CategoryTotals sums the amount paid for the transactions dated within
+// [from, to) per category, largest first. Transactions without a category are
+// reported under the empty category.
+func (m *StatsModel) CategoryTotals(groupID int64, from, to Date, timeout time.Duration) ([]CategoryTotal, error) {
+	query := `
+		SELECT t.category, SUM(GREATEST((p->>'amount')::numeric, 0)) AS total
+		FROM transactions t, jsonb_array_elements(t.payments) AS p
+		WHERE t.group_id = $1 AND t.date >= $2 AND t.date < $3
+		GROUP BY t.category
+		ORDER BY total DESC, t.category ASC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, groupID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := []CategoryTotal{}
+
+	for rows.Next() {
+		var c CategoryTotal
+
+		if err := rows.Scan(&c.Category, &c.Total); err != nil {
+			return nil, err
+		}
+
+		totals = append(totals, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return totals, nil
+}
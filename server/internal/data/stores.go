@@ -0,0 +1,75 @@
+package data
+
+import (
+	"time"
+
+	"github.com/soumikc1729/splitty/server/internal/events"
+)
+
+// GroupStore keeps groups. GroupModel keeps them in Postgres,
+// SQLiteGroupModel in SQLite and MemoryStore in memory. Writes are checked
+// against the version of the group and fail with ErrEditConflict if it
+// changed, reads of missing groups fail with ErrRecordNotFound and changes to
+// archived groups fail with ErrGroupArchived.
+type GroupStore interface {
+	Insert(group *Group, timeout time.Duration) error
+	GetByIDAndToken(id int64, token string, timeout time.Duration) (*Group, error)
+	Update(group *Group, timeout time.Duration) error
+	ScheduleDeletion(group *Group, deleteAfter *time.Time, timeout time.Duration) error
+	SetArchived(group *Group, archivedAt *time.Time, timeout time.Duration) error
+	GetAllDueForDeletion(now time.Time, timeout time.Duration) ([]int64, error)
+	Delete(id int64, now time.Time, timeout time.Duration) error
+	Merge(target, source *Group, mapping map[string]string, action MergeAction, timeout time.Duration) ([]*Transaction, error)
+}
+
+// TransactionStore keeps the transactions of groups, with the same semantics
+// as GroupStore.
+type TransactionStore interface {
+	Insert(transaction *Transaction, timeout time.Duration) error
+	InsertAll(transactions []*Transaction, timeout time.Duration) error
+	Get(id int64, groupID int64, timeout time.Duration) (*Transaction, error)
+	GetByClientID(clientID string, groupID int64, timeout time.Duration) (*Transaction, error)
+	GetAllAfterID(id int64, groupID int64, timeout time.Duration) (*[]Transaction, error)
+	StreamAllAfterID(id int64, groupID int64, timeout time.Duration, fn func(*Transaction) error) error
+	StreamAllBefore(before Date, groupID int64, timeout time.Duration, fn func(*Transaction) error) error
+	Update(transaction *Transaction, timeout time.Duration) error
+	Delete(id int64, groupID int64, timeout time.Duration) error
+}
+
+// EventStore keeps the events of every group for a while, so that clients
+// can catch up on the events they missed. EventModel keeps them in Postgres,
+// where storing an event also notifies every instance of the server,
+// SQLiteEventModel in SQLite and MemoryEvents in memory.
+type EventStore interface {
+	Insert(event *events.Event, timeout time.Duration) error
+	Get(id int64, timeout time.Duration) (*events.Event, error)
+	LatestID(timeout time.Duration) (int64, error)
+	GetAllAfterID(afterID int64, groupID int64, limit int, timeout time.Duration) ([]*events.Event, error)
+	GetAllGroupsAfterID(afterID int64, limit int, timeout time.Duration) ([]*events.Event, error)
+	DeleteBefore(t time.Time, timeout time.Duration) (int64, error)
+}
+
+// AdminStore gives operators access to every group, regardless of its token.
+// AdminModel implements it for Postgres and SQLiteAdminModel for SQLite. The
+// memory driver has none, as nothing it keeps outlives the server.
+type AdminStore interface {
+	GetGroup(id int64, timeout time.Duration) (*GroupInfo, error)
+	RotateToken(group *Group, timeout time.Duration) error
+	GetAllGroupIDs(timeout time.Duration) ([]int64, error)
+	GetAllInactiveSince(since time.Time, timeout time.Duration) ([]int64, error)
+	Stats(timeout time.Duration) (*InstanceStats, error)
+}
+
+var (
+	_ GroupStore       = (*GroupModel)(nil)
+	_ TransactionStore = (*TransactionModel)(nil)
+	_ GroupStore       = (*SQLiteGroupModel)(nil)
+	_ TransactionStore = (*SQLiteTransactionModel)(nil)
+	_ GroupStore       = (*MemoryGroups)(nil)
+	_ TransactionStore = (*MemoryTransactions)(nil)
+	_ EventStore       = (*EventModel)(nil)
+	_ EventStore       = (*SQLiteEventModel)(nil)
+	_ EventStore       = (*MemoryEvents)(nil)
+	_ AdminStore       = (*AdminModel)(nil)
+	_ AdminStore       = (*SQLiteAdminModel)(nil)
+)
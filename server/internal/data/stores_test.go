@@ -0,0 +1,60 @@
+package data_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/internal/data/storetest"
+)
+
+// TestStores runs the checks of package storetest against every storage
+// backend. Postgres is only checked when STORETEST_DSN names a scratch
+// database, as the checks leave their groups behind.
+func TestStores(t *testing.T) {
+	backends := []struct {
+		name string
+		cfg  func(t *testing.T) data.Config
+	}{
+		{"memory", func(t *testing.T) data.Config {
+			return data.Config{Driver: data.Memory}
+		}},
+		{"sqlite", func(t *testing.T) data.Config {
+			return data.Config{DSN: "sqlite://" + filepath.Join(t.TempDir(), "splitty.db")}
+		}},
+		{"postgres", func(t *testing.T) data.Config {
+			dsn := os.Getenv("STORETEST_DSN")
+			if dsn == "" {
+				t.Skip("STORETEST_DSN is not set")
+			}
+			return data.Config{DSN: dsn}
+		}},
+	}
+
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			cfg := backend.cfg(t)
+			cfg.MaxOpenConns = 4
+			cfg.MaxIdleConns = 4
+			cfg.IdleTimeout = time.Minute
+			cfg.PingTimeout = 5 * time.Second
+			cfg.AutoMigrate = true
+
+			if err := data.CheckSchema(&cfg, nil); err != nil {
+				t.Fatalf("failed to migrate: %v", err)
+			}
+
+			d, err := data.New(&cfg)
+			if err != nil {
+				t.Fatalf("failed to open: %v", err)
+			}
+			if d.DB != nil {
+				t.Cleanup(func() { d.DB.Close() })
+			}
+
+			storetest.Run(t, storetest.Stores{Groups: d.Groups, Transactions: d.Transactions})
+		})
+	}
+}
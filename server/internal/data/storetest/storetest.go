@@ -0,0 +1,539 @@
+// Package storetest checks that implementations of data.GroupStore and
+// data.TransactionStore behave the same, in the spirit of testing/fstest. The
+// tests of package data run it against every storage backend.
+package storetest
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/model"
+)
+
+const timeout = 5 * time.Second
+
+// Stores are the stores under test. They must be empty or only hold data
+// that the checks can ignore, as every check creates its own groups.
+type Stores struct {
+	Groups       data.GroupStore
+	Transactions data.TransactionStore
+}
+
+type check struct {
+	name string
+	fn   func(s Stores) error
+}
+
+var checks = []check{
+	{"groups are inserted and read back", checkGroupRoundTrip},
+	{"group updates are checked against the version", checkGroupVersion},
+	{"groups due for deletion are deleted with their transactions", checkGroupDeletion},
+	{"archived groups are read-only", checkArchivedGroup},
+	{"transactions are inserted, updated and deleted", checkTransactionLifecycle},
+	{"client ids are unique", checkClientIDs},
+	{"inserting many transactions is all or nothing", checkInsertAll},
+	{"transactions are streamed in order", checkStreams},
+	{"merging moves transactions and removes the source", checkMerge},
+}
+
+// Run runs every check against the stores, each as a subtest of t.
+func Run(t *testing.T, s Stores) {
+	t.Helper()
+
+	for _, c := range checks {
+		t.Run(c.name, func(t *testing.T) {
+			if err := c.fn(s); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func newGroup(s Stores, users ...string) (*data.Group, error) {
+	if len(users) == 0 {
+		users = []string{"Soumik", "Paulomi"}
+	}
+
+	group := &data.Group{Name: "Conformance", Users: users, Settings: model.DefaultSettings()}
+	if err := s.Groups.Insert(group, timeout); err != nil {
+		return nil, fmt.Errorf("insert group: %w", err)
+	}
+
+	return group, nil
+}
+
+func newTransaction(s Stores, group *data.Group, title string, date data.Date) (*data.Transaction, error) {
+	transaction := &data.Transaction{
+		Title:    title,
+		Payments: []data.Payment{{Payer: group.Users[0], Amount: 10}, {Payer: group.Users[1], Amount: -10}},
+		Date:     date,
+		GroupID:  group.ID,
+	}
+	if err := s.Transactions.Insert(transaction, timeout); err != nil {
+		return nil, fmt.Errorf("insert transaction: %w", err)
+	}
+
+	return transaction, nil
+}
+
+func expectErr(what string, got, want error) error {
+	if !errors.Is(got, want) {
+		return fmt.Errorf("%s: got error %v, want %v", what, got, want)
+	}
+
+	return nil
+}
+
+func checkGroupRoundTrip(s Stores) error {
+	group, err := newGroup(s)
+	if err != nil {
+		return err
+	}
+
+	if group.ID == 0 || group.Token == "" || group.Version != 1 {
+		return fmt.Errorf("inserted group has id %d, token %q and version %d", group.ID, group.Token, group.Version)
+	}
+
+	got, err := s.Groups.GetByIDAndToken(group.ID, group.Token, timeout)
+	if err != nil {
+		return fmt.Errorf("get group: %w", err)
+	}
+
+	if !reflect.DeepEqual(got, group) {
+		return fmt.Errorf("got group %+v, want %+v", got, group)
+	}
+
+	_, err = s.Groups.GetByIDAndToken(group.ID, group.Token+"X", timeout)
+	return expectErr("get group with wrong token", err, data.ErrRecordNotFound)
+}
+
+func checkGroupVersion(s Stores) error {
+	group, err := newGroup(s)
+	if err != nil {
+		return err
+	}
+
+	stale := *group
+
+	group.Name = "Conformance Renamed"
+	group.Users = append(group.Users, "Rohan")
+	group.Settings.Currency = "INR"
+	if err := s.Groups.Update(group, timeout); err != nil {
+		return fmt.Errorf("update group: %w", err)
+	}
+
+	if group.Version != 2 {
+		return fmt.Errorf("updated group has version %d, want 2", group.Version)
+	}
+
+	got, err := s.Groups.GetByIDAndToken(group.ID, group.Token, timeout)
+	if err != nil {
+		return fmt.Errorf("get group: %w", err)
+	}
+
+	if !reflect.DeepEqual(got, group) {
+		return fmt.Errorf("got group %+v, want %+v", got, group)
+	}
+
+	return expectErr("update stale group", s.Groups.Update(&stale, timeout), data.ErrEditConflict)
+}
+
+func checkGroupDeletion(s Stores) error {
+	group, err := newGroup(s)
+	if err != nil {
+		return err
+	}
+
+	transaction, err := newTransaction(s, group, "Dinner", model.NewDate(2024, time.May, 1))
+	if err != nil {
+		return err
+	}
+
+	// Whole seconds, as some stores keep no more precise times.
+	now := time.Now().Truncate(time.Second)
+	deleteAfter := now.Add(time.Hour)
+
+	if err := s.Groups.ScheduleDeletion(group, &deleteAfter, timeout); err != nil {
+		return fmt.Errorf("schedule deletion: %w", err)
+	}
+
+	if group.DeleteAfter == nil || !group.DeleteAfter.Equal(deleteAfter) {
+		return fmt.Errorf("scheduled group is deleted after %v, want %v", group.DeleteAfter, deleteAfter)
+	}
+
+	if err := expectErr("delete group before it is due", s.Groups.Delete(group.ID, now, timeout), data.ErrRecordNotFound); err != nil {
+		return err
+	}
+
+	later := deleteAfter.Add(time.Minute)
+
+	ids, err := s.Groups.GetAllDueForDeletion(later, timeout)
+	if err != nil {
+		return fmt.Errorf("get groups due for deletion: %w", err)
+	}
+
+	if !containsID(ids, group.ID) {
+		return fmt.Errorf("group %d is not due for deletion in %v", group.ID, ids)
+	}
+
+	if err := s.Groups.Delete(group.ID, later, timeout); err != nil {
+		return fmt.Errorf("delete group: %w", err)
+	}
+
+	if _, err := s.Groups.GetByIDAndToken(group.ID, group.Token, timeout); !errors.Is(err, data.ErrRecordNotFound) {
+		return fmt.Errorf("get deleted group: got error %v, want %v", err, data.ErrRecordNotFound)
+	}
+
+	_, err = s.Transactions.Get(transaction.ID, group.ID, timeout)
+	return expectErr("get transaction of deleted group", err, data.ErrRecordNotFound)
+}
+
+func checkArchivedGroup(s Stores) error {
+	group, err := newGroup(s)
+	if err != nil {
+		return err
+	}
+
+	transaction, err := newTransaction(s, group, "Dinner", model.NewDate(2024, time.May, 1))
+	if err != nil {
+		return err
+	}
+
+	archivedAt := time.Now()
+	if err := s.Groups.SetArchived(group, &archivedAt, timeout); err != nil {
+		return fmt.Errorf("archive group: %w", err)
+	}
+
+	if !group.Archived() {
+		return errors.New("archived group is not archived")
+	}
+
+	renamed := *group
+	renamed.Name = "Conformance Renamed"
+	if err := expectErr("rename archived group", s.Groups.Update(&renamed, timeout), data.ErrGroupArchived); err != nil {
+		return err
+	}
+
+	if _, err := newTransaction(s, group, "Lunch", model.NewDate(2024, time.May, 2)); !errors.Is(err, data.ErrGroupArchived) {
+		return fmt.Errorf("insert transaction into archived group: got error %v, want %v", err, data.ErrGroupArchived)
+	}
+
+	transaction.Title = "Dinner Out"
+	if err := expectErr("update transaction of archived group", s.Transactions.Update(transaction, timeout), data.ErrGroupArchived); err != nil {
+		return err
+	}
+
+	if err := expectErr("delete transaction of archived group", s.Transactions.Delete(transaction.ID, group.ID, timeout), data.ErrGroupArchived); err != nil {
+		return err
+	}
+
+	if err := s.Groups.SetArchived(group, nil, timeout); err != nil {
+		return fmt.Errorf("unarchive group: %w", err)
+	}
+
+	if err := s.Transactions.Update(transaction, timeout); err != nil {
+		return fmt.Errorf("update transaction of unarchived group: %w", err)
+	}
+
+	return nil
+}
+
+func checkTransactionLifecycle(s Stores) error {
+	group, err := newGroup(s)
+	if err != nil {
+		return err
+	}
+
+	other, err := newGroup(s)
+	if err != nil {
+		return err
+	}
+
+	transaction, err := newTransaction(s, group, "Dinner", model.NewDate(2024, time.May, 1))
+	if err != nil {
+		return err
+	}
+
+	if transaction.ID == 0 || transaction.Version != 1 || transaction.CreatedAt.IsZero() || transaction.UpdatedAt.IsZero() {
+		return fmt.Errorf("inserted transaction is %+v", transaction)
+	}
+
+	got, err := s.Transactions.Get(transaction.ID, group.ID, timeout)
+	if err != nil {
+		return fmt.Errorf("get transaction: %w", err)
+	}
+
+	if !sameTransaction(got, transaction) {
+		return fmt.Errorf("got transaction %+v, want %+v", got, transaction)
+	}
+
+	if _, err := s.Transactions.Get(transaction.ID, other.ID, timeout); !errors.Is(err, data.ErrRecordNotFound) {
+		return fmt.Errorf("get transaction of another group: got error %v, want %v", err, data.ErrRecordNotFound)
+	}
+
+	stale := *transaction
+
+	transaction.Title = "Dinner Out"
+	transaction.Category = "Food"
+	transaction.Date = model.NewDate(2024, time.May, 2)
+	transaction.Payments = []data.Payment{{Payer: "Paulomi", Amount: 25.5}, {Payer: "Soumik", Amount: -25.5}}
+	if err := s.Transactions.Update(transaction, timeout); err != nil {
+		return fmt.Errorf("update transaction: %w", err)
+	}
+
+	if transaction.Version != 2 {
+		return fmt.Errorf("updated transaction has version %d, want 2", transaction.Version)
+	}
+
+	got, err = s.Transactions.Get(transaction.ID, group.ID, timeout)
+	if err != nil {
+		return fmt.Errorf("get transaction: %w", err)
+	}
+
+	if !sameTransaction(got, transaction) {
+		return fmt.Errorf("got transaction %+v, want %+v", got, transaction)
+	}
+
+	if err := expectErr("update stale transaction", s.Transactions.Update(&stale, timeout), data.ErrEditConflict); err != nil {
+		return err
+	}
+
+	if err := expectErr("delete transaction of another group", s.Transactions.Delete(transaction.ID, other.ID, timeout), data.ErrRecordNotFound); err != nil {
+		return err
+	}
+
+	if err := s.Transactions.Delete(transaction.ID, group.ID, timeout); err != nil {
+		return fmt.Errorf("delete transaction: %w", err)
+	}
+
+	return expectErr("delete deleted transaction", s.Transactions.Delete(transaction.ID, group.ID, timeout), data.ErrRecordNotFound)
+}
+
+func checkClientIDs(s Stores) error {
+	group, err := newGroup(s)
+	if err != nil {
+		return err
+	}
+
+	clientID := fmt.Sprintf("0b6f1c2e-8d3a-4f5b-9c7d-%012x", time.Now().UnixNano()&0xffffffffffff)
+
+	transaction := &data.Transaction{
+		Title:    "Offline",
+		Payments: []data.Payment{{Payer: "Soumik", Amount: 5}, {Payer: "Paulomi", Amount: -5}},
+		Date:     model.NewDate(2024, time.May, 1),
+		GroupID:  group.ID,
+		ClientID: clientID,
+	}
+	if err := s.Transactions.Insert(transaction, timeout); err != nil {
+		return fmt.Errorf("insert transaction: %w", err)
+	}
+
+	got, err := s.Transactions.GetByClientID(clientID, group.ID, timeout)
+	if err != nil {
+		return fmt.Errorf("get transaction by client id: %w", err)
+	}
+
+	if got.ID != transaction.ID || got.ClientID != clientID {
+		return fmt.Errorf("got transaction %+v by client id, want %+v", got, transaction)
+	}
+
+	duplicate := *transaction
+	if err := expectErr("insert duplicate client id", s.Transactions.Insert(&duplicate, timeout), data.ErrDuplicateClientID); err != nil {
+		return err
+	}
+
+	fresh := *transaction
+	fresh.ClientID = ""
+	batch := []*data.Transaction{&fresh, &duplicate}
+	if err := expectErr("insert all with duplicate client id", s.Transactions.InsertAll(batch, timeout), data.ErrDuplicateClientID); err != nil {
+		return err
+	}
+
+	// A client ID used twice within one batch fails the whole batch.
+	repeated := fmt.Sprintf("0b6f1c2e-8d3a-4f5b-9c7d-%012x", (time.Now().UnixNano()+1)&0xffffffffffff)
+	first, second := *transaction, *transaction
+	first.ClientID, second.ClientID = repeated, repeated
+	batch = []*data.Transaction{&first, &second}
+	if err := expectErr("insert all with repeated client id", s.Transactions.InsertAll(batch, timeout), data.ErrDuplicateClientID); err != nil {
+		return err
+	}
+
+	if _, err := s.Transactions.GetByClientID(repeated, group.ID, timeout); !errors.Is(err, data.ErrRecordNotFound) {
+		return fmt.Errorf("failed insert of a repeated client id left a transaction behind: %v", err)
+	}
+
+	return nil
+}
+
+func checkInsertAll(s Stores) error {
+	group, err := newGroup(s)
+	if err != nil {
+		return err
+	}
+
+	archived, err := newGroup(s)
+	if err != nil {
+		return err
+	}
+
+	archivedAt := time.Now()
+	if err := s.Groups.SetArchived(archived, &archivedAt, timeout); err != nil {
+		return fmt.Errorf("archive group: %w", err)
+	}
+
+	transactions := []*data.Transaction{
+		{Title: "First", Payments: []data.Payment{{Payer: "Soumik", Amount: 1}, {Payer: "Paulomi", Amount: -1}}, Date: model.NewDate(2024, time.May, 1), GroupID: group.ID},
+		{Title: "Second", Payments: []data.Payment{{Payer: "Soumik", Amount: 1}, {Payer: "Paulomi", Amount: -1}}, Date: model.NewDate(2024, time.May, 1), GroupID: archived.ID},
+	}
+
+	if err := s.Transactions.InsertAll(transactions, timeout); err == nil {
+		return errors.New("inserting into an archived group succeeded")
+	}
+
+	all, err := s.Transactions.GetAllAfterID(0, group.ID, timeout)
+	if err != nil {
+		return fmt.Errorf("get transactions: %w", err)
+	}
+
+	if len(*all) != 0 {
+		return fmt.Errorf("failed insert left %d transactions behind", len(*all))
+	}
+
+	transactions[1].GroupID = group.ID
+	if err := s.Transactions.InsertAll(transactions, timeout); err != nil {
+		return fmt.Errorf("insert transactions: %w", err)
+	}
+
+	all, err = s.Transactions.GetAllAfterID(0, group.ID, timeout)
+	if err != nil {
+		return fmt.Errorf("get transactions: %w", err)
+	}
+
+	if len(*all) != 2 {
+		return fmt.Errorf("got %d transactions, want 2", len(*all))
+	}
+
+	return nil
+}
+
+func checkStreams(s Stores) error {
+	group, err := newGroup(s)
+	if err != nil {
+		return err
+	}
+
+	var ids []int64
+	for _, day := range []int{3, 1, 2, 1} {
+		transaction, err := newTransaction(s, group, fmt.Sprintf("Day %d", day), model.NewDate(2024, time.May, day))
+		if err != nil {
+			return err
+		}
+		ids = append(ids, transaction.ID)
+	}
+
+	var afterFirst []int64
+	err = s.Transactions.StreamAllAfterID(ids[0], group.ID, timeout, func(t *data.Transaction) error {
+		afterFirst = append(afterFirst, t.ID)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("stream transactions after id: %w", err)
+	}
+
+	if want := ids[1:]; !reflect.DeepEqual(afterFirst, want) {
+		return fmt.Errorf("streamed ids %v after id %d, want %v", afterFirst, ids[0], want)
+	}
+
+	var beforeThird []int64
+	err = s.Transactions.StreamAllBefore(model.NewDate(2024, time.May, 3), group.ID, timeout, func(t *data.Transaction) error {
+		beforeThird = append(beforeThird, t.ID)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("stream transactions before date: %w", err)
+	}
+
+	if want := []int64{ids[1], ids[3], ids[2]}; !reflect.DeepEqual(beforeThird, want) {
+		return fmt.Errorf("streamed ids %v before date, want %v", beforeThird, want)
+	}
+
+	return nil
+}
+
+func checkMerge(s Stores) error {
+	for _, action := range []data.MergeAction{data.MergeDelete, data.MergeArchive} {
+		target, err := newGroup(s)
+		if err != nil {
+			return err
+		}
+
+		source, err := newGroup(s, "Soumik", "Rohan")
+		if err != nil {
+			return err
+		}
+
+		transaction, err := newTransaction(s, source, "Taxi", model.NewDate(2024, time.May, 1))
+		if err != nil {
+			return err
+		}
+
+		target.Users = append(target.Users, "Rohan Das")
+
+		moved, err := s.Groups.Merge(target, source, map[string]string{"Rohan": "Rohan Das"}, action, timeout)
+		if err != nil {
+			return fmt.Errorf("merge with %s: %w", action, err)
+		}
+
+		if len(moved) != 1 || moved[0].ID != transaction.ID || moved[0].GroupID != target.ID {
+			return fmt.Errorf("merge with %s moved %+v, want the transaction in the target", action, moved)
+		}
+
+		got, err := s.Transactions.Get(transaction.ID, target.ID, timeout)
+		if err != nil {
+			return fmt.Errorf("get moved transaction: %w", err)
+		}
+
+		want := []data.Payment{{Payer: "Soumik", Amount: 10}, {Payer: "Rohan Das", Amount: -10}}
+		if !reflect.DeepEqual(got.Payments, want) {
+			return fmt.Errorf("moved transaction has payments %+v, want %+v", got.Payments, want)
+		}
+
+		if !reflect.DeepEqual(moved[0].Payments, want) || moved[0].Version != got.Version {
+			return fmt.Errorf("merge with %s returned %+v, want it as stored: %+v", action, moved[0], got)
+		}
+
+		stored, err := s.Groups.GetByIDAndToken(source.ID, source.Token, timeout)
+		switch action {
+		case data.MergeArchive:
+			if err != nil || !stored.Archived() {
+				return fmt.Errorf("source of merge with %s is %+v with error %v, want it archived", action, stored, err)
+			}
+		default:
+			if !errors.Is(err, data.ErrRecordNotFound) {
+				return fmt.Errorf("get source of merge with %s: got error %v, want %v", action, err, data.ErrRecordNotFound)
+			}
+		}
+	}
+
+	return nil
+}
+
+func containsID(ids []int64, id int64) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+// sameTransaction compares what the stores keep, leaving out the times set
+// by them, which differ in precision.
+func sameTransaction(a, b *data.Transaction) bool {
+	return a.ID == b.ID && a.Title == b.Title && a.Category == b.Category && reflect.DeepEqual(a.Payments, b.Payments) &&
+		a.Date.Equal(b.Date.Time) && a.GroupID == b.GroupID && a.ClientID == b.ClientID && a.Version == b.Version
+}
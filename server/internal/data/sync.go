@@ -0,0 +1,68 @@
+package data
+
+import (
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/soumikc1729/splitty/server/internal/validator"
+)
+
+var (
+	UUIDRX = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+)
+
+func ValidateClientID(v *validator.Validator, key, clientID string) {
+	v.Check(validator.Matches(clientID, UUIDRX), key, "must be a lowercase UUID")
+}
+
+// MergeTransaction merges the changes a client made to base while offline,
+// resulting in mine, into current, the transaction as it is now. Fields are
+// merged one by one, with payments counting as a single field:
+//
+//   - a field only one side changed takes the changed value,
+//   - a field both sides changed to the same value takes that value,
+//   - a field both sides changed differently takes the value of the side that
+//     changed last, comparing modifiedAt of the client with the time current
+//     was last updated. The server wins ties.
+//
+// Without a base every field counts as changed by both sides. The fields that
+// both sides changed differently are returned as conflicts.
+func MergeTransaction(base, current, mine *Transaction, modifiedAt time.Time) (*Transaction, []string) {
+	merged := *current
+	conflicts := []string{}
+
+	mineWins := modifiedAt.After(current.UpdatedAt)
+
+	mergeField := func(name string, equal func(a, b *Transaction) bool, take func(from *Transaction)) {
+		mineChanged := base == nil || !equal(base, mine)
+		currentChanged := base == nil || !equal(base, current)
+
+		switch {
+		case !mineChanged:
+		case !currentChanged:
+			take(mine)
+		case equal(mine, current):
+		default:
+			conflicts = append(conflicts, name)
+			if mineWins {
+				take(mine)
+			}
+		}
+	}
+
+	mergeField("title",
+		func(a, b *Transaction) bool { return a.Title == b.Title },
+		func(from *Transaction) { merged.Title = from.Title })
+	mergeField("category",
+		func(a, b *Transaction) bool { return a.Category == b.Category },
+		func(from *Transaction) { merged.Category = from.Category })
+	mergeField("date",
+		func(a, b *Transaction) bool { return a.Date.Equal(b.Date.Time) },
+		func(from *Transaction) { merged.Date = from.Date })
+	mergeField("payments",
+		func(a, b *Transaction) bool { return slices.Equal(a.Payments, b.Payments) },
+		func(from *Transaction) { merged.Payments = from.Payments })
+
+	return &merged, conflicts
+}
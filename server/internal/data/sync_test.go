@@ -0,0 +1,146 @@
+package data_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/model"
+)
+
+func TestMergeTransaction(t *testing.T) {
+	updatedAt := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+
+	dinner := func(change func(*data.Transaction)) *data.Transaction {
+		transaction := &data.Transaction{
+			ID:        1,
+			Title:     "Dinner",
+			Category:  "Food",
+			Date:      model.NewDate(2024, 5, 1),
+			Payments:  []data.Payment{{Payer: "alice", Amount: 15}, {Payer: "bob", Amount: -15}},
+			UpdatedAt: updatedAt,
+		}
+		if change != nil {
+			change(transaction)
+		}
+		return transaction
+	}
+
+	retitle := func(title string) func(*data.Transaction) {
+		return func(tr *data.Transaction) { tr.Title = title }
+	}
+
+	tests := []struct {
+		name          string
+		base          *data.Transaction
+		current       *data.Transaction
+		mine          *data.Transaction
+		modifiedAt    time.Time
+		wantTitle     string
+		wantCategory  string
+		wantConflicts []string
+	}{
+		{
+			name:          "only mine changed",
+			base:          dinner(nil),
+			current:       dinner(nil),
+			mine:          dinner(retitle("Team dinner")),
+			wantTitle:     "Team dinner",
+			wantCategory:  "Food",
+			wantConflicts: []string{},
+		},
+		{
+			name:          "only current changed",
+			base:          dinner(nil),
+			current:       dinner(retitle("Team dinner")),
+			mine:          dinner(nil),
+			modifiedAt:    updatedAt.Add(time.Hour),
+			wantTitle:     "Team dinner",
+			wantCategory:  "Food",
+			wantConflicts: []string{},
+		},
+		{
+			name:          "different fields changed",
+			base:          dinner(nil),
+			current:       dinner(retitle("Team dinner")),
+			mine:          dinner(func(tr *data.Transaction) { tr.Category = "Restaurants" }),
+			wantTitle:     "Team dinner",
+			wantCategory:  "Restaurants",
+			wantConflicts: []string{},
+		},
+		{
+			name:          "same change on both sides",
+			base:          dinner(nil),
+			current:       dinner(retitle("Team dinner")),
+			mine:          dinner(retitle("Team dinner")),
+			wantTitle:     "Team dinner",
+			wantCategory:  "Food",
+			wantConflicts: []string{},
+		},
+		{
+			name:          "mine changed last",
+			base:          dinner(nil),
+			current:       dinner(retitle("Team dinner")),
+			mine:          dinner(retitle("Late dinner")),
+			modifiedAt:    updatedAt.Add(time.Minute),
+			wantTitle:     "Late dinner",
+			wantCategory:  "Food",
+			wantConflicts: []string{"title"},
+		},
+		{
+			name:          "current changed last",
+			base:          dinner(nil),
+			current:       dinner(retitle("Team dinner")),
+			mine:          dinner(retitle("Late dinner")),
+			modifiedAt:    updatedAt.Add(-time.Minute),
+			wantTitle:     "Team dinner",
+			wantCategory:  "Food",
+			wantConflicts: []string{"title"},
+		},
+		{
+			name:          "tie goes to the server",
+			base:          dinner(nil),
+			current:       dinner(retitle("Team dinner")),
+			mine:          dinner(retitle("Late dinner")),
+			modifiedAt:    updatedAt,
+			wantTitle:     "Team dinner",
+			wantCategory:  "Food",
+			wantConflicts: []string{"title"},
+		},
+		{
+			name:          "stale base without modified_at",
+			base:          dinner(nil),
+			current:       dinner(retitle("Team dinner")),
+			mine:          dinner(func(tr *data.Transaction) { tr.Title, tr.Category = "Late dinner", "Restaurants" }),
+			wantTitle:     "Team dinner",
+			wantCategory:  "Restaurants",
+			wantConflicts: []string{"title"},
+		},
+		{
+			name:          "no base",
+			current:       dinner(retitle("Team dinner")),
+			mine:          dinner(retitle("Late dinner")),
+			modifiedAt:    updatedAt.Add(time.Minute),
+			wantTitle:     "Late dinner",
+			wantCategory:  "Food",
+			wantConflicts: []string{"title"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, conflicts := data.MergeTransaction(tt.base, tt.current, tt.mine, tt.modifiedAt)
+
+			if merged.Title != tt.wantTitle || merged.Category != tt.wantCategory {
+				t.Errorf("got title %q and category %q, want %q and %q", merged.Title, merged.Category, tt.wantTitle, tt.wantCategory)
+			}
+			if !slices.Equal(conflicts, tt.wantConflicts) {
+				t.Errorf("got conflicts %v, want %v", conflicts, tt.wantConflicts)
+			}
+			if merged.ID != tt.current.ID || len(merged.Payments) != 2 {
+				t.Errorf("got %+v, want the other fields of current", merged)
+			}
+		})
+	}
+}
@@ -11,31 +11,22 @@ import (
 	"github.com/soumikc1729/splitty/server/internal/validator"
 )
 
-type Payment struct {
-	Amount float64 `json:"amount"`
-	Payer  string  `json:"payer"`
-}
-
-type Transaction struct {
-	ID       int64     `json:"id"`
-	Title    string    `json:"title"`
-	Payments []Payment `json:"payments"`
-	GroupID  int64     `json:"group_id"`
-	Version  int       `json:"-"`
-}
-
 func ValidateTransaction(v *validator.Validator, transaction *Transaction, group *Group) {
 	v.Check(validator.Matches(transaction.Title, ShortTextRX), "title", "must be 3-50 characters long and contain only letters, numbers, spaces, hyphens, and underscores")
+	v.Check(transaction.Category == "" || validator.Matches(transaction.Category, ShortTextRX), "category", "must be 3-50 characters long and contain only letters, numbers, spaces, hyphens, and underscores")
 
 	var payers []string
-	var amount float64
+	var units int64
 	for _, p := range transaction.Payments {
 		v.Check(validator.In(p.Payer, group.Users...), "payments", fmt.Sprintf("%s not one of the group users", p.Payer))
+		v.Check(group.Settings.HasPrecision(p.Amount), "payments", fmt.Sprintf("amounts must have at most %d decimals", group.Settings.Precision))
 		payers = append(payers, p.Payer)
-		amount += p.Amount
+		units += group.Settings.Units(p.Amount)
 	}
 	v.Check(validator.Unique(payers), "payments", "must not contain duplicate payers")
-	v.Check(amount == 0, "payments", "sum of all payments must be 0")
+	v.Check(units == 0, "payments", "sum of all payments must be 0")
+
+	v.Check(transaction.Date.Year() >= 1970 && transaction.Date.Year() <= 9999, "date", "must be a date between 1970 and 9999")
 
 	v.Check(transaction.GroupID == group.ID, "group_id", "must be same as the id of the group")
 }
@@ -46,9 +37,9 @@ type TransactionModel struct {
 
 func (t *TransactionModel) Insert(transaction *Transaction, timeout time.Duration) error {
 	query := `
-        INSERT INTO transactions (title, payments, group_id)
-        VALUES ($1, $2, $3)
-        RETURNING id, version`
+        INSERT INTO transactions (title, category, payments, date, group_id, client_id)
+        VALUES ($1, $2, $3, $4, $5, NULLIF($6, '')::uuid)
+        RETURNING id, created_at, updated_at, version`
 
 	paymentsJSON, err := json.Marshal(transaction.Payments)
 	if err != nil {
@@ -58,25 +49,71 @@ func (t *TransactionModel) Insert(transaction *Transaction, timeout time.Duratio
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	args := []interface{}{transaction.Title, paymentsJSON, transaction.GroupID}
+	args := []interface{}{transaction.Title, transaction.Category, paymentsJSON, transaction.Date, transaction.GroupID, transaction.ClientID}
+
+	err = t.DB.QueryRowContext(ctx, query, args...).Scan(&transaction.ID, &transaction.CreatedAt, &transaction.UpdatedAt, &transaction.Version)
+	return clientIDError(groupWriteError(err))
+}
+
+func (t *TransactionModel) InsertAll(transactions []*Transaction, timeout time.Duration) error {
+	query := `
+        INSERT INTO transactions (title, category, payments, date, group_id, client_id)
+        VALUES ($1, $2, $3, $4, $5, NULLIF($6, '')::uuid)
+        RETURNING id, created_at, updated_at, version`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	tx, err := t.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, transaction := range transactions {
+		paymentsJSON, err := json.Marshal(transaction.Payments)
+		if err != nil {
+			return err
+		}
+
+		args := []interface{}{transaction.Title, transaction.Category, paymentsJSON, transaction.Date, transaction.GroupID, transaction.ClientID}
 
-	return t.DB.QueryRowContext(ctx, query, args...).Scan(&transaction.ID, &transaction.Version)
+		err = tx.QueryRowContext(ctx, query, args...).Scan(&transaction.ID, &transaction.CreatedAt, &transaction.UpdatedAt, &transaction.Version)
+		if err != nil {
+			return clientIDError(groupWriteError(err))
+		}
+	}
+
+	return tx.Commit()
 }
 
 func (t *TransactionModel) Get(id int64, groupID int64, timeout time.Duration) (*Transaction, error) {
 	query := `
-		SELECT id, title, payments, group_id, version
+		SELECT id, title, category, payments, date, group_id, COALESCE(client_id::text, ''), created_at, updated_at, version
 		FROM transactions
 		WHERE id = $1 AND group_id = $2`
 
+	return t.get(query, []interface{}{id, groupID}, timeout)
+}
+
+func (t *TransactionModel) GetByClientID(clientID string, groupID int64, timeout time.Duration) (*Transaction, error) {
+	query := `
+		SELECT id, title, category, payments, date, group_id, COALESCE(client_id::text, ''), created_at, updated_at, version
+		FROM transactions
+		WHERE client_id = $1 AND group_id = $2`
+
+	return t.get(query, []interface{}{clientID, groupID}, timeout)
+}
+
+func (t *TransactionModel) get(query string, args []interface{}, timeout time.Duration) (*Transaction, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	row := t.DB.QueryRowContext(ctx, query, id, groupID)
+	row := t.DB.QueryRowContext(ctx, query, args...)
 	var transaction Transaction
 	var paymentsJSON []byte
 
-	err := row.Scan(&transaction.ID, &transaction.Title, &paymentsJSON, &transaction.GroupID, &transaction.Version)
+	err := row.Scan(&transaction.ID, &transaction.Title, &transaction.Category, &paymentsJSON, &transaction.Date, &transaction.GroupID, &transaction.ClientID, &transaction.CreatedAt, &transaction.UpdatedAt, &transaction.Version)
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
@@ -94,23 +131,49 @@ func (t *TransactionModel) Get(id int64, groupID int64, timeout time.Duration) (
 }
 
 func (t *TransactionModel) GetAllAfterID(id int64, groupID int64, timeout time.Duration) (*[]Transaction, error) {
+	var transactions []Transaction
+
+	err := t.StreamAllAfterID(id, groupID, timeout, func(transaction *Transaction) error {
+		transactions = append(transactions, *transaction)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &transactions, nil
+}
+
+func (t *TransactionModel) StreamAllAfterID(id int64, groupID int64, timeout time.Duration, fn func(*Transaction) error) error {
 	query := `
-        SELECT id, title, payments, group_id, version
+        SELECT id, title, category, payments, date, group_id, COALESCE(client_id::text, ''), created_at, updated_at, version
         FROM transactions
         WHERE id > $1 AND group_id = $2
         ORDER BY id ASC`
 
+	return t.stream(query, []interface{}{id, groupID}, timeout, fn)
+}
+
+func (t *TransactionModel) StreamAllBefore(before Date, groupID int64, timeout time.Duration, fn func(*Transaction) error) error {
+	query := `
+        SELECT id, title, category, payments, date, group_id, COALESCE(client_id::text, ''), created_at, updated_at, version
+        FROM transactions
+        WHERE date < $1 AND group_id = $2
+        ORDER BY date ASC, id ASC`
+
+	return t.stream(query, []interface{}{before, groupID}, timeout, fn)
+}
+
+func (t *TransactionModel) stream(query string, args []interface{}, timeout time.Duration, fn func(*Transaction) error) error {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	rows, err := t.DB.QueryContext(ctx, query, id, groupID)
+	rows, err := t.DB.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	var transactions []Transaction
-
 	for rows.Next() {
 		var transaction Transaction
 		var paymentsJSON []byte
@@ -118,35 +181,38 @@ func (t *TransactionModel) GetAllAfterID(id int64, groupID int64, timeout time.D
 		err := rows.Scan(
 			&transaction.ID,
 			&transaction.Title,
+			&transaction.Category,
 			&paymentsJSON,
+			&transaction.Date,
 			&transaction.GroupID,
+			&transaction.ClientID,
+			&transaction.CreatedAt,
+			&transaction.UpdatedAt,
 			&transaction.Version,
 		)
 
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		if err = json.Unmarshal(paymentsJSON, &transaction.Payments); err != nil {
-			return nil, err
+			return err
 		}
 
-		transactions = append(transactions, transaction)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, err
+		if err = fn(&transaction); err != nil {
+			return err
+		}
 	}
 
-	return &transactions, nil
+	return rows.Err()
 }
 
 func (t *TransactionModel) Update(transaction *Transaction, timeout time.Duration) error {
 	query := `
         UPDATE transactions
-        SET title = $1, payments = $2, version = version + 1
-        WHERE id = $3 AND group_id = $4 AND version = $5
-        RETURNING version`
+        SET title = $1, category = $2, payments = $3, date = $4, updated_at = NOW(), version = version + 1
+        WHERE id = $5 AND group_id = $6 AND version = $7
+        RETURNING updated_at, version`
 
 	paymentsJSON, err := json.Marshal(transaction.Payments)
 	if err != nil {
@@ -156,14 +222,14 @@ func (t *TransactionModel) Update(transaction *Transaction, timeout time.Duratio
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	args := []interface{}{transaction.Title, paymentsJSON, transaction.ID, transaction.GroupID, transaction.Version}
+	args := []interface{}{transaction.Title, transaction.Category, paymentsJSON, transaction.Date, transaction.ID, transaction.GroupID, transaction.Version}
 
-	err = t.DB.QueryRowContext(ctx, query, args...).Scan(&transaction.Version)
+	err = t.DB.QueryRowContext(ctx, query, args...).Scan(&transaction.UpdatedAt, &transaction.Version)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return ErrEditConflict
 		}
-		return err
+		return groupWriteError(err)
 	}
 
 	return nil
@@ -179,7 +245,7 @@ func (t *TransactionModel) Delete(id int64, groupID int64, timeout time.Duration
 
 	result, err := t.DB.ExecContext(ctx, query, id, groupID)
 	if err != nil {
-		return err
+		return groupWriteError(err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
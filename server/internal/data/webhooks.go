@@ -0,0 +1,358 @@
+package data
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/soumikc1729/splitty/server/internal/events"
+	"github.com/soumikc1729/splitty/server/internal/validator"
+	"github.com/soumikc1729/splitty/server/internal/webhook"
+)
+
+// GenerateWebhookSecret returns a random secret for signing the payloads of a
+// webhook whose subscriber did not choose one.
+func GenerateWebhookSecret() string {
+	secret := make([]byte, 32)
+	rand.Read(secret)
+	return hex.EncodeToString(secret)
+}
+
+func ValidateWebhook(v *validator.Validator, webhook *Webhook) {
+	u, err := url.Parse(webhook.URL)
+	v.Check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", "url", "must be an absolute http or https URL")
+	v.Check(err != nil || publicHost(u.Hostname()), "url", "must not point to a loopback, link-local or private address")
+	v.Check(len(webhook.URL) <= 2048, "url", "must not be more than 2048 bytes long")
+
+	v.Check(len(webhook.Secret) >= 16, "secret", "must be at least 16 bytes long")
+	v.Check(len(webhook.Secret) <= 128, "secret", "must not be more than 128 bytes long")
+
+	v.Check(len(webhook.Events) > 0, "events", "must contain at least one value")
+	v.Check(validator.Unique(webhook.Events), "events", "must not contain duplicate values")
+	v.Check(validator.All(webhook.Events, func(e string) bool {
+		return validator.In(e, events.Types...)
+	}), "events", "each value must be a known event type")
+}
+
+// publicHost reports whether host may receive webhooks as far as can be told
+// before it is resolved. Hosts given by name are checked again when the
+// webhook is sent.
+func publicHost(host string) bool {
+	host = strings.ToLower(host)
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+
+	addr, err := netip.ParseAddr(host)
+	return err != nil || webhook.PublicAddr(addr)
+}
+
+type WebhookModel struct {
+	DB *sql.DB
+}
+
+func (m *WebhookModel) Insert(webhook *Webhook, timeout time.Duration) error {
+	query := `
+		INSERT INTO webhooks (group_id, url, secret, events)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, version`
+
+	args := []interface{}{webhook.GroupID, webhook.URL, webhook.Secret, pq.Array(webhook.Events)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query, args...).Scan(&webhook.ID, &webhook.CreatedAt, &webhook.Version)
+}
+
+func (m *WebhookModel) Get(id int64, groupID int64, timeout time.Duration) (*Webhook, error) {
+	query := `
+		SELECT id, group_id, url, secret, events, created_at, version
+		FROM webhooks
+		WHERE id = $1 AND group_id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var webhook Webhook
+
+	err := m.DB.QueryRowContext(ctx, query, id, groupID).Scan(&webhook.ID, &webhook.GroupID, &webhook.URL, &webhook.Secret, pq.Array(&webhook.Events), &webhook.CreatedAt, &webhook.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &webhook, nil
+}
+
+// GetAll lists the webhooks of the group without their secrets.
+func (m *WebhookModel) GetAll(groupID int64, timeout time.Duration) ([]Webhook, error) {
+	query := `
+		SELECT id, group_id, url, events, created_at, version
+		FROM webhooks
+		WHERE group_id = $1
+		ORDER BY id ASC`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []Webhook{}
+
+	for rows.Next() {
+		var webhook Webhook
+
+		if err := rows.Scan(&webhook.ID, &webhook.GroupID, &webhook.URL, pq.Array(&webhook.Events), &webhook.CreatedAt, &webhook.Version); err != nil {
+			return nil, err
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func (m *WebhookModel) Update(webhook *Webhook, timeout time.Duration) error {
+	query := `
+		UPDATE webhooks
+		SET url = $1, secret = $2, events = $3, version = version + 1
+		WHERE id = $4 AND group_id = $5 AND version = $6
+		RETURNING version`
+
+	args := []interface{}{webhook.URL, webhook.Secret, pq.Array(webhook.Events), webhook.ID, webhook.GroupID, webhook.Version}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&webhook.Version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *WebhookModel) Delete(id int64, groupID int64, timeout time.Duration) error {
+	query := `
+		DELETE FROM webhooks
+		WHERE id = $1 AND group_id = $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id, groupID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+var (
+	DeliveryStatuses = []string{string(DeliveryPending), string(DeliverySucceeded), string(DeliveryFailed)}
+)
+
+type DeliveryModel struct {
+	DB *sql.DB
+}
+
+// Enqueue queues a delivery of the event to every webhook of the group that
+// subscribed to its type and returns how many were queued.
+func (m *DeliveryModel) Enqueue(event *events.Event, timeout time.Duration) (int64, error) {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_type, payload)
+		SELECT id, $2, $3
+		FROM webhooks
+		WHERE group_id = $1 AND $2 = ANY(events)`
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, event.GroupID, string(event.Type), payload)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// Claim returns up to limit pending deliveries that are due and postpones
+// their next attempt by lease, so that no one else claims them while they
+// are being delivered.
+func (m *DeliveryModel) Claim(limit int, lease time.Duration, timeout time.Duration) ([]*Delivery, error) {
+	query := `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = NOW() + $2 * interval '1 millisecond'
+		FROM webhooks w
+		WHERE w.id = d.webhook_id AND d.id IN (
+			SELECT id
+			FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at ASC
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING d.id, d.webhook_id, d.event_type, d.payload, d.status, d.attempts, d.next_attempt_at,
+			d.response_status, d.last_error, d.created_at, d.updated_at, w.url, w.secret`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*Delivery{}
+
+	for rows.Next() {
+		var d Delivery
+
+		err := rows.Scan(&d.ID, &d.WebhookID, &d.EventType, &d.Payload, &d.Status, &d.Attempts, &d.NextAttemptAt,
+			&d.ResponseStatus, &d.LastError, &d.CreatedAt, &d.UpdatedAt, &d.URL, &d.Secret)
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, &d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// Record stores the outcome of an attempt to deliver.
+func (m *DeliveryModel) Record(delivery *Delivery, timeout time.Duration) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, next_attempt_at = $3, response_status = $4, last_error = $5, updated_at = NOW()
+		WHERE id = $6
+		RETURNING updated_at`
+
+	args := []interface{}{delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.ResponseStatus, delivery.LastError, delivery.ID}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&delivery.UpdatedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrRecordNotFound
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteFinishedBefore deletes the deliveries that succeeded or failed for
+// good before t, and returns how many were deleted.
+func (m *DeliveryModel) DeleteFinishedBefore(t time.Time, timeout time.Duration) (int64, error) {
+	query := `
+		DELETE FROM webhook_deliveries
+		WHERE status <> 'pending' AND updated_at < $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, t)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// GetAllForWebhook returns the latest deliveries of the webhook, newest first,
+// optionally only those with the given status.
+func (m *DeliveryModel) GetAllForWebhook(webhookID, groupID int64, status string, limit int, timeout time.Duration) ([]Delivery, error) {
+	query := `
+		SELECT d.id, d.webhook_id, d.event_type, d.payload, d.status, d.attempts, d.next_attempt_at,
+			d.response_status, d.last_error, d.created_at, d.updated_at
+		FROM webhook_deliveries d
+		JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.webhook_id = $1 AND w.group_id = $2 AND (d.status = $3 OR $3 = '')
+		ORDER BY d.id DESC
+		LIMIT $4`
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, webhookID, groupID, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []Delivery{}
+
+	for rows.Next() {
+		var d Delivery
+
+		err := rows.Scan(&d.ID, &d.WebhookID, &d.EventType, &d.Payload, &d.Status, &d.Attempts, &d.NextAttemptAt,
+			&d.ResponseStatus, &d.LastError, &d.CreatedAt, &d.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,91 @@
+package events
+
+import (
+	"sync"
+)
+
+// Broker fans events out to the subscribers of their group within this
+// process.
+type Broker struct {
+	bufferSize int
+
+	mu          sync.Mutex
+	subscribers map[int64]map[*Subscription]struct{}
+}
+
+// Subscription receives the events of a group on C. C is closed when the
+// subscriber falls so far behind that its buffer is full, after which it has
+// to catch up from the stored events.
+type Subscription struct {
+	C <-chan *Event
+
+	c       chan *Event
+	groupID int64
+	broker  *Broker
+}
+
+func NewBroker(bufferSize int) *Broker {
+	return &Broker{bufferSize: bufferSize, subscribers: make(map[int64]map[*Subscription]struct{})}
+}
+
+func (b *Broker) Subscribe(groupID int64) *Subscription {
+	c := make(chan *Event, b.bufferSize)
+	sub := &Subscription{C: c, c: c, groupID: groupID, broker: b}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.subscribers[groupID] == nil {
+		b.subscribers[groupID] = make(map[*Subscription]struct{})
+	}
+	b.subscribers[groupID][sub] = struct{}{}
+
+	return sub
+}
+
+func (b *Broker) HasSubscribers(groupID int64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.subscribers[groupID]) > 0
+}
+
+// Publish passes the event to every subscriber of its group without blocking.
+func (b *Broker) Publish(event *Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subscribers[event.GroupID] {
+		select {
+		case sub.c <- event:
+		default:
+			b.remove(sub)
+		}
+	}
+}
+
+// Close ends the subscription. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+
+	s.broker.remove(s)
+}
+
+func (b *Broker) remove(sub *Subscription) {
+	subs, ok := b.subscribers[sub.groupID]
+	if !ok {
+		return
+	}
+
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+
+	delete(subs, sub)
+	close(sub.c)
+
+	if len(subs) == 0 {
+		delete(b.subscribers, sub.groupID)
+	}
+}
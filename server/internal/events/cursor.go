@@ -0,0 +1,55 @@
+package events
+
+// GapWindow is how far behind the highest event ID read an event may still
+// appear. IDs are taken when an event is inserted, but the event can only be
+// read once the insert commits, so a later event can be read first. Each
+// database connection inserts one event at a time, so the window only has to
+// be larger than the connections of every instance together.
+const GapWindow = 1000
+
+// Cursor follows a stream of events that may appear out of order. It
+// remembers the IDs it was given within GapWindow of the highest one, so that
+// the stored events can be read again from Resume with those already handled
+// skipped.
+type Cursor struct {
+	start int64
+	high  int64
+	seen  map[int64]struct{}
+}
+
+// NewCursor returns a cursor that reads the events stored after afterID.
+func NewCursor(afterID int64) *Cursor {
+	return &Cursor{
+		start: afterID,
+		high:  afterID,
+		seen:  make(map[int64]struct{}),
+	}
+}
+
+// Add records the ID of an event and reports whether it was new to the
+// cursor.
+func (c *Cursor) Add(id int64) bool {
+	if _, ok := c.seen[id]; ok {
+		return false
+	}
+
+	c.seen[id] = struct{}{}
+	c.high = max(c.high, id)
+
+	if len(c.seen) > GapWindow {
+		for seen := range c.seen {
+			if seen <= c.high-GapWindow {
+				delete(c.seen, seen)
+			}
+		}
+	}
+
+	return true
+}
+
+// Resume returns the ID after which the stored events have to be read again
+// to find those that appeared late, which is GapWindow behind the highest ID
+// added but never before the start of the cursor.
+func (c *Cursor) Resume() int64 {
+	return max(c.start, c.high-GapWindow)
+}
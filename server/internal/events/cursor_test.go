@@ -0,0 +1,46 @@
+package events
+
+import "testing"
+
+func TestCursor(t *testing.T) {
+	c := NewCursor(10)
+
+	if got := c.Resume(); got != 10 {
+		t.Errorf("got resume %d before any event, want the start", got)
+	}
+
+	for _, tt := range []struct {
+		id   int64
+		want bool
+	}{
+		{12, true},
+		{12, false},
+		// 11 was stored before 12 but only appeared after it.
+		{11, true},
+		{11, false},
+		{13, true},
+	} {
+		if got := c.Add(tt.id); got != tt.want {
+			t.Errorf("Add(%d) = %t, want %t", tt.id, got, tt.want)
+		}
+	}
+
+	if got := c.Resume(); got != 10 {
+		t.Errorf("got resume %d within the window, want the start", got)
+	}
+
+	high := int64(10 + 3*GapWindow)
+	for id := int64(14); id <= high; id++ {
+		c.Add(id)
+	}
+
+	if got, want := c.Resume(), high-GapWindow; got != want {
+		t.Errorf("got resume %d, want %d", got, want)
+	}
+	if len(c.seen) > GapWindow+1 {
+		t.Errorf("remembers %d IDs, want at most %d", len(c.seen), GapWindow+1)
+	}
+	if c.Add(high - 1) {
+		t.Errorf("Add(%d) = true within the window after pruning", high-1)
+	}
+}
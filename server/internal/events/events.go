@@ -0,0 +1,50 @@
+package events
+
+import (
+	"time"
+
+	"github.com/soumikc1729/splitty/server/model"
+)
+
+type Type = model.EventType
+
+const (
+	GroupCreated       Type = "group.created"
+	GroupUpdated       Type = "group.updated"
+	GroupDeleted       Type = "group.deleted"
+	TransactionCreated Type = "transaction.created"
+	TransactionUpdated Type = "transaction.updated"
+	TransactionDeleted Type = "transaction.deleted"
+)
+
+var (
+	Types = []string{
+		string(GroupCreated),
+		string(GroupUpdated),
+		string(GroupDeleted),
+		string(TransactionCreated),
+		string(TransactionUpdated),
+		string(TransactionDeleted),
+	}
+)
+
+type Event = model.Event
+
+func New(eventType Type, groupID int64, data interface{}) *Event {
+	return &Event{
+		Type:      eventType,
+		GroupID:   groupID,
+		Data:      data,
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+type Config struct {
+	HeartbeatInterval time.Duration `mapstructure:"heartbeat-interval"`
+	BufferSize        int           `mapstructure:"buffer-size"`
+	Retention         time.Duration `mapstructure:"retention"`
+
+	ListenerMinReconnect time.Duration `mapstructure:"listener-min-reconnect"`
+	ListenerMaxReconnect time.Duration `mapstructure:"listener-max-reconnect"`
+	ListenerPingInterval time.Duration `mapstructure:"listener-ping-interval"`
+}
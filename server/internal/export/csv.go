@@ -0,0 +1,102 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/soumikc1729/splitty/server/internal/balance"
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+type csvExporter struct {
+	writer  *csv.Writer
+	group   *data.Group
+	summary *balance.Summary
+	amount  func(float64) string
+	started bool
+}
+
+// newCSVExporter writes amounts the way they are written in the locale of the
+// group, without grouping separators. Spreadsheets in locales writing decimals
+// with a comma expect fields to be separated by semicolons, so those are used
+// instead.
+func newCSVExporter(w io.Writer, group *data.Group) *csvExporter {
+	printer := message.NewPrinter(language.Make(group.Settings.Locale))
+	amount := func(amount float64) string {
+		return printer.Sprint(number.Decimal(amount, number.NoSeparator(), number.MaxFractionDigits(group.Settings.Precision)))
+	}
+
+	writer := csv.NewWriter(w)
+	if strings.Contains(amount(0.5), ",") {
+		writer.Comma = ';'
+	}
+
+	return &csvExporter{
+		writer:  writer,
+		group:   group,
+		summary: balance.New(group.Users),
+		amount:  amount,
+	}
+}
+
+func (e *csvExporter) writeHeader() error {
+	e.started = true
+	header := append([]string{"id", "date", "title", "category"}, e.group.Users...)
+	return e.writer.Write(header)
+}
+
+func (e *csvExporter) Write(transaction *data.Transaction) error {
+	if !e.started {
+		if err := e.writeHeader(); err != nil {
+			return err
+		}
+	}
+
+	e.summary.Add(transaction)
+
+	amounts := amountsByPayer(transaction)
+	record := []string{strconv.FormatInt(transaction.ID, 10), transaction.Date.String(), transaction.Title, transaction.Category}
+	for _, user := range e.group.Users {
+		record = append(record, e.amount(amounts[user]))
+	}
+
+	if err := e.writer.Write(record); err != nil {
+		return err
+	}
+
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+func (e *csvExporter) Close() error {
+	if !e.started {
+		if err := e.writeHeader(); err != nil {
+			return err
+		}
+	}
+
+	records := [][]string{
+		{},
+		{"summary"},
+		{"transactions", strconv.Itoa(e.summary.Count)},
+		{"total", e.amount(e.summary.Total)},
+		{},
+		{"member", "paid", "consumed", "net"},
+	}
+
+	for _, user := range e.group.Users {
+		records = append(records, []string{
+			user,
+			e.amount(e.summary.Paid[user]),
+			e.amount(e.summary.Consumed[user]),
+			e.amount(e.summary.Net(user)),
+		})
+	}
+
+	return e.writer.WriteAll(records)
+}
@@ -0,0 +1,96 @@
+package export
+
+import (
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/model"
+)
+
+type Format = model.ExportFormat
+
+const (
+	CSV       Format = "csv"
+	XLSX      Format = "xlsx"
+	Ledger    Format = "ledger"
+	HLedger   Format = "hledger"
+	Beancount Format = "beancount"
+)
+
+const (
+	DefaultCommodity = "USD"
+)
+
+var (
+	Formats     = []string{string(CSV), string(XLSX), string(Ledger), string(HLedger), string(Beancount)}
+	CommodityRX = regexp.MustCompile(`^[A-Z][A-Z0-9'._-]{0,22}[A-Z0-9]$`)
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown export format")
+)
+
+type Options struct {
+	Commodity string
+}
+
+type Exporter interface {
+	Write(transaction *data.Transaction) error
+	Close() error
+}
+
+func New(format Format, w io.Writer, group *data.Group, opts Options) (Exporter, error) {
+	if opts.Commodity == "" {
+		opts.Commodity = DefaultCommodity
+	}
+
+	switch format {
+	case CSV:
+		return newCSVExporter(w, group), nil
+	case XLSX:
+		return newXLSXExporter(w, group)
+	case Ledger, HLedger:
+		return newLedgerExporter(w, group, opts)
+	case Beancount:
+		return newBeancountExporter(w, group, opts)
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+func ContentType(format Format) string {
+	switch format {
+	case CSV:
+		return "text/csv; charset=utf-8"
+	case XLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case Ledger, HLedger, Beancount:
+		return "text/plain; charset=utf-8"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+func Extension(format Format) string {
+	switch format {
+	case HLedger:
+		return "journal"
+	default:
+		return string(format)
+	}
+}
+
+func amountsByPayer(transaction *data.Transaction) map[string]float64 {
+	amounts := make(map[string]float64)
+	for _, p := range transaction.Payments {
+		amounts[p.Payer] += p.Amount
+	}
+	return amounts
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', -1, 64)
+}
@@ -0,0 +1,140 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+)
+
+const (
+	accountRoot = "Assets:Splitty"
+	openDate    = "1970-01-01"
+)
+
+type ledgerExporter struct {
+	writer    *bufio.Writer
+	accounts  map[string]string
+	commodity string
+	indent    string
+	beancount bool
+}
+
+func newLedgerExporter(w io.Writer, group *data.Group, opts Options) (*ledgerExporter, error) {
+	e := &ledgerExporter{
+		writer:    bufio.NewWriter(w),
+		accounts:  accountNames(group),
+		commodity: opts.Commodity,
+		indent:    "    ",
+	}
+
+	fmt.Fprintf(e.writer, "; splitty group %d: %s\n\n", group.ID, group.Name)
+	fmt.Fprintf(e.writer, "commodity %s\n", e.commodity)
+	for _, user := range group.Users {
+		fmt.Fprintf(e.writer, "account %s\n", e.accounts[user])
+	}
+
+	return e, e.flush()
+}
+
+func newBeancountExporter(w io.Writer, group *data.Group, opts Options) (*ledgerExporter, error) {
+	e := &ledgerExporter{
+		writer:    bufio.NewWriter(w),
+		accounts:  accountNames(group),
+		commodity: opts.Commodity,
+		indent:    "  ",
+		beancount: true,
+	}
+
+	fmt.Fprintf(e.writer, "; splitty group %d: %s\n\n", group.ID, group.Name)
+	fmt.Fprintf(e.writer, "option \"operating_currency\" \"%s\"\n\n", e.commodity)
+	for _, user := range group.Users {
+		fmt.Fprintf(e.writer, "%s open %s %s\n", openDate, e.accounts[user], e.commodity)
+	}
+
+	return e, e.flush()
+}
+
+func (e *ledgerExporter) Write(transaction *data.Transaction) error {
+	date := transaction.Date.String()
+
+	if e.beancount {
+		fmt.Fprintf(e.writer, "\n%s * \"%s\"\n", date, transaction.Title)
+		fmt.Fprintf(e.writer, "%ssplitty-id: %d\n", e.indent, transaction.ID)
+	} else {
+		fmt.Fprintf(e.writer, "\n%s %s\n", date, transaction.Title)
+		fmt.Fprintf(e.writer, "%s; splitty-id: %d\n", e.indent, transaction.ID)
+	}
+
+	for _, p := range transaction.Payments {
+		if p.Amount == 0 {
+			continue
+		}
+
+		account, ok := e.accounts[p.Payer]
+		if !ok {
+			account = accountRoot + ":" + accountComponent(p.Payer)
+		}
+
+		fmt.Fprintf(e.writer, "%s%s  %s %s\n", e.indent, account, formatAmount(p.Amount), e.commodity)
+	}
+
+	return e.flush()
+}
+
+func (e *ledgerExporter) Close() error {
+	return e.flush()
+}
+
+func (e *ledgerExporter) flush() error {
+	return e.writer.Flush()
+}
+
+// accountNames maps every group member to a stable account name, suffixing
+// members whose sanitized names would otherwise collide.
+func accountNames(group *data.Group) map[string]string {
+	prefix := accountRoot + ":" + accountComponent(group.Name)
+
+	accounts := make(map[string]string)
+	taken := make(map[string]bool)
+
+	for _, user := range group.Users {
+		account := prefix + ":" + accountComponent(user)
+		for i := 2; taken[account]; i++ {
+			account = fmt.Sprintf("%s:%s-%d", prefix, accountComponent(user), i)
+		}
+
+		taken[account] = true
+		accounts[user] = account
+	}
+
+	return accounts
+}
+
+// accountComponent turns a name into a single account segment that both
+// ledger and beancount accept: letters, digits and dashes, starting with an
+// uppercase letter or digit.
+func accountComponent(name string) string {
+	var b strings.Builder
+
+	for _, r := range name {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+		default:
+			b.WriteRune('-')
+		}
+	}
+
+	component := []rune(strings.Trim(b.String(), "-"))
+	if len(component) == 0 {
+		return "Unknown"
+	}
+
+	component[0] = unicode.ToUpper(component[0])
+
+	return string(component)
+}
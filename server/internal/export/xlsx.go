@@ -0,0 +1,122 @@
+package export
+
+import (
+	"io"
+
+	"github.com/soumikc1729/splitty/server/internal/balance"
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	transactionsSheet = "Transactions"
+	summarySheet      = "Summary"
+)
+
+type xlsxExporter struct {
+	out     io.Writer
+	file    *excelize.File
+	stream  *excelize.StreamWriter
+	group   *data.Group
+	summary *balance.Summary
+	row     int
+}
+
+func newXLSXExporter(w io.Writer, group *data.Group) (*xlsxExporter, error) {
+	file := excelize.NewFile()
+
+	if err := file.SetSheetName("Sheet1", transactionsSheet); err != nil {
+		return nil, err
+	}
+
+	stream, err := file.NewStreamWriter(transactionsSheet)
+	if err != nil {
+		return nil, err
+	}
+
+	header := []interface{}{"id", "date", "title", "category"}
+	for _, user := range group.Users {
+		header = append(header, user)
+	}
+
+	if err := stream.SetRow("A1", header); err != nil {
+		return nil, err
+	}
+
+	return &xlsxExporter{
+		out:     w,
+		file:    file,
+		stream:  stream,
+		group:   group,
+		summary: balance.New(group.Users),
+		row:     1,
+	}, nil
+}
+
+func (e *xlsxExporter) Write(transaction *data.Transaction) error {
+	e.summary.Add(transaction)
+	e.row++
+
+	amounts := amountsByPayer(transaction)
+	values := []interface{}{transaction.ID, transaction.Date.String(), transaction.Title, transaction.Category}
+	for _, user := range e.group.Users {
+		values = append(values, amounts[user])
+	}
+
+	cell, err := excelize.CoordinatesToCellName(1, e.row)
+	if err != nil {
+		return err
+	}
+
+	return e.stream.SetRow(cell, values)
+}
+
+func (e *xlsxExporter) Close() error {
+	defer e.file.Close()
+
+	if err := e.stream.Flush(); err != nil {
+		return err
+	}
+
+	if err := e.writeSummary(); err != nil {
+		return err
+	}
+
+	_, err := e.file.WriteTo(e.out)
+	return err
+}
+
+func (e *xlsxExporter) writeSummary() error {
+	if _, err := e.file.NewSheet(summarySheet); err != nil {
+		return err
+	}
+
+	stream, err := e.file.NewStreamWriter(summarySheet)
+	if err != nil {
+		return err
+	}
+
+	rows := [][]interface{}{
+		{"transactions", e.summary.Count},
+		{"total", e.summary.Total},
+		{},
+		{"member", "paid", "consumed", "net"},
+	}
+
+	for _, user := range e.group.Users {
+		rows = append(rows, []interface{}{user, e.summary.Paid[user], e.summary.Consumed[user], e.summary.Net(user)})
+	}
+
+	for i, values := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return err
+		}
+
+		if err := stream.SetRow(cell, values); err != nil {
+			return err
+		}
+	}
+
+	return stream.Flush()
+}
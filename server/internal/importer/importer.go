@@ -0,0 +1,292 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/model"
+)
+
+type Source string
+
+const (
+	Splitwise Source = "splitwise"
+	Tricount  Source = "tricount"
+)
+
+const (
+	fallbackTitle = "Imported expense"
+	tolerance     = 0.005
+)
+
+var (
+	// parseSettings round the amounts of parsed rows just enough to remove
+	// floating point noise. The rows are rounded to the settings of the group
+	// once they are imported.
+	parseSettings = model.Settings{Precision: 6}
+
+	Sources     = []string{string(Splitwise), string(Tricount)}
+	dateLayouts = []string{"2006-01-02", "2006-01-02 15:04", "2006-01-02 15:04:05", "02/01/2006", "02/01/2006 15:04", "02.01.2006", "02.01.2006 15:04"}
+)
+
+var (
+	ErrUnknownSource = errors.New("unknown import source")
+	ErrMissingColumn = errors.New("missing required column")
+	ErrNoMembers     = errors.New("no member columns found")
+
+	invalidTitleCharsRX = regexp.MustCompile(`[^a-zA-Z0-9 \-_]+`)
+	spacesRX            = regexp.MustCompile(`\s+`)
+)
+
+type record struct {
+	line   int
+	fields []string
+}
+
+type Row struct {
+	Line     int            `json:"line"`
+	Date     data.Date      `json:"date"`
+	Title    string         `json:"title"`
+	Payments []data.Payment `json:"payments"`
+}
+
+type Warning = model.Warning
+
+type Result struct {
+	Source   Source    `json:"source"`
+	Members  []string  `json:"members"`
+	Rows     []Row     `json:"rows"`
+	Warnings []Warning `json:"warnings"`
+}
+
+func Parse(source Source, r io.Reader) (*Result, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var records []record
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		records = append(records, record{line: line, fields: fields})
+	}
+
+	if len(records) == 0 {
+		return nil, errors.New("csv must not be empty")
+	}
+
+	result := &Result{Source: source, Rows: []Row{}, Warnings: []Warning{}}
+
+	var err error
+
+	switch source {
+	case Splitwise:
+		err = parseSplitwise(records, result)
+	case Tricount:
+		err = parseTricount(records, result)
+	default:
+		err = ErrUnknownSource
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// MapMembers renames every payer according to mapping. Payers that end up
+// with the same name are merged into a single payment.
+func (res *Result) MapMembers(mapping map[string]string) {
+	var members []string
+	for _, member := range res.Members {
+		if mapped, ok := mapping[member]; ok {
+			member = mapped
+		}
+
+		if !slices.Contains(members, member) {
+			members = append(members, member)
+		}
+	}
+	res.Members = members
+
+	for i := range res.Rows {
+		var payments []data.Payment
+		for _, p := range res.Rows[i].Payments {
+			if mapped, ok := mapping[p.Payer]; ok {
+				p.Payer = mapped
+			}
+			payments = append(payments, p)
+		}
+
+		res.Rows[i].Payments = Balance(parseSettings, mergePayers(payments))
+	}
+}
+
+func (res *Result) warn(line int, format string, args ...interface{}) {
+	res.Warnings = append(res.Warnings, Warning{Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+func (res *Result) addRow(line int, date data.Date, title string, payments []data.Payment) {
+	var sum float64
+	for _, p := range payments {
+		sum += p.Amount
+	}
+
+	if math.Abs(sum) > tolerance {
+		res.warn(line, "payments do not add up to zero (off by %.2f), skipping row", sum)
+		return
+	}
+
+	payments = Balance(parseSettings, payments)
+	if len(payments) < 2 {
+		res.warn(line, "row has no amounts to split, skipping row")
+		return
+	}
+
+	sanitized := sanitizeTitle(title)
+	if sanitized != title {
+		res.warn(line, "title %q was changed to %q", title, sanitized)
+	}
+
+	res.Rows = append(res.Rows, Row{Line: line, Date: date, Title: sanitized, Payments: payments})
+}
+
+// Balance rounds the amounts to the smallest unit of settings and drops zero
+// payments. What rounding leaves over is put on a single payment, the first
+// whose amount it makes larger, so that the amounts sum up to exactly zero
+// without any of them changing sign.
+func Balance(settings model.Settings, payments []data.Payment) []data.Payment {
+	var balanced []data.Payment
+	var units []int64
+	var total int64
+
+	for _, p := range payments {
+		u := settings.Units(p.Amount)
+		if u == 0 {
+			continue
+		}
+
+		balanced = append(balanced, p)
+		units = append(units, u)
+		total += u
+	}
+
+	if total != 0 {
+		i := slices.IndexFunc(units, func(u int64) bool { return (u < 0) == (total > 0) })
+		if i < 0 {
+			i = len(units) - 1
+		}
+		units[i] -= total
+	}
+
+	for i := range balanced {
+		balanced[i].Amount = settings.Amount(units[i])
+	}
+
+	return balanced
+}
+
+func mergePayers(payments []data.Payment) []data.Payment {
+	var merged []data.Payment
+	for _, p := range payments {
+		i := indexOfPayer(merged, p.Payer)
+		if i < 0 {
+			merged = append(merged, p)
+			continue
+		}
+		merged[i].Amount += p.Amount
+	}
+	return merged
+}
+
+func indexOfPayer(payments []data.Payment, payer string) int {
+	for i, p := range payments {
+		if p.Payer == payer {
+			return i
+		}
+	}
+	return -1
+}
+
+func sanitizeTitle(title string) string {
+	title = invalidTitleCharsRX.ReplaceAllString(title, " ")
+	title = strings.TrimSpace(spacesRX.ReplaceAllString(title, " "))
+
+	if len(title) > 50 {
+		title = strings.TrimSpace(title[:50])
+	}
+
+	if len(title) < 3 {
+		return fallbackTitle
+	}
+
+	return title
+}
+
+func parseAmount(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", ""), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+
+	return amount, nil
+}
+
+func parseDate(value string) (data.Date, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return model.NewDate(date.Year(), date.Month(), date.Day()), nil
+		}
+	}
+	return data.Date{}, fmt.Errorf("unrecognized date %q", value)
+}
+
+func columnIndex(header []string, names ...string) int {
+	for _, name := range names {
+		for i, column := range header {
+			if strings.EqualFold(strings.TrimSpace(column), name) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func (r record) field(index int) string {
+	if index < 0 || index >= len(r.fields) {
+		return ""
+	}
+	return strings.TrimSpace(r.fields[index])
+}
+
+func (r record) isBlank() bool {
+	for _, value := range r.fields {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
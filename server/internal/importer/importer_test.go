@@ -0,0 +1,72 @@
+package importer
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/model"
+)
+
+func TestBalance(t *testing.T) {
+	tests := []struct {
+		name      string
+		precision int
+		payments  []data.Payment
+		want      []data.Payment
+	}{
+		{
+			name:      "even",
+			precision: 2,
+			payments:  []data.Payment{{Payer: "Alice", Amount: 15.5}, {Payer: "Bob", Amount: -15.5}},
+			want:      []data.Payment{{Payer: "Alice", Amount: 15.5}, {Payer: "Bob", Amount: -15.5}},
+		},
+		{
+			name:      "uneven split",
+			precision: 2,
+			payments:  []data.Payment{{Payer: "Alice", Amount: 6.666667}, {Payer: "Bob", Amount: -3.333333}, {Payer: "Carol", Amount: -3.333334}},
+			want:      []data.Payment{{Payer: "Alice", Amount: 6.67}, {Payer: "Bob", Amount: -3.34}, {Payer: "Carol", Amount: -3.33}},
+		},
+		{
+			name:      "remainder on a payer",
+			precision: 2,
+			payments:  []data.Payment{{Payer: "Alice", Amount: -6.666667}, {Payer: "Bob", Amount: 3.333333}, {Payer: "Carol", Amount: 3.333334}},
+			want:      []data.Payment{{Payer: "Alice", Amount: -6.67}, {Payer: "Bob", Amount: 3.34}, {Payer: "Carol", Amount: 3.33}},
+		},
+		{
+			name:      "no decimals",
+			precision: 0,
+			payments:  []data.Payment{{Payer: "Alice", Amount: 100}, {Payer: "Bob", Amount: -33.33}, {Payer: "Carol", Amount: -33.33}, {Payer: "Dan", Amount: -33.34}},
+			want:      []data.Payment{{Payer: "Alice", Amount: 100}, {Payer: "Bob", Amount: -34}, {Payer: "Carol", Amount: -33}, {Payer: "Dan", Amount: -33}},
+		},
+		{
+			name:      "zero dropped",
+			precision: 0,
+			payments:  []data.Payment{{Payer: "Alice", Amount: 10}, {Payer: "Bob", Amount: -0.4}, {Payer: "Carol", Amount: -9.6}},
+			want:      []data.Payment{{Payer: "Alice", Amount: 10}, {Payer: "Carol", Amount: -10}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := model.DefaultSettings()
+			settings.Precision = tt.precision
+
+			got := Balance(settings, tt.payments)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+
+			var units int64
+			for _, p := range got {
+				if !settings.HasPrecision(p.Amount) {
+					t.Errorf("amount %v has more than %d decimals", p.Amount, tt.precision)
+				}
+				units += settings.Units(p.Amount)
+			}
+			if units != 0 {
+				t.Errorf("amounts add up to %d units, want 0", units)
+			}
+		})
+	}
+}
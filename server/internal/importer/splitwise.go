@@ -0,0 +1,76 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+)
+
+// Splitwise exports one column per member holding that member's net share of
+// the expense: positive when they lent money, negative when they owe it.
+func parseSplitwise(records []record, res *Result) error {
+	header := records[0].fields
+
+	dateIndex := columnIndex(header, "Date")
+	descriptionIndex := columnIndex(header, "Description")
+	currencyIndex := columnIndex(header, "Currency")
+
+	if dateIndex < 0 || descriptionIndex < 0 || currencyIndex < 0 {
+		return fmt.Errorf("%w: splitwise exports need Date, Description and Currency columns", ErrMissingColumn)
+	}
+
+	for _, column := range header[currencyIndex+1:] {
+		res.Members = append(res.Members, strings.TrimSpace(column))
+	}
+
+	if len(res.Members) == 0 {
+		return ErrNoMembers
+	}
+
+	var currency string
+
+	for _, record := range records[1:] {
+		line := record.line
+
+		if record.isBlank() {
+			continue
+		}
+
+		title := record.field(descriptionIndex)
+		if strings.EqualFold(title, "Total balance") {
+			continue
+		}
+
+		if c := record.field(currencyIndex); currency == "" {
+			currency = c
+		} else if c != currency {
+			res.warn(line, "currency %s differs from %s, amounts are imported unconverted", c, currency)
+		}
+
+		date, err := parseDate(record.field(dateIndex))
+		if err != nil {
+			res.warn(line, "%s", err.Error())
+		}
+
+		var payments []data.Payment
+		valid := true
+
+		for j, member := range res.Members {
+			amount, err := parseAmount(record.field(currencyIndex + 1 + j))
+			if err != nil {
+				res.warn(line, "%s for %s, skipping row", err.Error(), member)
+				valid = false
+				break
+			}
+
+			payments = append(payments, data.Payment{Amount: amount, Payer: member})
+		}
+
+		if valid {
+			res.addRow(line, date, title, payments)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"github.com/soumikc1729/splitty/server/internal/validator"
+	"github.com/soumikc1729/splitty/server/model"
+)
+
+const (
+	DefaultStatementDateFormat = "YYYY-MM-DD"
+)
+
+var (
+	StatementDateFormats = []string{"YYYY-MM-DD", "DD/MM/YYYY", "MM/DD/YYYY", "DD.MM.YYYY", "DD-MM-YYYY"}
+
+	statementDateLayouts = strings.NewReplacer("YYYY", "2006", "MM", "01", "DD", "02")
+)
+
+type StatementMapping = model.StatementMapping
+
+func ValidateStatementMapping(v *validator.Validator, mapping *StatementMapping) {
+	v.Check(mapping.DateColumn != "", "date_column", "must be provided")
+	v.Check(mapping.DescriptionColumn != "", "description_column", "must be provided")
+	v.Check(mapping.AmountColumn != "", "amount_column", "must be provided")
+	v.Check(validator.In(mapping.DateFormat, StatementDateFormats...), "date_format", "must be one of "+strings.Join(StatementDateFormats, ", "))
+	v.Check(utf8.RuneCountInString(mapping.Delimiter) == 1, "delimiter", "must be a single character")
+}
+
+type StatementRow struct {
+	Line        int       `json:"line"`
+	Date        data.Date `json:"date"`
+	Description string    `json:"description"`
+	Amount      float64   `json:"amount"`
+}
+
+type Proposal = model.Proposal
+
+// ParseStatement reads the expenses out of a bank or card statement. Only
+// debits are returned, as positive amounts; credits such as refunds are
+// reported as warnings.
+func ParseStatement(r io.Reader, mapping *StatementMapping) ([]StatementRow, []Warning, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comma, _ = utf8.DecodeRuneInString(mapping.Delimiter)
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, errors.New("csv must not be empty")
+		}
+		return nil, nil, err
+	}
+
+	dateIndex := columnIndex(header, mapping.DateColumn)
+	descriptionIndex := columnIndex(header, mapping.DescriptionColumn)
+	amountIndex := columnIndex(header, mapping.AmountColumn)
+
+	if dateIndex < 0 || descriptionIndex < 0 || amountIndex < 0 {
+		return nil, nil, fmt.Errorf("%w: the statement must contain the mapped date, description and amount columns", ErrMissingColumn)
+	}
+
+	layout := statementDateLayouts.Replace(mapping.DateFormat)
+
+	rows := []StatementRow{}
+	warnings := []Warning{}
+
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		rec := record{line: line, fields: fields}
+
+		if rec.isBlank() {
+			continue
+		}
+
+		date, err := time.Parse(layout, rec.field(dateIndex))
+		if err != nil {
+			warnings = append(warnings, Warning{Line: line, Message: fmt.Sprintf("unrecognized date %q, skipping row", rec.field(dateIndex))})
+			continue
+		}
+
+		value := rec.field(amountIndex)
+		if mapping.DecimalComma {
+			value = strings.ReplaceAll(strings.ReplaceAll(value, ".", ""), ",", ".")
+		}
+
+		amount, err := parseAmount(value)
+		if err != nil {
+			warnings = append(warnings, Warning{Line: line, Message: fmt.Sprintf("%s, skipping row", err.Error())})
+			continue
+		}
+
+		if mapping.DebitsPositive {
+			amount = -amount
+		}
+
+		if amount >= 0 {
+			warnings = append(warnings, Warning{Line: line, Message: "row is not a debit, skipping row"})
+			continue
+		}
+
+		rows = append(rows, StatementRow{
+			Line:        line,
+			Date:        model.NewDate(date.Year(), date.Month(), date.Day()),
+			Description: rec.field(descriptionIndex),
+			Amount:      -amount,
+		})
+	}
+
+	return rows, warnings, nil
+}
+
+// ApplyRules turns statement rows into transactions of group. The first rule
+// whose pattern matches the description decides the category, the payer and
+// the members the expense is split between; rows without a matching rule are
+// paid by defaultPayer and split between all members.
+func ApplyRules(rows []StatementRow, rules []data.ImportRule, group *data.Group, defaultPayer string) ([]Proposal, []Warning) {
+	patterns := make([]*regexp.Regexp, len(rules))
+	for i, rule := range rules {
+		patterns[i] = regexp.MustCompile(rule.Pattern)
+	}
+
+	proposals := []Proposal{}
+	warnings := []Warning{}
+
+	for _, row := range rows {
+		proposal := Proposal{Line: row.Line, Date: row.Date, Description: row.Description}
+
+		payer := defaultPayer
+		split := group.Users
+		var category string
+
+		for i, rule := range rules {
+			if !patterns[i].MatchString(row.Description) {
+				continue
+			}
+
+			proposal.RuleID = rule.ID
+			category = rule.Category
+			if rule.Payer != "" {
+				payer = rule.Payer
+			}
+			if len(rule.Split) > 0 {
+				split = rule.Split
+			}
+			break
+		}
+
+		if payer == "" {
+			warnings = append(warnings, Warning{Line: row.Line, Message: "no rule or default payer applies, skipping row"})
+			continue
+		}
+
+		proposal.Transaction = &data.Transaction{
+			Title:    sanitizeTitle(row.Description),
+			Category: category,
+			Payments: Balance(group.Settings, group.Settings.SplitEqually([]data.Payment{{Amount: row.Amount, Payer: payer}}, split)),
+			Date:     row.Date,
+			GroupID:  group.ID,
+		}
+
+		proposals = append(proposals, proposal)
+	}
+
+	return proposals, warnings
+}
+
+// Fingerprint identifies transactions that most likely describe the same
+// expense, so that statement rows imported earlier are not imported twice.
+func Fingerprint(transaction *data.Transaction) string {
+	var total float64
+	for _, p := range transaction.Payments {
+		if p.Amount > 0 {
+			total += p.Amount
+		}
+	}
+	return fmt.Sprintf("%s|%s|%.2f", transaction.Date, strings.ToLower(transaction.Title), total)
+}
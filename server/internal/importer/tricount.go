@@ -0,0 +1,90 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+)
+
+var (
+	tricountSharePrefixes = []string{"Paid for ", "Impacted to "}
+)
+
+// Tricount exports the total amount with the member who paid it, followed by
+// one column per member holding their share of that amount.
+func parseTricount(records []record, res *Result) error {
+	header := records[0].fields
+
+	titleIndex := columnIndex(header, "Title", "Description")
+	amountIndex := columnIndex(header, "Amount in default currency", "Amount")
+	dateIndex := columnIndex(header, "Date & time", "Date")
+	payerIndex := columnIndex(header, "Paid by")
+
+	if titleIndex < 0 || amountIndex < 0 || payerIndex < 0 {
+		return fmt.Errorf("%w: tricount exports need Title, Amount and Paid by columns", ErrMissingColumn)
+	}
+
+	shareIndexes := make(map[string]int)
+	for i, column := range header {
+		for _, prefix := range tricountSharePrefixes {
+			if member, ok := strings.CutPrefix(strings.TrimSpace(column), prefix); ok {
+				res.Members = append(res.Members, member)
+				shareIndexes[member] = i
+			}
+		}
+	}
+
+	if len(res.Members) == 0 {
+		return ErrNoMembers
+	}
+
+	for _, record := range records[1:] {
+		line := record.line
+
+		if record.isBlank() {
+			continue
+		}
+
+		amount, err := parseAmount(record.field(amountIndex))
+		if err != nil {
+			res.warn(line, "%s, skipping row", err.Error())
+			continue
+		}
+
+		payer := record.field(payerIndex)
+		if _, ok := shareIndexes[payer]; !ok {
+			res.warn(line, "payer %q is not one of the member columns, skipping row", payer)
+			continue
+		}
+
+		var date data.Date
+		if dateIndex >= 0 {
+			if date, err = parseDate(record.field(dateIndex)); err != nil {
+				res.warn(line, "%s", err.Error())
+			}
+		}
+
+		// Expenses are positive, while income and refunds are negative and
+		// flow the other way, so the signs are kept as they are.
+		payments := []data.Payment{{Amount: amount, Payer: payer}}
+		valid := true
+
+		for _, member := range res.Members {
+			share, err := parseAmount(record.field(shareIndexes[member]))
+			if err != nil {
+				res.warn(line, "%s for %s, skipping row", err.Error(), member)
+				valid = false
+				break
+			}
+
+			payments = append(payments, data.Payment{Amount: -share, Payer: member})
+		}
+
+		if valid {
+			res.addRow(line, date, record.field(titleIndex), mergePayers(payments))
+		}
+	}
+
+	return nil
+}
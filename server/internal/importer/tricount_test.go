@@ -0,0 +1,69 @@
+package importer
+
+import (
+	"os"
+	"testing"
+
+	"github.com/soumikc1729/splitty/server/internal/data"
+)
+
+func TestParseTricount(t *testing.T) {
+	file, err := os.Open("testdata/tricount.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	res, err := Parse(Tricount, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Warnings) != 0 {
+		t.Fatalf("got warnings %v", res.Warnings)
+	}
+
+	want := []struct {
+		title    string
+		payments map[string]float64
+	}{
+		{"Hotel", map[string]float64{"Alice": 60, "Bob": -60}},
+		{"Dinner", map[string]float64{"Alice": -15.5, "Bob": 15.5}},
+		// A refund is paid back to Alice, so the balances move the other way.
+		{"Hotel refund", map[string]float64{"Alice": -20, "Bob": 20}},
+	}
+
+	if len(res.Rows) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(res.Rows), len(want))
+	}
+
+	for i, row := range res.Rows {
+		if row.Title != want[i].title {
+			t.Errorf("row %d: got title %q, want %q", i, row.Title, want[i].title)
+		}
+
+		if got := paymentsByPayer(row.Payments); !equalAmounts(got, want[i].payments) {
+			t.Errorf("row %q: got payments %v, want %v", row.Title, got, want[i].payments)
+		}
+	}
+}
+
+func paymentsByPayer(payments []data.Payment) map[string]float64 {
+	byPayer := make(map[string]float64)
+	for _, p := range payments {
+		byPayer[p.Payer] += p.Amount
+	}
+	return byPayer
+}
+
+func equalAmounts(a, b map[string]float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for payer, amount := range a {
+		if diff := amount - b[payer]; diff > tolerance || diff < -tolerance {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,134 @@
+package report
+
+import (
+	"embed"
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/soumikc1729/splitty/server/internal/balance"
+	"github.com/soumikc1729/splitty/server/internal/data"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+var (
+	//go:embed templates
+	templateFS embed.FS
+
+	// The amount function is replaced by Render to follow the settings of the
+	// group.
+	templates = template.Must(template.New("").Funcs(template.FuncMap{
+		"amount": func(float64) string { return "" },
+	}).ParseFS(templateFS, "templates/*.html"))
+)
+
+type MemberLine struct {
+	User     string
+	Paid     float64
+	Consumed float64
+	Net      float64
+}
+
+type Statement struct {
+	Group        *data.Group
+	From         data.Date
+	To           data.Date
+	Transactions []*data.Transaction
+	Spending     []MemberLine
+	Balances     []MemberLine
+	Settlements  []balance.Settlement
+	Total        float64
+	GeneratedAt  time.Time
+}
+
+type Builder struct {
+	statement *Statement
+	period    *balance.Summary
+	closing   *balance.Summary
+}
+
+// NewBuilder starts the statement of group for the period [from, to). It is
+// fed every transaction made before to: those inside the period are listed,
+// all of them count towards the closing balances.
+func NewBuilder(group *data.Group, from, to data.Date) *Builder {
+	statement := &Statement{
+		Group:        group,
+		From:         from,
+		To:           to,
+		Transactions: []*data.Transaction{},
+		GeneratedAt:  time.Now().UTC(),
+	}
+
+	return &Builder{
+		statement: statement,
+		period:    balance.New(group.Users),
+		closing:   balance.New(group.Users),
+	}
+}
+
+func (b *Builder) Add(transaction *data.Transaction) error {
+	b.closing.Add(transaction)
+
+	if !transaction.Date.Before(b.statement.From.Time) {
+		b.period.Add(transaction)
+		b.statement.Transactions = append(b.statement.Transactions, transaction)
+	}
+
+	return nil
+}
+
+func (b *Builder) Statement() *Statement {
+	s := b.statement
+
+	s.Total = b.period.Total
+	s.Spending = memberLines(b.period)
+	s.Balances = memberLines(b.closing)
+	if s.Group.Settings.SimplifyDebts {
+		s.Settlements = b.closing.Settlements()
+	} else {
+		s.Settlements = b.closing.Debts()
+	}
+
+	return s
+}
+
+func (s *Statement) LastDay() data.Date {
+	return s.To.AddDays(-1)
+}
+
+func Render(w io.Writer, statement *Statement) error {
+	t, err := templates.Clone()
+	if err != nil {
+		return err
+	}
+
+	t.Funcs(template.FuncMap{"amount": amountFormatter(statement.Group.Settings)})
+
+	return t.ExecuteTemplate(w, "statement.html", statement)
+}
+
+func memberLines(summary *balance.Summary) []MemberLine {
+	lines := []MemberLine{}
+	for _, user := range summary.Users {
+		lines = append(lines, MemberLine{
+			User:     user,
+			Paid:     summary.Paid[user],
+			Consumed: summary.Consumed[user],
+			Net:      summary.Net(user),
+		})
+	}
+	return lines
+}
+
+// amountFormatter formats amounts with the precision of the group, written
+// the way they are in its locale, such as 1,234.50 in en-US or 1.234,50 in
+// de-DE.
+func amountFormatter(settings data.Settings) func(float64) string {
+	printer := message.NewPrinter(language.Make(settings.Locale))
+
+	return func(amount float64) string {
+		return printer.Sprint(number.Decimal(amount, number.Scale(settings.Precision)))
+	}
+}
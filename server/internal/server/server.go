@@ -27,10 +27,40 @@ type Server struct {
 	Config    *Config
 	Logger    *zerolog.Logger
 	WaitGroup sync.WaitGroup
+
+	ctx      context.Context
+	cancel   context.CancelFunc
+	shutdown chan struct{}
 }
 
 func New(cfg *Config, logger *zerolog.Logger) *Server {
-	return &Server{Config: cfg, Logger: logger}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Server{Config: cfg, Logger: logger, ctx: ctx, cancel: cancel, shutdown: make(chan struct{})}
+}
+
+// ShuttingDown returns a channel that is closed as soon as the server starts
+// shutting down. Shutdown waits for every active request, so long-lived
+// responses such as streams have to end when it is closed.
+func (s *Server) ShuttingDown() <-chan struct{} {
+	return s.shutdown
+}
+
+// Background runs fn in its own goroutine. The context passed to fn is
+// cancelled when the server shuts down, and shutdown waits for fn to return.
+func (s *Server) Background(fn func(ctx context.Context)) {
+	s.WaitGroup.Add(1)
+
+	go func() {
+		defer s.WaitGroup.Done()
+
+		defer func() {
+			if err := recover(); err != nil {
+				s.Logger.Error().Interface("error", err).Msg("background task panicked")
+			}
+		}()
+
+		fn(s.ctx)
+	}()
 }
 
 func (s *Server) Start(handler http.Handler) error {
@@ -43,6 +73,8 @@ func (s *Server) Start(handler http.Handler) error {
 		WriteTimeout: s.Config.WriteTimeout,
 	}
 
+	srv.RegisterOnShutdown(func() { close(s.shutdown) })
+
 	shutdownError := make(chan error)
 	go s.finishBackgroudTasks(shutdownError, srv)
 
@@ -80,6 +112,8 @@ func (s *Server) finishBackgroudTasks(shutdownError chan error, srv *http.Server
 
 	s.Logger.Info().Str("addr", srv.Addr).Msg("completing background tasks")
 
+	s.cancel()
+
 	s.WaitGroup.Wait()
 	shutdownError <- nil
 }
@@ -0,0 +1,224 @@
+package socket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Close codes that handlers may close a connection with.
+const (
+	CloseNormalClosure   = websocket.CloseNormalClosure
+	ClosePolicyViolation = websocket.ClosePolicyViolation
+	CloseTryAgainLater   = websocket.CloseTryAgainLater
+)
+
+var (
+	ErrHubClosed = errors.New("socket hub is closed")
+)
+
+type Config struct {
+	WriteTimeout   time.Duration `mapstructure:"write-timeout"`
+	PongTimeout    time.Duration `mapstructure:"pong-timeout"`
+	PingInterval   time.Duration `mapstructure:"ping-interval"`
+	MaxMessageSize int64         `mapstructure:"max-message-size"`
+	SendBuffer     int           `mapstructure:"send-buffer"`
+}
+
+// Hub keeps track of every open WebSocket connection, so that they can all be
+// closed when the server shuts down.
+type Hub struct {
+	config   *Config
+	upgrader websocket.Upgrader
+
+	mu     sync.Mutex
+	conns  map[*Conn]struct{}
+	closed bool
+	wg     sync.WaitGroup
+}
+
+func NewHub(cfg *Config) *Hub {
+	return &Hub{
+		config: cfg,
+		upgrader: websocket.Upgrader{
+			// Clients authenticate with the group token rather than cookies,
+			// so requests from other origins cannot act on behalf of anyone.
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+		conns: make(map[*Conn]struct{}),
+	}
+}
+
+// Run waits until ctx is cancelled and then closes every connection, returning
+// once all of them are done.
+func (h *Hub) Run(ctx context.Context) {
+	<-ctx.Done()
+
+	h.mu.Lock()
+	h.closed = true
+	for c := range h.conns {
+		c.Close(websocket.CloseGoingAway, "server shutting down")
+	}
+	h.mu.Unlock()
+
+	h.wg.Wait()
+}
+
+// Serve upgrades the request to a WebSocket connection. Messages received on
+// it are passed to handle one at a time until the connection is closed. When
+// the upgrade fails, an error response has already been sent.
+func (h *Hub) Serve(w http.ResponseWriter, r *http.Request, handle func(c *Conn, message []byte)) (*Conn, error) {
+	ws, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Conn{
+		ws:      ws,
+		config:  h.config,
+		send:    make(chan []byte, h.config.SendBuffer),
+		closing: make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"), time.Now().Add(h.config.WriteTimeout))
+		ws.Close()
+		return nil, ErrHubClosed
+	}
+	h.conns[c] = struct{}{}
+	h.wg.Add(2)
+	h.mu.Unlock()
+
+	go func() {
+		defer h.wg.Done()
+		c.writePump()
+	}()
+
+	go func() {
+		defer h.wg.Done()
+		defer h.remove(c)
+		c.readPump(handle)
+	}()
+
+	return c, nil
+}
+
+func (h *Hub) remove(c *Conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.conns, c)
+}
+
+// Conn is a WebSocket connection of the hub. Messages are sent as JSON text
+// messages by a single writer.
+type Conn struct {
+	ws     *websocket.Conn
+	config *Config
+	send   chan []byte
+
+	closeOnce   sync.Once
+	closing     chan struct{}
+	closeCode   int
+	closeReason string
+}
+
+// Send queues v to be sent without blocking. A connection that cannot keep up
+// is closed and Send reports false.
+func (c *Conn) Send(v interface{}) bool {
+	message, err := json.Marshal(v)
+	if err != nil {
+		return false
+	}
+
+	select {
+	case <-c.closing:
+		return false
+	default:
+	}
+
+	select {
+	case c.send <- message:
+		return true
+	default:
+		c.Close(websocket.CloseTryAgainLater, "too slow to receive messages")
+		return false
+	}
+}
+
+// Close closes the connection with the given close code. It is safe to call
+// more than once, only the first call has an effect.
+func (c *Conn) Close(code int, reason string) {
+	c.closeOnce.Do(func() {
+		c.closeCode = code
+		c.closeReason = reason
+		close(c.closing)
+	})
+}
+
+// Done returns a channel that is closed once the connection is closing.
+func (c *Conn) Done() <-chan struct{} {
+	return c.closing
+}
+
+func (c *Conn) readPump(handle func(c *Conn, message []byte)) {
+	c.ws.SetReadLimit(c.config.MaxMessageSize)
+	c.ws.SetReadDeadline(time.Now().Add(c.config.PongTimeout))
+	c.ws.SetPongHandler(func(string) error {
+		return c.ws.SetReadDeadline(time.Now().Add(c.config.PongTimeout))
+	})
+
+	for {
+		_, message, err := c.ws.ReadMessage()
+		if err != nil {
+			var closeErr *websocket.CloseError
+			switch {
+			case errors.As(err, &closeErr):
+				c.Close(websocket.CloseNormalClosure, "")
+			case errors.Is(err, websocket.ErrReadLimit):
+				c.Close(websocket.CloseMessageTooBig, "message too big")
+			default:
+				c.Close(websocket.CloseAbnormalClosure, "")
+			}
+			return
+		}
+
+		handle(c, message)
+	}
+}
+
+func (c *Conn) writePump() {
+	ticker := time.NewTicker(c.config.PingInterval)
+	defer ticker.Stop()
+	defer c.ws.Close()
+
+	for {
+		select {
+		case message := <-c.send:
+			c.ws.SetWriteDeadline(time.Now().Add(c.config.WriteTimeout))
+			if err := c.ws.WriteMessage(websocket.TextMessage, message); err != nil {
+				c.Close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-ticker.C:
+			c.ws.SetWriteDeadline(time.Now().Add(c.config.WriteTimeout))
+			if err := c.ws.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.Close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-c.closing:
+			if c.closeCode != websocket.CloseAbnormalClosure {
+				closeMessage := websocket.FormatCloseMessage(c.closeCode, c.closeReason)
+				c.ws.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(c.config.WriteTimeout))
+			}
+			return
+		}
+	}
+}
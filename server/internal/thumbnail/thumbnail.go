@@ -0,0 +1,85 @@
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"io"
+
+	_ "image/gif"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	ContentType = "image/jpeg"
+	quality     = 80
+)
+
+var (
+	ErrUnsupported = errors.New("unsupported image format")
+
+	contentTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp"}
+
+	// maxPixels guards against images whose dimensions would take too much
+	// memory to decode.
+	maxPixels = 50_000_000
+)
+
+func Supported(contentType string) bool {
+	for _, t := range contentTypes {
+		if t == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+// Generate decodes the image in r and returns a JPEG that fits into a
+// size x size square, keeping the aspect ratio. Images that already fit are
+// only re-encoded.
+func Generate(r io.ReadSeeker, size int) ([]byte, error) {
+	cfg, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrUnsupported
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	src, _, err := image.Decode(r)
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if width > size || height > size {
+		if width >= height {
+			width, height = size, max(1, height*size/width)
+		} else {
+			width, height = max(1, width*size/height), size
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	// JPEG has no alpha channel, so transparent areas become white.
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -1,6 +1,9 @@
 package validator
 
-import "regexp"
+import (
+	"cmp"
+	"regexp"
+)
 
 type Validator struct {
 	Errors map[string]string
@@ -55,3 +58,7 @@ func In(value string, list ...string) bool {
 	}
 	return false
 }
+
+func Between[T cmp.Ordered](value, min, max T) bool {
+	return value >= min && value <= max
+}
@@ -0,0 +1,110 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress is returned when a webhook URL resolves to an address
+// the server must not send requests to, such as its own loopback interface or
+// a private network.
+var ErrForbiddenAddress = errors.New("webhook: forbidden address")
+
+var (
+	// reservedPrefixes are refused on top of the special addresses netip
+	// recognizes: the carrier-grade NAT range, "this network", the range for
+	// benchmarking networks and the range for IETF protocol assignments.
+	reservedPrefixes = []netip.Prefix{
+		netip.MustParsePrefix("100.64.0.0/10"),
+		netip.MustParsePrefix("0.0.0.0/8"),
+		netip.MustParsePrefix("198.18.0.0/15"),
+		netip.MustParsePrefix("192.0.0.0/24"),
+	}
+
+	// nat64Prefix holds IPv6 addresses that NAT64 gateways translate to the
+	// IPv4 address in their last four bytes.
+	nat64Prefix = netip.MustParsePrefix("64:ff9b::/96")
+)
+
+// PublicAddr reports whether addr may receive webhooks. Loopback, link-local,
+// private, shared, reserved, multicast and unspecified addresses are refused,
+// so that a webhook cannot be used to reach services that are only exposed to
+// the server. Addresses translated by NAT64 are checked as the IPv4 address
+// they stand for.
+func PublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	if addr.Is6() && nat64Prefix.Contains(addr) {
+		b := addr.As16()
+		addr = netip.AddrFrom4([4]byte(b[12:]))
+	}
+
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return addr.IsValid() &&
+		!addr.IsLoopback() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsPrivate() &&
+		!addr.IsUnspecified()
+}
+
+// NewClient returns a client for sending webhooks. The address is checked
+// when the connection is dialed, after the host has been resolved, so a
+// host that resolves to a public address when the webhook is created and to
+// a private one later is still refused. Redirects are not followed, and
+// count as unexpected responses.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !PublicAddr(addrPort.Addr()) {
+				return ErrForbiddenAddress
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Describe returns a message about an error returned by Send that can be
+// shown to the subscriber. The errors of the transport are not passed on as
+// they are, since they tell about the network the server runs in.
+func Describe(err error) string {
+	var statusErr *StatusError
+	var netErr net.Error
+
+	switch {
+	case errors.As(err, &statusErr):
+		return statusErr.Error()
+	case errors.Is(err, ErrForbiddenAddress):
+		return "the URL resolves to an address that is not allowed"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "the request timed out"
+	default:
+		return "the request could not be sent"
+	}
+}
@@ -0,0 +1,35 @@
+package webhook
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestPublicAddr(t *testing.T) {
+	for _, tt := range []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"100.127.255.254", false},
+		{"0.1.2.3", false},
+		{"198.18.0.1", false},
+		{"198.19.255.255", false},
+		{"192.0.0.8", false},
+		{"::1", false},
+		{"::ffff:10.1.2.3", false},
+		{"fd00::1", false},
+		{"64:ff9b::5db8:d822", true},
+		{"64:ff9b::a01:203", false},
+		{"64:ff9b::a9fe:a9fe", false},
+	} {
+		if got := PublicAddr(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("PublicAddr(%s) = %t, want %t", tt.addr, got, tt.want)
+		}
+	}
+}
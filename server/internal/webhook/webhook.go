@@ -0,0 +1,104 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Splitty-Signature"
+	TimestampHeader = "X-Splitty-Timestamp"
+	EventHeader     = "X-Splitty-Event"
+	DeliveryHeader  = "X-Splitty-Delivery"
+)
+
+type Config struct {
+	PollInterval   time.Duration `mapstructure:"poll-interval"`
+	Timeout        time.Duration `mapstructure:"timeout"`
+	MaxAttempts    int           `mapstructure:"max-attempts"`
+	InitialBackoff time.Duration `mapstructure:"initial-backoff"`
+	MaxBackoff     time.Duration `mapstructure:"max-backoff"`
+	BatchSize      int           `mapstructure:"batch-size"`
+	Retention      time.Duration `mapstructure:"retention"`
+}
+
+// Backoff returns how long to wait before the next attempt after the given
+// number of failed attempts. It doubles with every attempt up to MaxBackoff.
+func (c *Config) Backoff(attempts int) time.Duration {
+	backoff := c.InitialBackoff
+	for i := 1; i < attempts && backoff < c.MaxBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, c.MaxBackoff)
+}
+
+// Sign returns the signature of a payload sent at timestamp. Receivers
+// recompute it over "<timestamp>.<body>" with the shared secret and compare
+// it to the SignatureHeader.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type Request struct {
+	URL        string
+	Secret     string
+	Event      string
+	DeliveryID int64
+	Body       []byte
+}
+
+// Send posts the signed payload and returns the status code of the response.
+// Any response other than 2xx is an error.
+func Send(ctx context.Context, client *http.Client, req *Request) (int, error) {
+	timestamp := time.Now().Unix()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return 0, err
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", "splitty-webhooks")
+	httpReq.Header.Set(SignatureHeader, Sign(req.Secret, timestamp, req.Body))
+	httpReq.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	httpReq.Header.Set(EventHeader, req.Event)
+	httpReq.Header.Set(DeliveryHeader, strconv.FormatInt(req.DeliveryID, 10))
+
+	res, err := client.Do(httpReq)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	// Draining a bounded part of the body lets the connection be reused.
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, &StatusError{Status: res.Status}
+	}
+
+	return res.StatusCode, nil
+}
+
+// StatusError is returned by Send for responses other than 2xx.
+type StatusError struct {
+	Status string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected response status %s", e.Status)
+}
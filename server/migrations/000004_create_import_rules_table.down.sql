@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS import_rules;
+ALTER TABLE transactions DROP COLUMN IF EXISTS category;
@@ -0,0 +1,12 @@
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS category text NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS import_rules (
+    id bigserial PRIMARY KEY,
+    pattern text NOT NULL,
+    category text NOT NULL DEFAULT '',
+    payer text NOT NULL DEFAULT '',
+    split text[] NOT NULL DEFAULT '{}',
+    group_id bigint NOT NULL,
+    version integer NOT NULL DEFAULT 1,
+    FOREIGN KEY (group_id) REFERENCES groups(id)
+);
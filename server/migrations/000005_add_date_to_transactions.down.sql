@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS transactions_group_id_date_idx;
+ALTER TABLE transactions DROP COLUMN IF EXISTS date;
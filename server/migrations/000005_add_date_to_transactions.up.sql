@@ -0,0 +1,3 @@
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS date date NOT NULL DEFAULT CURRENT_DATE;
+UPDATE transactions SET date = created_at::date;
+CREATE INDEX IF NOT EXISTS transactions_group_id_date_idx ON transactions (group_id, date);
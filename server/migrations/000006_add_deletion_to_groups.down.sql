@@ -0,0 +1,8 @@
+ALTER TABLE import_rules DROP CONSTRAINT IF EXISTS import_rules_group_id_fkey;
+ALTER TABLE import_rules ADD CONSTRAINT import_rules_group_id_fkey FOREIGN KEY (group_id) REFERENCES groups(id);
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_group_id_fkey;
+ALTER TABLE transactions ADD CONSTRAINT transactions_group_id_fkey FOREIGN KEY (group_id) REFERENCES groups(id);
+
+DROP INDEX IF EXISTS groups_delete_after_idx;
+ALTER TABLE groups DROP COLUMN IF EXISTS delete_after;
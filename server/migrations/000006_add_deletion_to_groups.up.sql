@@ -0,0 +1,9 @@
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS delete_after timestamp(0) with time zone;
+
+CREATE INDEX IF NOT EXISTS groups_delete_after_idx ON groups (delete_after) WHERE delete_after IS NOT NULL;
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_group_id_fkey;
+ALTER TABLE transactions ADD CONSTRAINT transactions_group_id_fkey FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE;
+
+ALTER TABLE import_rules DROP CONSTRAINT IF EXISTS import_rules_group_id_fkey;
+ALTER TABLE import_rules ADD CONSTRAINT import_rules_group_id_fkey FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE CASCADE;
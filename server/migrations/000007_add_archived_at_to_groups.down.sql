@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS groups_reject_archived_group_updates ON groups;
+DROP TRIGGER IF EXISTS import_rules_reject_archived_group_writes ON import_rules;
+DROP TRIGGER IF EXISTS transactions_reject_archived_group_writes ON transactions;
+DROP FUNCTION IF EXISTS reject_archived_group_updates();
+DROP FUNCTION IF EXISTS reject_archived_group_writes();
+ALTER TABLE groups DROP COLUMN IF EXISTS archived_at;
@@ -0,0 +1,53 @@
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS archived_at timestamp(0) with time zone;
+
+CREATE OR REPLACE FUNCTION reject_archived_group_writes() RETURNS trigger AS $$
+DECLARE
+    row_group_id bigint;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        row_group_id := OLD.group_id;
+    ELSE
+        row_group_id := NEW.group_id;
+    END IF;
+
+    -- Archived groups stay deletable once their deletion grace period has passed.
+    IF EXISTS (
+        SELECT 1 FROM groups
+        WHERE id = row_group_id AND archived_at IS NOT NULL
+            AND NOT (TG_OP = 'DELETE' AND delete_after IS NOT NULL AND delete_after <= now())
+    ) THEN
+        RAISE EXCEPTION 'group % is archived', row_group_id USING ERRCODE = 'SP001';
+    END IF;
+
+    IF TG_OP = 'DELETE' THEN
+        RETURN OLD;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION reject_archived_group_updates() RETURNS trigger AS $$
+BEGIN
+    IF OLD.archived_at IS NOT NULL AND NEW.archived_at IS NOT NULL
+        AND (NEW.name IS DISTINCT FROM OLD.name OR NEW.users IS DISTINCT FROM OLD.users) THEN
+        RAISE EXCEPTION 'group % is archived', OLD.id USING ERRCODE = 'SP001';
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS transactions_reject_archived_group_writes ON transactions;
+CREATE TRIGGER transactions_reject_archived_group_writes
+    BEFORE INSERT OR UPDATE OR DELETE ON transactions
+    FOR EACH ROW EXECUTE FUNCTION reject_archived_group_writes();
+
+DROP TRIGGER IF EXISTS import_rules_reject_archived_group_writes ON import_rules;
+CREATE TRIGGER import_rules_reject_archived_group_writes
+    BEFORE INSERT OR UPDATE OR DELETE ON import_rules
+    FOR EACH ROW EXECUTE FUNCTION reject_archived_group_writes();
+
+DROP TRIGGER IF EXISTS groups_reject_archived_group_updates ON groups;
+CREATE TRIGGER groups_reject_archived_group_updates
+    BEFORE UPDATE ON groups
+    FOR EACH ROW EXECUTE FUNCTION reject_archived_group_updates();
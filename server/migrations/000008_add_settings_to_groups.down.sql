@@ -0,0 +1,12 @@
+CREATE OR REPLACE FUNCTION reject_archived_group_updates() RETURNS trigger AS $$
+BEGIN
+    IF OLD.archived_at IS NOT NULL AND NEW.archived_at IS NOT NULL
+        AND (NEW.name IS DISTINCT FROM OLD.name OR NEW.users IS DISTINCT FROM OLD.users) THEN
+        RAISE EXCEPTION 'group % is archived', OLD.id USING ERRCODE = 'SP001';
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE groups DROP COLUMN IF EXISTS settings;
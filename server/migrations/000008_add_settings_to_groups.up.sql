@@ -0,0 +1,13 @@
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS settings JSONB NOT NULL DEFAULT '{}';
+
+CREATE OR REPLACE FUNCTION reject_archived_group_updates() RETURNS trigger AS $$
+BEGIN
+    IF OLD.archived_at IS NOT NULL AND NEW.archived_at IS NOT NULL
+        AND (NEW.name IS DISTINCT FROM OLD.name OR NEW.users IS DISTINCT FROM OLD.users
+            OR NEW.settings IS DISTINCT FROM OLD.settings) THEN
+        RAISE EXCEPTION 'group % is archived', OLD.id USING ERRCODE = 'SP001';
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS attachments (
+    id bigserial PRIMARY KEY,
+    transaction_id bigint NOT NULL,
+    filename text NOT NULL,
+    content_type text NOT NULL,
+    size bigint NOT NULL,
+    blob_key text NOT NULL,
+    thumbnail_key text NOT NULL DEFAULT '',
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS attachments_transaction_id_idx ON attachments (transaction_id);
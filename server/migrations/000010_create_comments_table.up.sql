@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS comments (
+    id bigserial PRIMARY KEY,
+    transaction_id bigint NOT NULL,
+    author text NOT NULL,
+    body text NOT NULL,
+    created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+    version integer NOT NULL DEFAULT 1,
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS comments_transaction_id_idx ON comments (transaction_id);
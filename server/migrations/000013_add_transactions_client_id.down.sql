@@ -0,0 +1,2 @@
+ALTER TABLE transactions DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE transactions DROP COLUMN IF EXISTS client_id;
@@ -0,0 +1,7 @@
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS client_id uuid UNIQUE;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS updated_at timestamp with time zone NOT NULL DEFAULT NOW();
+
+-- Backfilling must not be rejected for the transactions of archived groups.
+ALTER TABLE transactions DISABLE TRIGGER transactions_reject_archived_group_writes;
+UPDATE transactions SET updated_at = created_at;
+ALTER TABLE transactions ENABLE TRIGGER transactions_reject_archived_group_writes;
@@ -0,0 +1 @@
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS created_at timestamp(0) with time zone NOT NULL DEFAULT NOW();
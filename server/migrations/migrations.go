@@ -0,0 +1,17 @@
+// Package migrations embeds the database migrations into the binary, so that
+// the server can apply them itself.
+package migrations
+
+import (
+	"embed"
+)
+
+// Postgres holds the migrations of the Postgres schema.
+//
+//go:embed *.sql
+var Postgres embed.FS
+
+// SQLite holds the migrations of the SQLite schema in its sqlite directory.
+//
+//go:embed sqlite/*.sql
+var SQLite embed.FS
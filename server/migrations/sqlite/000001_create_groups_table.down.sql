@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS groups_reject_archived_group_updates;
+DROP TABLE IF EXISTS groups;
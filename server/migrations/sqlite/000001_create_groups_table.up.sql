@@ -0,0 +1,23 @@
+-- Users and settings are kept as JSON, as SQLite has neither arrays nor JSONB.
+-- Times are kept as UTC text that sorts chronologically.
+CREATE TABLE IF NOT EXISTS groups (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    name text NOT NULL,
+    token text NOT NULL UNIQUE,
+    users text NOT NULL CHECK (json_valid(users) AND json_type(users) = 'array'),
+    settings text NOT NULL DEFAULT '{}' CHECK (json_valid(settings)),
+    delete_after datetime,
+    archived_at datetime,
+    version integer NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS groups_delete_after_idx ON groups (delete_after) WHERE delete_after IS NOT NULL;
+
+CREATE TRIGGER IF NOT EXISTS groups_reject_archived_group_updates
+    BEFORE UPDATE OF name, users, settings ON groups
+    FOR EACH ROW
+    WHEN OLD.archived_at IS NOT NULL AND NEW.archived_at IS NOT NULL
+        AND (NEW.name IS NOT OLD.name OR NEW.users IS NOT OLD.users OR NEW.settings IS NOT OLD.settings)
+BEGIN
+    SELECT RAISE(ABORT, 'SP001: group is archived');
+END;
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS transactions_reject_archived_group_deletes;
+DROP TRIGGER IF EXISTS transactions_reject_archived_group_updates;
+DROP TRIGGER IF EXISTS transactions_reject_archived_group_inserts;
+DROP TABLE IF EXISTS transactions;
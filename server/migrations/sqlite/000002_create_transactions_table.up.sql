@@ -0,0 +1,45 @@
+CREATE TABLE IF NOT EXISTS transactions (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    title text NOT NULL,
+    category text NOT NULL DEFAULT '',
+    payments text NOT NULL CHECK (json_valid(payments) AND json_type(payments) = 'array'),
+    date date NOT NULL,
+    group_id integer NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    client_id text UNIQUE,
+    created_at datetime NOT NULL,
+    updated_at datetime NOT NULL,
+    version integer NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS transactions_group_id_date_idx ON transactions (group_id, date);
+
+-- The triggers reject writes to the transactions of archived groups, with the
+-- same rules as the Postgres schema.
+CREATE TRIGGER IF NOT EXISTS transactions_reject_archived_group_inserts
+    BEFORE INSERT ON transactions
+    FOR EACH ROW
+    WHEN EXISTS (SELECT 1 FROM groups WHERE id = NEW.group_id AND archived_at IS NOT NULL)
+BEGIN
+    SELECT RAISE(ABORT, 'SP001: group is archived');
+END;
+
+CREATE TRIGGER IF NOT EXISTS transactions_reject_archived_group_updates
+    BEFORE UPDATE ON transactions
+    FOR EACH ROW
+    WHEN EXISTS (SELECT 1 FROM groups WHERE id = NEW.group_id AND archived_at IS NOT NULL)
+BEGIN
+    SELECT RAISE(ABORT, 'SP001: group is archived');
+END;
+
+-- Archived groups stay deletable once their deletion grace period has passed.
+CREATE TRIGGER IF NOT EXISTS transactions_reject_archived_group_deletes
+    BEFORE DELETE ON transactions
+    FOR EACH ROW
+    WHEN EXISTS (
+        SELECT 1 FROM groups
+        WHERE id = OLD.group_id AND archived_at IS NOT NULL
+            AND NOT (delete_after IS NOT NULL AND delete_after <= strftime('%Y-%m-%d %H:%M:%S+00:00', 'now'))
+    )
+BEGIN
+    SELECT RAISE(ABORT, 'SP001: group is archived');
+END;
@@ -0,0 +1,5 @@
+-- Columns added to an existing table cannot default to the current time, so
+-- existing groups are backfilled and new ones get it from the application.
+ALTER TABLE groups ADD COLUMN created_at datetime NOT NULL DEFAULT '1970-01-01 00:00:00+00:00';
+
+UPDATE groups SET created_at = strftime('%Y-%m-%d %H:%M:%S+00:00', 'now');
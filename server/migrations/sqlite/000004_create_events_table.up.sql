@@ -0,0 +1,12 @@
+-- Events outlive the group they belong to for a while, so that subscribers
+-- still learn about its deletion. They are pruned by age instead.
+CREATE TABLE IF NOT EXISTS events (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    group_id integer NOT NULL,
+    type text NOT NULL,
+    data text NOT NULL CHECK (json_valid(data)),
+    created_at datetime NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS events_group_id_idx ON events (group_id, id);
+CREATE INDEX IF NOT EXISTS events_created_at_idx ON events (created_at);
@@ -0,0 +1,8 @@
+package model
+
+// Settlement is a transfer that settles what From owes To.
+type Settlement struct {
+	From   string  `json:"from"`
+	To     string  `json:"to"`
+	Amount float64 `json:"amount"`
+}
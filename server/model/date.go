@@ -0,0 +1,91 @@
+package model
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrInvalidDateFormat = errors.New("invalid date format, expected YYYY-MM-DD")
+)
+
+// Date is a calendar day without a time of day, stored in a date column and
+// encoded as "YYYY-MM-DD" in JSON.
+type Date struct {
+	time.Time
+}
+
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+func Today(loc *time.Location) Date {
+	now := time.Now().In(loc)
+	return NewDate(now.Year(), now.Month(), now.Day())
+}
+
+func ParseDate(value string) (Date, error) {
+	t, err := time.Parse(DateLayout, value)
+	if err != nil {
+		return Date{}, ErrInvalidDateFormat
+	}
+	return Date{t}, nil
+}
+
+func (d Date) String() string {
+	return d.Format(DateLayout)
+}
+
+func (d Date) AddDays(days int) Date {
+	return Date{d.AddDate(0, 0, days)}
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(d.String())), nil
+}
+
+func (d *Date) UnmarshalJSON(b []byte) error {
+	value, err := strconv.Unquote(string(b))
+	if err != nil {
+		return ErrInvalidDateFormat
+	}
+
+	date, err := ParseDate(value)
+	if err != nil {
+		return err
+	}
+
+	*d = date
+	return nil
+}
+
+func (d Date) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+func (d *Date) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case time.Time:
+		*d = NewDate(v.Year(), v.Month(), v.Day())
+		return nil
+	case []byte:
+		date, err := ParseDate(string(v))
+		if err != nil {
+			return err
+		}
+		*d = date
+		return nil
+	case string:
+		date, err := ParseDate(v)
+		if err != nil {
+			return err
+		}
+		*d = date
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into Date", src)
+	}
+}
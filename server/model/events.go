@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+)
+
+type EventType string
+
+// Event describes a change to a group or to one of its transactions. Data
+// holds the group or transaction after the change, or what identifies it when
+// it was deleted. ID is assigned when the event is stored and increases with
+// every event, which lets clients resume after the last event they saw.
+type Event struct {
+	ID        int64       `json:"id"`
+	Type      EventType   `json:"type"`
+	GroupID   int64       `json:"group_id"`
+	Data      interface{} `json:"data"`
+	CreatedAt time.Time   `json:"created_at"`
+}
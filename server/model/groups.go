@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+)
+
+type Group struct {
+	ID       int64    `json:"id"`
+	Name     string   `json:"name"`
+	Token    string   `json:"token"`
+	Users    []string `json:"users"`
+	Settings Settings `json:"settings"`
+	// DeleteAfter is set while the group is scheduled for deletion. Such a
+	// group is read-only and gets purged once the time has passed.
+	DeleteAfter *time.Time `json:"delete_after,omitempty"`
+	// ArchivedAt is set once the group is closed. An archived group can still
+	// be read and exported but not modified.
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	Version    int        `json:"-"`
+}
+
+func (g *Group) PendingDeletion() bool {
+	return g.DeleteAfter != nil
+}
+
+func (g *Group) Archived() bool {
+	return g.ArchivedAt != nil
+}
+
+type MergeAction string
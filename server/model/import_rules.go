@@ -0,0 +1,11 @@
+package model
+
+type ImportRule struct {
+	ID       int64    `json:"id"`
+	Pattern  string   `json:"pattern"`
+	Category string   `json:"category"`
+	Payer    string   `json:"payer"`
+	Split    []string `json:"split"`
+	GroupID  int64    `json:"group_id"`
+	Version  int      `json:"-"`
+}
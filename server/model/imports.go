@@ -0,0 +1,25 @@
+package model
+
+type Warning struct {
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+type StatementMapping struct {
+	DateColumn        string `json:"date_column"`
+	DescriptionColumn string `json:"description_column"`
+	AmountColumn      string `json:"amount_column"`
+	DateFormat        string `json:"date_format"`
+	Delimiter         string `json:"delimiter"`
+	DecimalComma      bool   `json:"decimal_comma"`
+	DebitsPositive    bool   `json:"debits_positive"`
+}
+
+type Proposal struct {
+	Line        int          `json:"line"`
+	Date        Date         `json:"date"`
+	Description string       `json:"description"`
+	RuleID      int64        `json:"rule_id,omitempty"`
+	Duplicate   bool         `json:"duplicate"`
+	Transaction *Transaction `json:"transaction"`
+}
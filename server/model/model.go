@@ -0,0 +1,8 @@
+// Package model holds the records of the Splitty API, as the server stores
+// them and clients read and write them. It only depends on the standard
+// library, so that clients do not link the database drivers of the server.
+package model
+
+const (
+	DateLayout = "2006-01-02"
+)
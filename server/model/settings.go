@@ -0,0 +1,133 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+type SplitMode string
+
+const (
+	// SplitManual requires every transaction to list all payments itself.
+	SplitManual SplitMode = "manual"
+	// SplitEqual shares a transaction that only lists what was paid equally
+	// among all group users.
+	SplitEqual SplitMode = "equal"
+)
+
+type Settings struct {
+	Currency      string    `json:"currency"`
+	DefaultSplit  SplitMode `json:"default_split"`
+	Locale        string    `json:"locale"`
+	Timezone      string    `json:"timezone"`
+	SimplifyDebts bool      `json:"simplify_debts"`
+	Precision     int       `json:"precision"`
+}
+
+func DefaultSettings() Settings {
+	return Settings{
+		Currency:      "USD",
+		DefaultSplit:  SplitManual,
+		Locale:        "en-US",
+		Timezone:      "UTC",
+		SimplifyDebts: true,
+		Precision:     2,
+	}
+}
+
+// Location returns the time zone of the group, falling back to UTC.
+func (s Settings) Location() *time.Location {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+// Units converts amount to an integer number of the smallest currency unit
+// allowed by the precision.
+func (s Settings) Units(amount float64) int64 {
+	return int64(math.Round(amount * math.Pow10(s.Precision)))
+}
+
+func (s Settings) Amount(units int64) float64 {
+	return float64(units) / math.Pow10(s.Precision)
+}
+
+// HasPrecision reports whether amount has no more decimals than allowed.
+func (s Settings) HasPrecision(amount float64) bool {
+	return math.Abs(s.Amount(s.Units(amount))-amount) < 1e-9
+}
+
+// SplitEqually adds the share each of users consumed to payments, which must
+// only contain what was paid. Remaining smallest units are assigned to the
+// first users so that the payments sum up to exactly zero.
+func (s Settings) SplitEqually(payments []Payment, users []string) []Payment {
+	if len(users) == 0 {
+		return payments
+	}
+
+	var total int64
+	for _, p := range payments {
+		total += s.Units(p.Amount)
+	}
+
+	n := int64(len(users))
+	share, remainder := total/n, total%n
+
+	split := make([]Payment, 0, len(users))
+	for i, user := range users {
+		units := -share
+		if int64(i) < remainder {
+			units--
+		}
+
+		for _, p := range payments {
+			if p.Payer == user {
+				units += s.Units(p.Amount)
+			}
+		}
+
+		split = append(split, Payment{Payer: user, Amount: s.Amount(units)})
+	}
+
+	// Payers that are not among users keep their payment as it is.
+	for _, p := range payments {
+		if !slices.Contains(users, p.Payer) {
+			split = append(split, Payment{Payer: p.Payer, Amount: s.Amount(s.Units(p.Amount))})
+		}
+	}
+
+	return split
+}
+
+func (s Settings) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+// Scan reads settings stored as JSON, keeping the default for every setting
+// that is missing.
+func (s *Settings) Scan(src interface{}) error {
+	settings := DefaultSettings()
+
+	switch v := src.(type) {
+	case []byte:
+		if err := json.Unmarshal(v, &settings); err != nil {
+			return err
+		}
+	case string:
+		if err := json.Unmarshal([]byte(v), &settings); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("cannot scan %T into Settings", src)
+	}
+
+	*s = settings
+	return nil
+}
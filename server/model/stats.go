@@ -0,0 +1,19 @@
+package model
+
+type Bucket string
+
+type MemberSpending struct {
+	Paid     float64 `json:"paid"`
+	Consumed float64 `json:"consumed"`
+}
+
+type SpendingBucket struct {
+	Start   Date                      `json:"start"`
+	Total   float64                   `json:"total"`
+	Members map[string]MemberSpending `json:"members"`
+}
+
+type RankedTransaction struct {
+	Transaction
+	Total float64 `json:"total"`
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+type Payment struct {
+	Amount float64 `json:"amount"`
+	Payer  string  `json:"payer"`
+}
+
+type Transaction struct {
+	ID       int64     `json:"id"`
+	Title    string    `json:"title"`
+	Category string    `json:"category"`
+	Payments []Payment `json:"payments"`
+	Date     Date      `json:"date"`
+	GroupID  int64     `json:"group_id"`
+	// ClientID is the UUID a client chose when creating the transaction
+	// offline. It is empty for transactions created otherwise.
+	ClientID  string    `json:"client_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Version   int       `json:"version"`
+}
+
+// SameContent reports whether both transactions have the same fields a
+// client can change.
+func (t *Transaction) SameContent(other *Transaction) bool {
+	return t.Title == other.Title &&
+		t.Category == other.Category &&
+		t.Date.Equal(other.Date.Time) &&
+		slices.Equal(t.Payments, other.Payments)
+}
+
+type Comment struct {
+	ID            int64     `json:"id"`
+	TransactionID int64     `json:"transaction_id"`
+	Author        string    `json:"author"`
+	Body          string    `json:"body"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	Version       int       `json:"-"`
+}
+
+type Attachment struct {
+	ID            int64     `json:"id"`
+	TransactionID int64     `json:"transaction_id"`
+	Filename      string    `json:"filename"`
+	ContentType   string    `json:"content_type"`
+	Size          int64     `json:"size"`
+	HasThumbnail  bool      `json:"has_thumbnail"`
+	BlobKey       string    `json:"-"`
+	ThumbnailKey  string    `json:"-"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// BlobKeys lists the keys of every blob stored for the attachment.
+func (a *Attachment) BlobKeys() []string {
+	keys := []string{a.BlobKey}
+	if a.ThumbnailKey != "" {
+		keys = append(keys, a.ThumbnailKey)
+	}
+	return keys
+}